@@ -56,6 +56,11 @@ func Register(r *gin.Engine, opts *Options) {
 	config := router.Group("/config")
 	config.Use(mwBasicAuth(opts.Auther))
 
+	// apply takes the config lock itself.
+	config.POST("/apply", applyConfig)
+
+	config = config.Group("", mwConfigLock())
+
 	config.GET("", getConfig)
 	config.POST("", saveConfig)
 
@@ -139,6 +144,12 @@ func Register(r *gin.Engine, opts *Options) {
 	config.PUT("/sds/:sd", updateSD)
 	config.DELETE("/sds/:sd", deleteSD)
 
+	config.GET("/quotas", getQuotaList)
+	config.GET("/quotas/:quota", getQuota)
+	config.POST("/quotas", createQuota)
+	config.PUT("/quotas/:quota", updateQuota)
+	config.DELETE("/quotas/:quota", deleteQuota)
+
 	config.GET("/limiters", getLimiterList)
 	config.GET("/limiters/:limiter", getLimiter)
 	config.POST("/limiters", createLimiter)
@@ -156,4 +167,23 @@ func Register(r *gin.Engine, opts *Options) {
 	config.POST("/rlimiters", createRateLimiter)
 	config.PUT("/rlimiters/:limiter", updateRateLimiter)
 	config.DELETE("/rlimiters/:limiter", deleteRateLimiter)
+
+	runtime := router.Group("/runtime")
+	runtime.Use(mwBasicAuth(opts.Auther))
+
+	runtime.GET("/conns", getConnList)
+	runtime.GET("/conns/:sid", getConn)
+	runtime.DELETE("/conns/:sid", deleteConn)
+	runtime.GET("/services", getServiceStatusList)
+	runtime.GET("/services/:service", getServiceStatus)
+	runtime.GET("/services/:service/conns", getServiceConnList)
+	runtime.GET("/chains", getChainStatusList)
+	runtime.GET("/chains/:chain", getChainStatus)
+	runtime.GET("/hops", getHopStatusList)
+	runtime.GET("/hops/:hop", getHopStatus)
+	runtime.GET("/hops/:hop/nodes/:node", getNodeStatus)
+	runtime.GET("/hops/:hop/health", getHopHealth)
+	runtime.GET("/quotas/:quota", getQuotaUsageList)
+	runtime.GET("/quotas/:quota/clients/:client", getQuotaUsage)
+	runtime.GET("/events", watchStatus)
 }
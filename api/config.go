@@ -52,28 +52,7 @@ func getConfig(ctx *gin.Context) {
 			s := registry.ServiceRegistry().Get(svc.Name)
 			ss, ok := s.(serviceStatus)
 			if ok && ss != nil {
-				status := ss.Status()
-				svc.Status = &config.ServiceStatus{
-					CreateTime: status.CreateTime().Unix(),
-					State:      string(status.State()),
-				}
-				if st := status.Stats(); st != nil {
-					svc.Status.Stats = &config.ServiceStats{
-						TotalConns:   st.Get(stats.KindTotalConns),
-						CurrentConns: st.Get(stats.KindCurrentConns),
-						TotalErrs:    st.Get(stats.KindTotalErrs),
-						InputBytes:   st.Get(stats.KindInputBytes),
-						OutputBytes:  st.Get(stats.KindOutputBytes),
-					}
-				}
-				for _, ev := range status.Events() {
-					if !ev.Time.IsZero() {
-						svc.Status.Events = append(svc.Status.Events, config.ServiceEvent{
-							Time: ev.Time.Unix(),
-							Msg:  ev.Message,
-						})
-					}
-				}
+				svc.Status = newServiceStatus(ss.Status())
 			}
 		}
 		return nil
@@ -164,3 +143,32 @@ func saveConfig(ctx *gin.Context) {
 		Msg: "OK",
 	})
 }
+
+func newServiceStatus(status *service.Status) *config.ServiceStatus {
+	if status == nil {
+		return nil
+	}
+
+	ss := &config.ServiceStatus{
+		CreateTime: status.CreateTime().Unix(),
+		State:      string(status.State()),
+	}
+	if st := status.Stats(); st != nil {
+		ss.Stats = &config.ServiceStats{
+			TotalConns:   st.Get(stats.KindTotalConns),
+			CurrentConns: st.Get(stats.KindCurrentConns),
+			TotalErrs:    st.Get(stats.KindTotalErrs),
+			InputBytes:   st.Get(stats.KindInputBytes),
+			OutputBytes:  st.Get(stats.KindOutputBytes),
+		}
+	}
+	for _, ev := range status.Events() {
+		if !ev.Time.IsZero() {
+			ss.Events = append(ss.Events, config.ServiceEvent{
+				Time: ev.Time.Unix(),
+				Msg:  ev.Message,
+			})
+		}
+	}
+	return ss
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/config/loader"
+	"github.com/go-gost/x/config/validate"
+)
+
+// swagger:parameters applyConfigRequest
+type applyConfigRequest struct {
+	// apply mode, one of merge|replace, default is merge.
+	// merge: the objects in the request are added or replace the objects with the same name, other objects are kept.
+	// replace: the request is the full config, the objects not in the request are removed.
+	// in: query
+	Mode string `form:"mode" json:"mode"`
+	// only validate the config and report the changes without applying them.
+	// in: query
+	DryRun bool `form:"dryRun" json:"dryRun"`
+	// in: body
+	Data config.Config `json:"data"`
+}
+
+// successful operation.
+// swagger:response applyConfigResponse
+type applyConfigResponse struct {
+	// in: body
+	Data *loader.Diff
+}
+
+func applyConfig(ctx *gin.Context) {
+	// swagger:route POST /config/apply Config applyConfigRequest
+	//
+	// Apply the config as a transaction.
+	// All the references are checked and all the objects are built before the running objects are changed,
+	// the changes are rolled back if any service fails to start.
+	// The response is the list of the added, updated and removed objects.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: applyConfigResponse
+
+	var req applyConfigRequest
+	ctx.ShouldBindQuery(&req)
+	if err := ctx.ShouldBindJSON(&req.Data); err != nil {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeInvalid, err.Error()))
+		return
+	}
+
+	switch req.Mode {
+	case "", loader.ModeMerge, loader.ModeReplace:
+	default:
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeInvalid, fmt.Sprintf("invalid mode %s", req.Mode)))
+		return
+	}
+
+	diff, err := loader.Apply(&req.Data, req.Mode, req.DryRun)
+	if err != nil {
+		var verr validate.Errors
+		if errors.As(err, &verr) {
+			writeError(ctx, NewError(http.StatusBadRequest, ErrCodeInvalid, err.Error()))
+			return
+		}
+		writeError(ctx, NewError(http.StatusInternalServerError, ErrCodeFailed, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Data: diff,
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-gost/core/auth"
 	"github.com/go-gost/x/config"
 	parser "github.com/go-gost/x/config/parsing/auth"
 	"github.com/go-gost/x/registry"
@@ -141,6 +142,11 @@ func createAuther(ctx *gin.Context) {
 		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeDup, fmt.Sprintf("auther %s already exists", name)))
 		return
 	}
+	if err := registerLockoutAdmission(nil, &req.Data, v); err != nil {
+		registry.AutherRegistry().Unregister(name)
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeDup, err.Error()))
+		return
+	}
 
 	config.OnUpdate(func(c *config.Config) error {
 		c.Authers = append(c.Authers, &req.Data)
@@ -198,6 +204,10 @@ func updateAuther(ctx *gin.Context) {
 		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeDup, fmt.Sprintf("auther %s already exists", name)))
 		return
 	}
+	if err := registerLockoutAdmission(findAutherConfig(name), &req.Data, v); err != nil {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeDup, err.Error()))
+		return
+	}
 
 	config.OnUpdate(func(c *config.Config) error {
 		for i := range c.Authers {
@@ -248,6 +258,9 @@ func deleteAuther(ctx *gin.Context) {
 		return
 	}
 	registry.AutherRegistry().Unregister(name)
+	if cfg := findAutherConfig(name); cfg != nil && cfg.Lockout != nil && cfg.Lockout.Admission != "" {
+		registry.AdmissionRegistry().Unregister(cfg.Lockout.Admission)
+	}
 
 	config.OnUpdate(func(c *config.Config) error {
 		authers := c.Authers
@@ -265,3 +278,29 @@ func deleteAuther(ctx *gin.Context) {
 		Msg: "OK",
 	})
 }
+
+func findAutherConfig(name string) *config.AutherConfig {
+	for _, cfg := range config.Global().Authers {
+		if cfg != nil && cfg.Name == name {
+			return cfg
+		}
+	}
+	return nil
+}
+
+// registerLockoutAdmission registers the admission of the lockout tracker of the auther v,
+// the admission registered by the previous config of the auther is replaced.
+func registerLockoutAdmission(prev, cfg *config.AutherConfig, v auth.Authenticator) error {
+	if prev != nil && prev.Lockout != nil && prev.Lockout.Admission != "" {
+		registry.AdmissionRegistry().Unregister(prev.Lockout.Admission)
+	}
+	if cfg.Lockout == nil || cfg.Lockout.Admission == "" {
+		return nil
+	}
+
+	name := cfg.Lockout.Admission
+	if err := registry.AdmissionRegistry().Register(name, parser.LockoutAdmission(v)); err != nil {
+		return fmt.Errorf("admission %s already exists", name)
+	}
+	return nil
+}
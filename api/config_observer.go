@@ -136,7 +136,11 @@ func createObserver(ctx *gin.Context) {
 		return
 	}
 
-	v := parser.ParseObserver(&req.Data)
+	v, err := parser.ParseObserver(&req.Data)
+	if err != nil {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeInvalid, fmt.Sprintf("create observer %s failed: %s", name, err.Error())))
+		return
+	}
 
 	if err := registry.ObserverRegistry().Register(name, v); err != nil {
 		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeDup, fmt.Sprintf("observer %s already exists", name)))
@@ -192,7 +196,11 @@ func updateObserver(ctx *gin.Context) {
 
 	req.Data.Name = name
 
-	v := parser.ParseObserver(&req.Data)
+	v, err := parser.ParseObserver(&req.Data)
+	if err != nil {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeInvalid, fmt.Sprintf("update observer %s failed: %s", name, err.Error())))
+		return
+	}
 
 	registry.ObserverRegistry().Unregister(name)
 
@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-gost/core/auth"
 	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/config/loader"
 )
 
 func mwLogger() gin.HandlerFunc {
@@ -29,6 +30,16 @@ func mwLogger() gin.HandlerFunc {
 	}
 }
 
+// mwConfigLock serializes the requests changing the config with the config applies.
+func mwConfigLock() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet {
+			return
+		}
+		loader.Exclusive(c.Next)
+	}
+}
+
 func mwBasicAuth(auther auth.Authenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if auther == nil {
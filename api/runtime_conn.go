@@ -0,0 +1,175 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/x/conntrack"
+	"github.com/go-gost/x/registry"
+)
+
+// swagger:parameters getConnListRequest
+type getConnListRequest struct {
+}
+
+// successful operation.
+// swagger:response getConnListResponse
+type getConnListResponse struct {
+	// in: body
+	Data connList
+}
+
+type connList struct {
+	Count int                  `json:"count"`
+	List  []conntrack.ConnInfo `json:"list"`
+}
+
+func getConnList(ctx *gin.Context) {
+	// swagger:route GET /runtime/conns Runtime getConnListRequest
+	//
+	// Get the live connections of all services.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getConnListResponse
+
+	var req getConnListRequest
+	ctx.ShouldBindQuery(&req)
+
+	ctx.JSON(http.StatusOK, Response{
+		Data: newConnList(conntrack.Default().List("")),
+	})
+}
+
+// swagger:parameters getServiceConnListRequest
+type getServiceConnListRequest struct {
+	// in: path
+	// required: true
+	Service string `uri:"service" json:"service"`
+}
+
+// successful operation.
+// swagger:response getServiceConnListResponse
+type getServiceConnListResponse struct {
+	// in: body
+	Data connList
+}
+
+func getServiceConnList(ctx *gin.Context) {
+	// swagger:route GET /runtime/services/{service}/conns Runtime getServiceConnListRequest
+	//
+	// Get the live connections of the service.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getServiceConnListResponse
+
+	var req getServiceConnListRequest
+	ctx.ShouldBindUri(&req)
+
+	name := strings.TrimSpace(req.Service)
+	if !registry.ServiceRegistry().IsRegistered(name) {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeNotFound, fmt.Sprintf("service %s not found", name)))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Data: newConnList(conntrack.Default().List(name)),
+	})
+}
+
+// swagger:parameters getConnRequest
+type getConnRequest struct {
+	// in: path
+	// required: true
+	SID string `uri:"sid" json:"sid"`
+}
+
+// successful operation.
+// swagger:response getConnResponse
+type getConnResponse struct {
+	// in: body
+	Data conntrack.ConnInfo
+}
+
+func getConn(ctx *gin.Context) {
+	// swagger:route GET /runtime/conns/{sid} Runtime getConnRequest
+	//
+	// Get the live connection by session ID.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getConnResponse
+
+	var req getConnRequest
+	ctx.ShouldBindUri(&req)
+
+	c := conntrack.Default().Get(req.SID)
+	if c == nil {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeNotFound, fmt.Sprintf("connection %s not found", req.SID)))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Data: c.Info(),
+	})
+}
+
+// swagger:parameters deleteConnRequest
+type deleteConnRequest struct {
+	// in: path
+	// required: true
+	SID string `uri:"sid" json:"sid"`
+}
+
+// successful operation.
+// swagger:response deleteConnResponse
+type deleteConnResponse struct {
+	Data Response
+}
+
+func deleteConn(ctx *gin.Context) {
+	// swagger:route DELETE /runtime/conns/{sid} Runtime deleteConnRequest
+	//
+	// Close the live connection by session ID.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: deleteConnResponse
+
+	var req deleteConnRequest
+	ctx.ShouldBindUri(&req)
+
+	c := conntrack.Default().Get(req.SID)
+	if c == nil {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeNotFound, fmt.Sprintf("connection %s not found", req.SID)))
+		return
+	}
+	c.Close()
+	conntrack.Default().Unregister(req.SID)
+
+	ctx.JSON(http.StatusOK, Response{
+		Msg: "OK",
+	})
+}
+
+func newConnList(conns []*conntrack.Conn) connList {
+	list := connList{
+		Count: len(conns),
+		List:  make([]conntrack.ConnInfo, 0, len(conns)),
+	}
+	for _, c := range conns {
+		list.List = append(list.List, c.Info())
+	}
+	return list
+}
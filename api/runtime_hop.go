@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	xhop "github.com/go-gost/x/hop"
+)
+
+// swagger:parameters getHopHealthRequest
+type getHopHealthRequest struct {
+	// in: path
+	// required: true
+	Hop string `uri:"hop" json:"hop"`
+}
+
+// successful operation.
+// swagger:response getHopHealthResponse
+type getHopHealthResponse struct {
+	// in: body
+	Data hopHealthList
+}
+
+type hopHealthList struct {
+	Count int               `json:"count"`
+	List  []xhop.NodeHealth `json:"list"`
+}
+
+func getHopHealth(ctx *gin.Context) {
+	// swagger:route GET /runtime/hops/{hop}/health Runtime getHopHealthRequest
+	//
+	// Get the health check status of the nodes in the hop.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getHopHealthResponse
+
+	var req getHopHealthRequest
+	ctx.ShouldBindUri(&req)
+
+	name := strings.TrimSpace(req.Hop)
+	list, ok := xhop.HealthStatus(name)
+	if !ok {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeNotFound, fmt.Sprintf("health check of hop %s not found", name)))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Data: hopHealthList{
+			Count: len(list),
+			List:  list,
+		},
+	})
+}
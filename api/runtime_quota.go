@@ -0,0 +1,112 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/x/quota"
+	"github.com/go-gost/x/registry"
+)
+
+// swagger:parameters getQuotaUsageListRequest
+type getQuotaUsageListRequest struct {
+	// in: path
+	// required: true
+	Quota string `uri:"quota" json:"quota"`
+}
+
+// successful operation.
+// swagger:response getQuotaUsageListResponse
+type getQuotaUsageListResponse struct {
+	// in: body
+	Data quotaUsageList
+}
+
+type quotaUsageList struct {
+	Count int                  `json:"count"`
+	List  []*quota.ClientUsage `json:"list"`
+}
+
+func getQuotaUsageList(ctx *gin.Context) {
+	// swagger:route GET /runtime/quotas/{quota} Runtime getQuotaUsageListRequest
+	//
+	// Get the usage and remaining quota of the clients with usage in the current periods.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getQuotaUsageListResponse
+
+	var req getQuotaUsageListRequest
+	ctx.ShouldBindUri(&req)
+
+	name := strings.TrimSpace(req.Quota)
+	if !registry.QuotaRegistry().IsRegistered(name) {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeNotFound, fmt.Sprintf("quota %s not found", name)))
+		return
+	}
+	q := registry.QuotaRegistry().Get(name)
+
+	clients := q.Clients(ctx)
+	sort.Strings(clients)
+
+	list := make([]*quota.ClientUsage, 0, len(clients))
+	for _, client := range clients {
+		if u := q.Usage(ctx, client); u != nil {
+			list = append(list, u)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Data: quotaUsageList{
+			Count: len(list),
+			List:  list,
+		},
+	})
+}
+
+// swagger:parameters getQuotaUsageRequest
+type getQuotaUsageRequest struct {
+	// in: path
+	// required: true
+	Quota string `uri:"quota" json:"quota"`
+	// in: path
+	// required: true
+	Client string `uri:"client" json:"client"`
+}
+
+// successful operation.
+// swagger:response getQuotaUsageResponse
+type getQuotaUsageResponse struct {
+	// in: body
+	Data *quota.ClientUsage
+}
+
+func getQuotaUsage(ctx *gin.Context) {
+	// swagger:route GET /runtime/quotas/{quota}/clients/{client} Runtime getQuotaUsageRequest
+	//
+	// Get the usage and remaining quota of the client.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getQuotaUsageResponse
+
+	var req getQuotaUsageRequest
+	ctx.ShouldBindUri(&req)
+
+	name := strings.TrimSpace(req.Quota)
+	if !registry.QuotaRegistry().IsRegistered(name) {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeNotFound, fmt.Sprintf("quota %s not found", name)))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Data: registry.QuotaRegistry().Get(name).Usage(ctx, req.Client),
+	})
+}
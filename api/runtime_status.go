@@ -0,0 +1,617 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/hop"
+	"github.com/go-gost/core/selector"
+	"github.com/go-gost/x/config"
+	xhop "github.com/go-gost/x/hop"
+	"github.com/go-gost/x/registry"
+	xs "github.com/go-gost/x/selector"
+)
+
+const (
+	defaultStatusInterval = time.Second
+	minStatusInterval     = 100 * time.Millisecond
+)
+
+type serviceRuntimeStatus struct {
+	Name   string                `json:"name"`
+	Status *config.ServiceStatus `json:"status,omitempty"`
+}
+
+type serviceStatusList struct {
+	Count int                    `json:"count"`
+	List  []serviceRuntimeStatus `json:"list"`
+}
+
+type nodeStatus struct {
+	Name string `json:"name"`
+	Addr string `json:"addr"`
+	// number of the consecutive failures marked on the node.
+	Failures int64 `json:"failures"`
+	// unix time of the last failure mark.
+	FailTime int64 `json:"failTime,omitempty"`
+	// number of the active connections through the node.
+	Conns int64 `json:"conns"`
+	// connect latency moving average in milliseconds.
+	Latency   int64  `json:"latency"`
+	LastError string `json:"lastError,omitempty"`
+	// unix time of the last error.
+	ErrorTime int64 `json:"errorTime,omitempty"`
+	// health check result, absent if the health check is not enabled.
+	Healthy *bool `json:"healthy,omitempty"`
+}
+
+type hopStatus struct {
+	Name  string       `json:"name"`
+	Nodes []nodeStatus `json:"nodes"`
+}
+
+type hopStatusList struct {
+	Count int         `json:"count"`
+	List  []hopStatus `json:"list"`
+}
+
+type chainStatus struct {
+	Name     string      `json:"name"`
+	Failures int64       `json:"failures"`
+	FailTime int64       `json:"failTime,omitempty"`
+	Hops     []hopStatus `json:"hops"`
+}
+
+type chainStatusList struct {
+	Count int           `json:"count"`
+	List  []chainStatus `json:"list"`
+}
+
+// swagger:parameters getServiceStatusListRequest
+type getServiceStatusListRequest struct {
+}
+
+// successful operation.
+// swagger:response getServiceStatusListResponse
+type getServiceStatusListResponse struct {
+	// in: body
+	Data serviceStatusList
+}
+
+func getServiceStatusList(ctx *gin.Context) {
+	// swagger:route GET /runtime/services Runtime getServiceStatusListRequest
+	//
+	// Get the runtime status of all services.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getServiceStatusListResponse
+
+	list := serviceStatuses()
+	ctx.JSON(http.StatusOK, Response{
+		Data: serviceStatusList{
+			Count: len(list),
+			List:  list,
+		},
+	})
+}
+
+// swagger:parameters getServiceStatusRequest
+type getServiceStatusRequest struct {
+	// in: path
+	// required: true
+	Service string `uri:"service" json:"service"`
+}
+
+// successful operation.
+// swagger:response getServiceStatusResponse
+type getServiceStatusResponse struct {
+	// in: body
+	Data serviceRuntimeStatus
+}
+
+func getServiceStatus(ctx *gin.Context) {
+	// swagger:route GET /runtime/services/{service} Runtime getServiceStatusRequest
+	//
+	// Get the runtime status of the service, including the state, recent events and statistics.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getServiceStatusResponse
+
+	var req getServiceStatusRequest
+	ctx.ShouldBindUri(&req)
+
+	name := strings.TrimSpace(req.Service)
+	s := registry.ServiceRegistry().Get(name)
+	if s == nil {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeNotFound, fmt.Sprintf("service %s not found", name)))
+		return
+	}
+
+	status := serviceRuntimeStatus{Name: name}
+	if ss, ok := s.(serviceStatus); ok && ss != nil {
+		status.Status = newServiceStatus(ss.Status())
+	}
+	ctx.JSON(http.StatusOK, Response{
+		Data: status,
+	})
+}
+
+// swagger:parameters getChainStatusListRequest
+type getChainStatusListRequest struct {
+}
+
+// successful operation.
+// swagger:response getChainStatusListResponse
+type getChainStatusListResponse struct {
+	// in: body
+	Data chainStatusList
+}
+
+func getChainStatusList(ctx *gin.Context) {
+	// swagger:route GET /runtime/chains Runtime getChainStatusListRequest
+	//
+	// Get the runtime status of all chains.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getChainStatusListResponse
+
+	chains := registry.ChainRegistry().GetAll()
+	list := make([]chainStatus, 0, len(chains))
+	for _, name := range sortedKeys(chains) {
+		list = append(list, newChainStatus(name, chains[name]))
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Data: chainStatusList{
+			Count: len(list),
+			List:  list,
+		},
+	})
+}
+
+// swagger:parameters getChainStatusRequest
+type getChainStatusRequest struct {
+	// in: path
+	// required: true
+	Chain string `uri:"chain" json:"chain"`
+}
+
+// successful operation.
+// swagger:response getChainStatusResponse
+type getChainStatusResponse struct {
+	// in: body
+	Data chainStatus
+}
+
+func getChainStatus(ctx *gin.Context) {
+	// swagger:route GET /runtime/chains/{chain} Runtime getChainStatusRequest
+	//
+	// Get the runtime status of the chain and the nodes in its hops.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getChainStatusResponse
+
+	var req getChainStatusRequest
+	ctx.ShouldBindUri(&req)
+
+	name := strings.TrimSpace(req.Chain)
+	c := registry.ChainRegistry().GetAll()[name]
+	if c == nil {
+		writeError(ctx, NewError(http.StatusBadRequest, ErrCodeNotFound, fmt.Sprintf("chain %s not found", name)))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Data: newChainStatus(name, c),
+	})
+}
+
+// swagger:parameters getHopStatusListRequest
+type getHopStatusListRequest struct {
+}
+
+// successful operation.
+// swagger:response getHopStatusListResponse
+type getHopStatusListResponse struct {
+	// in: body
+	Data hopStatusList
+}
+
+func getHopStatusList(ctx *gin.Context) {
+	// swagger:route GET /runtime/hops Runtime getHopStatusListRequest
+	//
+	// Get the runtime status of all hops, including the hops defined in chains.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getHopStatusListResponse
+
+	list := hopStatuses()
+	ctx.JSON(http.StatusOK, Response{
+		Data: hopStatusList{
+			Count: len(list),
+			List:  list,
+		},
+	})
+}
+
+// swagger:parameters getHopStatusRequest
+type getHopStatusRequest struct {
+	// in: path
+	// required: true
+	Hop string `uri:"hop" json:"hop"`
+}
+
+// successful operation.
+// swagger:response getHopStatusResponse
+type getHopStatusResponse struct {
+	// in: body
+	Data hopStatus
+}
+
+func getHopStatus(ctx *gin.Context) {
+	// swagger:route GET /runtime/hops/{hop} Runtime getHopStatusRequest
+	//
+	// Get the runtime status of the nodes in the hop.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getHopStatusResponse
+
+	var req getHopStatusRequest
+	ctx.ShouldBindUri(&req)
+
+	name := strings.TrimSpace(req.Hop)
+	for _, st := range hopStatuses() {
+		if st.Name == name {
+			ctx.JSON(http.StatusOK, Response{
+				Data: st,
+			})
+			return
+		}
+	}
+
+	writeError(ctx, NewError(http.StatusBadRequest, ErrCodeNotFound, fmt.Sprintf("hop %s not found", name)))
+}
+
+// swagger:parameters getNodeStatusRequest
+type getNodeStatusRequest struct {
+	// in: path
+	// required: true
+	Hop string `uri:"hop" json:"hop"`
+	// in: path
+	// required: true
+	Node string `uri:"node" json:"node"`
+}
+
+// successful operation.
+// swagger:response getNodeStatusResponse
+type getNodeStatusResponse struct {
+	// in: body
+	Data nodeStatus
+}
+
+func getNodeStatus(ctx *gin.Context) {
+	// swagger:route GET /runtime/hops/{hop}/nodes/{node} Runtime getNodeStatusRequest
+	//
+	// Get the runtime status of the node, including the fail marks and the last error.
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200: getNodeStatusResponse
+
+	var req getNodeStatusRequest
+	ctx.ShouldBindUri(&req)
+
+	hopName := strings.TrimSpace(req.Hop)
+	nodeName := strings.TrimSpace(req.Node)
+	for _, st := range hopStatuses() {
+		if st.Name != hopName {
+			continue
+		}
+		for _, node := range st.Nodes {
+			if node.Name == nodeName {
+				ctx.JSON(http.StatusOK, Response{
+					Data: node,
+				})
+				return
+			}
+		}
+		break
+	}
+
+	writeError(ctx, NewError(http.StatusBadRequest, ErrCodeNotFound, fmt.Sprintf("node %s of hop %s not found", nodeName, hopName)))
+}
+
+// swagger:parameters watchStatusRequest
+type watchStatusRequest struct {
+	// status polling interval, such as 500ms, 5s, default is 1s.
+	// in: query
+	Interval string `form:"interval" json:"interval"`
+}
+
+type removedStatus struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Hop  string `json:"hop,omitempty"`
+}
+
+type nodeStatusEvent struct {
+	Hop  string     `json:"hop"`
+	Node nodeStatus `json:"node"`
+}
+
+func watchStatus(ctx *gin.Context) {
+	// swagger:route GET /runtime/events Runtime watchStatusRequest
+	//
+	// Watch the status changes of the services and nodes as a Server-Sent-Events stream.
+	// The current status of all objects is sent first, then a service or node event is sent
+	// when the state, events, fail marks, last error or health of the object change,
+	// and a removed event is sent when the object is deleted.
+	//
+	//     Produces:
+	//     - text/event-stream
+	//
+	//     Security:
+	//       basicAuth: []
+	//
+	//     Responses:
+	//       200:
+
+	var req watchStatusRequest
+	ctx.ShouldBindQuery(&req)
+
+	interval := defaultStatusInterval
+	if req.Interval != "" {
+		d, err := time.ParseDuration(req.Interval)
+		if err != nil {
+			writeError(ctx, NewError(http.StatusBadRequest, ErrCodeInvalid, fmt.Sprintf("invalid interval %s", req.Interval)))
+			return
+		}
+		interval = d
+	}
+	if interval < minStatusInterval {
+		interval = minStatusInterval
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	w := &statusWatcher{
+		services: make(map[string]string),
+		nodes:    make(map[string]string),
+	}
+	ctx.Stream(func(_ io.Writer) bool {
+		w.emit(ctx)
+
+		select {
+		case <-ticker.C:
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// statusWatcher keeps the digests of the last sent status,
+// counters are excluded from the digest, so that the busy objects do not flood the stream.
+type statusWatcher struct {
+	services map[string]string
+	nodes    map[string]string
+}
+
+func (w *statusWatcher) emit(ctx *gin.Context) {
+	services := make(map[string]struct{})
+	for _, st := range serviceStatuses() {
+		services[st.Name] = struct{}{}
+
+		digest := serviceDigest(st.Status)
+		if v, ok := w.services[st.Name]; ok && v == digest {
+			continue
+		}
+		w.services[st.Name] = digest
+		ctx.SSEvent("service", st)
+	}
+	for name := range w.services {
+		if _, ok := services[name]; !ok {
+			delete(w.services, name)
+			ctx.SSEvent("removed", removedStatus{Type: "service", Name: name})
+		}
+	}
+
+	nodes := make(map[string]removedStatus)
+	for _, hs := range hopStatuses() {
+		for _, ns := range hs.Nodes {
+			key := hs.Name + "/" + ns.Name
+			nodes[key] = removedStatus{Type: "node", Name: ns.Name, Hop: hs.Name}
+
+			digest := nodeDigest(&ns)
+			if v, ok := w.nodes[key]; ok && v == digest {
+				continue
+			}
+			w.nodes[key] = digest
+			ctx.SSEvent("node", nodeStatusEvent{Hop: hs.Name, Node: ns})
+		}
+	}
+	for key := range w.nodes {
+		if _, ok := nodes[key]; !ok {
+			delete(w.nodes, key)
+			hop, name, _ := strings.Cut(key, "/")
+			ctx.SSEvent("removed", removedStatus{Type: "node", Name: name, Hop: hop})
+		}
+	}
+}
+
+func serviceDigest(st *config.ServiceStatus) string {
+	if st == nil {
+		return ""
+	}
+	var last config.ServiceEvent
+	if n := len(st.Events); n > 0 {
+		last = st.Events[n-1]
+	}
+	return fmt.Sprintf("%s|%d|%d|%d|%s", st.State, st.CreateTime, len(st.Events), last.Time, last.Msg)
+}
+
+func nodeDigest(st *nodeStatus) string {
+	healthy := "-"
+	if st.Healthy != nil {
+		healthy = fmt.Sprint(*st.Healthy)
+	}
+	return fmt.Sprintf("%s|%d|%d|%d|%s|%s", st.Addr, st.Failures, st.FailTime, st.ErrorTime, st.LastError, healthy)
+}
+
+func serviceStatuses() []serviceRuntimeStatus {
+	services := registry.ServiceRegistry().GetAll()
+	list := make([]serviceRuntimeStatus, 0, len(services))
+	for _, name := range sortedKeys(services) {
+		st := serviceRuntimeStatus{Name: name}
+		if ss, ok := services[name].(serviceStatus); ok && ss != nil {
+			st.Status = newServiceStatus(ss.Status())
+		}
+		list = append(list, st)
+	}
+	return list
+}
+
+// hopStatuses returns the status of the registered hops and the named hops defined in chains.
+func hopStatuses() []hopStatus {
+	hops := registry.HopRegistry().GetAll()
+	for _, c := range registry.ChainRegistry().GetAll() {
+		for _, h := range chainHops(c) {
+			name := hopName(h)
+			if name == "" {
+				continue
+			}
+			if _, ok := hops[name]; !ok {
+				hops[name] = h
+			}
+		}
+	}
+
+	list := make([]hopStatus, 0, len(hops))
+	for _, name := range sortedKeys(hops) {
+		list = append(list, newHopStatus(name, hops[name]))
+	}
+	return list
+}
+
+func newChainStatus(name string, c chain.Chainer) chainStatus {
+	st := chainStatus{
+		Name: name,
+		Hops: []hopStatus{},
+	}
+	if v, ok := c.(selector.Markable); ok {
+		st.Failures, st.FailTime = markerStatus(v.Marker())
+	}
+	for _, h := range chainHops(c) {
+		st.Hops = append(st.Hops, newHopStatus(hopName(h), h))
+	}
+	return st
+}
+
+func newHopStatus(name string, h hop.Hop) hopStatus {
+	st := hopStatus{
+		Name:  name,
+		Nodes: []nodeStatus{},
+	}
+
+	nl, ok := h.(hop.NodeList)
+	if !ok || nl == nil {
+		return st
+	}
+
+	health := make(map[string]bool)
+	if list, ok := xhop.HealthStatus(name); ok {
+		for _, v := range list {
+			health[v.Node] = v.Healthy
+		}
+	}
+
+	for _, node := range nl.Nodes() {
+		if node == nil {
+			continue
+		}
+		ns := nodeStatus{
+			Name: node.Name,
+			Addr: node.Addr,
+		}
+		ns.Failures, ns.FailTime = markerStatus(node.Marker())
+		if s := xs.StatsOf(node); s != nil {
+			ns.Conns = s.Conns()
+			ns.Latency = s.Latency().Milliseconds()
+			msg, t := s.LastError()
+			if msg != "" {
+				ns.LastError = msg
+				ns.ErrorTime = t.Unix()
+			}
+		}
+		if v, ok := health[node.Name]; ok {
+			ns.Healthy = &v
+		}
+		st.Nodes = append(st.Nodes, ns)
+	}
+	return st
+}
+
+func markerStatus(marker selector.Marker) (count int64, t int64) {
+	if marker == nil {
+		return
+	}
+	if count = marker.Count(); count > 0 {
+		t = marker.Time().Unix()
+	}
+	return
+}
+
+func chainHops(c chain.Chainer) []hop.Hop {
+	if v, ok := c.(interface{ Hops() []hop.Hop }); ok && v != nil {
+		return v.Hops()
+	}
+	return nil
+}
+
+func hopName(h hop.Hop) string {
+	if v, ok := h.(interface{ Name() string }); ok && v != nil {
+		return v.Name()
+	}
+	return ""
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-gost/core/chain"
+	xchain "github.com/go-gost/x/chain"
+	"github.com/go-gost/x/hop"
+	"github.com/go-gost/x/registry"
+)
+
+func TestGetChainStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c := xchain.NewChain("test-chain")
+	c.AddHop(hop.NewHop(
+		hop.NameOption("test-hop"),
+		hop.NodeOption(chain.NewNode("node-0", "127.0.0.1:8080")),
+	))
+	if err := registry.ChainRegistry().Register("test-chain", c); err != nil {
+		t.Fatal(err)
+	}
+	defer registry.ChainRegistry().Unregister("test-chain")
+
+	r := gin.New()
+	r.GET("/runtime/chains/:chain", getChainStatus)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/runtime/chains/test-chain", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Data chainStatus `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	st := resp.Data
+	if st.Name != "test-chain" || len(st.Hops) != 1 || st.Hops[0].Name != "test-hop" ||
+		len(st.Hops[0].Nodes) != 1 || st.Hops[0].Nodes[0].Addr != "127.0.0.1:8080" {
+		t.Errorf("unexpected chain status %+v", st)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/runtime/chains/unknown", nil))
+	if w.Code == http.StatusOK {
+		t.Errorf("unknown chain: got status %d", w.Code)
+	}
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
 	"io"
 	"strings"
 	"sync"
@@ -14,6 +15,24 @@ import (
 	xlogger "github.com/go-gost/x/logger"
 )
 
+const (
+	verifiedCacheTTL = 5 * time.Minute
+)
+
+// Lister is implemented by the authenticators which can enumerate the users.
+type Lister interface {
+	// List returns the users and their stored passwords.
+	// The wrappers return nil if the wrapped authenticator cannot enumerate the users.
+	List(ctx context.Context) map[string]string
+}
+
+// CertAuthenticator is implemented by the authenticators which authenticate the client
+// by the verified TLS client certificate in the context, without the username and password.
+// The wrappers and the group return false if no wrapped authenticator authenticates by certificate.
+type CertAuthenticator interface {
+	AuthenticateCert(ctx context.Context, opts ...auth.Option) (id string, ok bool)
+}
+
 type options struct {
 	auths       map[string]string
 	fileLoader  loader.Loader
@@ -64,6 +83,7 @@ func LoggerOption(logger logger.Logger) Option {
 // authenticator is an Authenticator that authenticates client by key-value pairs.
 type authenticator struct {
 	kvs        map[string]string
+	verified   sync.Map
 	mu         sync.RWMutex
 	cancelFunc context.CancelFunc
 	options    options
@@ -107,7 +127,44 @@ func (p *authenticator) Authenticate(ctx context.Context, user, password string,
 	}
 
 	v, ok := p.kvs[user]
-	return user, ok && (v == "" || password == v)
+	if !ok {
+		return user, false
+	}
+	if v == "" {
+		return user, true
+	}
+	if !isHashed(v) {
+		return user, verifyPassword(v, password)
+	}
+
+	// the hashed credentials are slow to verify by design,
+	// so the successful results are cached for a while.
+	key := verifiedKey(user, v, password)
+	if t, ok := p.verified.Load(key); ok && time.Now().Before(t.(time.Time)) {
+		return user, true
+	}
+	if !verifyPassword(v, password) {
+		return user, false
+	}
+	p.verified.Store(key, time.Now().Add(verifiedCacheTTL))
+	return user, true
+}
+
+// List implements Lister interface.
+func (p *authenticator) List(ctx context.Context) map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	m := make(map[string]string, len(p.kvs))
+	for k, v := range p.kvs {
+		m[k] = v
+	}
+	return m
+}
+
+func verifiedKey(user, stored, password string) string {
+	sum := sha256.Sum256([]byte(user + "\x00" + stored + "\x00" + password))
+	return string(sum[:])
 }
 
 func (p *authenticator) periodReload(ctx context.Context) error {
@@ -155,6 +212,7 @@ func (p *authenticator) reload(ctx context.Context) (err error) {
 	defer p.mu.Unlock()
 
 	p.kvs = kvs
+	p.verified.Clear()
 
 	return
 }
@@ -180,10 +238,10 @@ func (p *authenticator) load(ctx context.Context) (m map[string]string, err erro
 		}
 	}
 	if p.options.redisLoader != nil {
-		if mapper, ok := p.options.fileLoader.(loader.Mapper); ok {
+		if mapper, ok := p.options.redisLoader.(loader.Mapper); ok {
 			auths, er := mapper.Map(ctx)
 			if er != nil {
-				p.logger.Warnf("file loader: %v", er)
+				p.logger.Warnf("redis loader: %v", er)
 			}
 			for k, v := range auths {
 				m[k] = v
@@ -282,3 +340,30 @@ func (p *authenticatorGroup) Authenticate(ctx context.Context, user, password st
 	}
 	return "", false
 }
+
+// AuthenticateCert implements CertAuthenticator interface, only the certificate authenticators of the group are tried.
+func (p *authenticatorGroup) AuthenticateCert(ctx context.Context, opts ...auth.Option) (string, bool) {
+	for _, auther := range p.authers {
+		if v, ok := auther.(CertAuthenticator); ok {
+			if id, ok := v.AuthenticateCert(ctx, opts...); ok {
+				return id, ok
+			}
+		}
+	}
+	return "", false
+}
+
+// List implements Lister interface, the user of the former authenticator takes precedence.
+func (p *authenticatorGroup) List(ctx context.Context) map[string]string {
+	m := make(map[string]string)
+	for i := len(p.authers) - 1; i >= 0; i-- {
+		lister, ok := p.authers[i].(Lister)
+		if !ok {
+			continue
+		}
+		for k, v := range lister.List(ctx) {
+			m[k] = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,336 @@
+package cert
+
+import (
+	"bufio"
+	"context"
+	"crypto/x509"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/auth"
+	"github.com/go-gost/core/logger"
+	xctx "github.com/go-gost/x/ctx"
+	"github.com/go-gost/x/internal/loader"
+	xlogger "github.com/go-gost/x/logger"
+	"github.com/gobwas/glob"
+)
+
+const (
+	// SourceCN uses the subject common name as the client ID.
+	SourceCN = "cn"
+	// SourceSAN uses the first subject alternative name as the client ID,
+	// in the order of DNS name, email address, IP address and URI.
+	SourceSAN = "san"
+	// SourceSPIFFE uses the SPIFFE ID (spiffe:// URI SAN) as the client ID.
+	SourceSPIFFE = "spiffe"
+)
+
+type options struct {
+	source      string
+	allows      []string
+	fileLoader  loader.Loader
+	redisLoader loader.Loader
+	httpLoader  loader.Loader
+	period      time.Duration
+	logger      logger.Logger
+}
+
+type Option func(opts *options)
+
+// SourceOption sets the certificate field used as the client ID, one of cn, san and spiffe.
+func SourceOption(source string) Option {
+	return func(opts *options) {
+		opts.source = source
+	}
+}
+
+// AllowsOption sets the allowed client IDs, the wildcard pattern is supported.
+func AllowsOption(allows []string) Option {
+	return func(opts *options) {
+		opts.allows = allows
+	}
+}
+
+func ReloadPeriodOption(period time.Duration) Option {
+	return func(opts *options) {
+		opts.period = period
+	}
+}
+
+func FileLoaderOption(fileLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.fileLoader = fileLoader
+	}
+}
+
+func RedisLoaderOption(redisLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.redisLoader = redisLoader
+	}
+}
+
+func HTTPLoaderOption(httpLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.httpLoader = httpLoader
+	}
+}
+
+func LoggerOption(logger logger.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+// authenticator is an Authenticator that authenticates client by the verified TLS client certificate.
+// The username and password are ignored, the identity of the certificate is returned as the client ID.
+type authenticator struct {
+	ids        map[string]struct{}
+	globs      []glob.Glob
+	mu         sync.RWMutex
+	cancelFunc context.CancelFunc
+	options    options
+	logger     logger.Logger
+}
+
+// NewAuthenticator creates an Authenticator that authenticates client by the TLS client certificate.
+// If no allow-list is configured, all the verified certificates are accepted.
+func NewAuthenticator(opts ...Option) auth.Authenticator {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.source == "" {
+		options.source = SourceCN
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &authenticator{
+		ids:        make(map[string]struct{}),
+		cancelFunc: cancel,
+		options:    options,
+		logger:     options.logger,
+	}
+	if p.logger == nil {
+		p.logger = xlogger.Nop()
+	}
+
+	go p.periodReload(ctx)
+
+	return p
+}
+
+func (p *authenticator) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
+	if p == nil {
+		return "", true
+	}
+
+	cert := xctx.PeerCertificateFromContext(ctx)
+	if cert == nil {
+		return "", false
+	}
+
+	id := Identity(cert, p.options.source)
+	if id == "" {
+		p.logger.Debugf("no %s identity in certificate %s", p.options.source, cert.Subject)
+		return "", false
+	}
+
+	if !p.allowed(id) {
+		p.logger.Debugf("client %s is not allowed", id)
+		return id, false
+	}
+	return id, true
+}
+
+// AuthenticateCert authenticates the client by the certificate in the context.
+func (p *authenticator) AuthenticateCert(ctx context.Context, opts ...auth.Option) (string, bool) {
+	return p.Authenticate(ctx, "", "", opts...)
+}
+
+// Identity returns the identity of the certificate from the source field.
+func Identity(cert *x509.Certificate, source string) string {
+	if cert == nil {
+		return ""
+	}
+
+	switch source {
+	case SourceSAN:
+		if len(cert.DNSNames) > 0 {
+			return cert.DNSNames[0]
+		}
+		if len(cert.EmailAddresses) > 0 {
+			return cert.EmailAddresses[0]
+		}
+		if len(cert.IPAddresses) > 0 {
+			return cert.IPAddresses[0].String()
+		}
+		if len(cert.URIs) > 0 {
+			return cert.URIs[0].String()
+		}
+	case SourceSPIFFE:
+		// a SPIFFE X.509-SVID contains exactly one URI SAN.
+		for _, u := range cert.URIs {
+			if strings.EqualFold(u.Scheme, "spiffe") {
+				return u.String()
+			}
+		}
+	default:
+		return cert.Subject.CommonName
+	}
+	return ""
+}
+
+func (p *authenticator) allowed(id string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	// all the verified certificates are accepted if no allow-list is configured.
+	if len(p.options.allows) == 0 && p.options.fileLoader == nil &&
+		p.options.redisLoader == nil && p.options.httpLoader == nil {
+		return true
+	}
+	if _, ok := p.ids[id]; ok {
+		return true
+	}
+	for _, g := range p.globs {
+		if g.Match(id) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *authenticator) periodReload(ctx context.Context) error {
+	if err := p.reload(ctx); err != nil {
+		p.logger.Warnf("reload: %v", err)
+	}
+
+	period := p.options.period
+	if period <= 0 {
+		return nil
+	}
+	if period < time.Second {
+		period = time.Second
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.reload(ctx); err != nil {
+				p.logger.Warnf("reload: %v", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *authenticator) reload(ctx context.Context) error {
+	v, err := p.load(ctx)
+	if err != nil {
+		return err
+	}
+	patterns := append(append([]string{}, p.options.allows...), v...)
+
+	ids := make(map[string]struct{})
+	var globs []glob.Glob
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, "*?[{") {
+			g, err := glob.Compile(pattern)
+			if err != nil {
+				p.logger.Warnf("invalid pattern %s: %v", pattern, err)
+				continue
+			}
+			globs = append(globs, g)
+			continue
+		}
+		ids[pattern] = struct{}{}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ids = ids
+	p.globs = globs
+
+	return nil
+}
+
+func (p *authenticator) load(ctx context.Context) (patterns []string, err error) {
+	if p.options.fileLoader != nil {
+		r, er := p.options.fileLoader.Load(ctx)
+		if er != nil {
+			p.logger.Warnf("file loader: %v", er)
+		}
+		if v, _ := p.parsePatterns(r); v != nil {
+			patterns = append(patterns, v...)
+		}
+	}
+	if p.options.redisLoader != nil {
+		if lister, ok := p.options.redisLoader.(loader.Lister); ok {
+			list, er := lister.List(ctx)
+			if er != nil {
+				p.logger.Warnf("redis loader: %v", er)
+			}
+			patterns = append(patterns, list...)
+		} else {
+			r, er := p.options.redisLoader.Load(ctx)
+			if er != nil {
+				p.logger.Warnf("redis loader: %v", er)
+			}
+			if v, _ := p.parsePatterns(r); v != nil {
+				patterns = append(patterns, v...)
+			}
+		}
+	}
+	if p.options.httpLoader != nil {
+		r, er := p.options.httpLoader.Load(ctx)
+		if er != nil {
+			p.logger.Warnf("http loader: %v", er)
+		}
+		if v, _ := p.parsePatterns(r); v != nil {
+			patterns = append(patterns, v...)
+		}
+	}
+
+	p.logger.Debugf("load items %d", len(patterns))
+	return
+}
+
+func (p *authenticator) parsePatterns(r io.Reader) (patterns []string, err error) {
+	if r == nil {
+		return
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if n := strings.IndexByte(line, '#'); n >= 0 {
+			line = line[:n]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+
+	err = scanner.Err()
+	return
+}
+
+func (p *authenticator) Close() error {
+	p.cancelFunc()
+	if p.options.fileLoader != nil {
+		p.options.fileLoader.Close()
+	}
+	if p.options.redisLoader != nil {
+		p.options.redisLoader.Close()
+	}
+	if p.options.httpLoader != nil {
+		p.options.httpLoader.Close()
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package cert
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/go-gost/core/auth"
+	xauth "github.com/go-gost/x/auth"
+	"github.com/go-gost/x/auth/lockout"
+	xctx "github.com/go-gost/x/ctx"
+)
+
+func testCert() *x509.Certificate {
+	spiffe, _ := url.Parse("spiffe://example.org/ns/prod/sa/web")
+	return &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "alice"},
+		DNSNames:    []string{"alice.example.org"},
+		IPAddresses: []net.IP{net.IPv4(10, 0, 0, 1)},
+		URIs:        []*url.URL{spiffe},
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	cert := testCert()
+
+	cases := map[string]string{
+		"":           "alice",
+		SourceCN:     "alice",
+		SourceSAN:    "alice.example.org",
+		SourceSPIFFE: "spiffe://example.org/ns/prod/sa/web",
+	}
+	for source, want := range cases {
+		if id := Identity(cert, source); id != want {
+			t.Errorf("source %q: got %q, want %q", source, id, want)
+		}
+	}
+
+	if id := Identity(&x509.Certificate{}, SourceSPIFFE); id != "" {
+		t.Errorf("got %q for certificate without SPIFFE ID", id)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	cert := testCert()
+	ctx := xctx.ContextWithPeerCertificate(context.Background(), func() *x509.Certificate {
+		return cert
+	})
+
+	au := NewAuthenticator(SourceOption(SourceSPIFFE))
+	if _, ok := au.Authenticate(context.Background(), "", ""); ok {
+		t.Error("client without certificate is authenticated")
+	}
+	if id, ok := au.Authenticate(ctx, "", ""); !ok || id != "spiffe://example.org/ns/prod/sa/web" {
+		t.Errorf("got %q %v", id, ok)
+	}
+
+	p := NewAuthenticator(
+		SourceOption(SourceSPIFFE),
+		AllowsOption([]string{"spiffe://example.org/ns/prod/*"}),
+	).(*authenticator)
+	p.reload(context.Background())
+	if _, ok := p.Authenticate(ctx, "", ""); !ok {
+		t.Error("allowed client is rejected")
+	}
+
+	p = NewAuthenticator(AllowsOption([]string{"bob"})).(*authenticator)
+	p.reload(context.Background())
+	if _, ok := p.Authenticate(ctx, "", ""); ok {
+		t.Error("client not in the allow-list is authenticated")
+	}
+}
+
+type passwordAuther struct {
+	calls int
+}
+
+func (a *passwordAuther) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
+	a.calls++
+	return "", false
+}
+
+func TestAuthenticateCert(t *testing.T) {
+	cert := testCert()
+	ctx := xctx.ContextWithPeerCertificate(context.Background(), func() *x509.Certificate {
+		return cert
+	})
+
+	pa := &passwordAuther{}
+	password := lockout.WrapAuthenticator(pa, lockout.NewTracker(lockout.ThresholdOption(1)))
+
+	// the password authenticators are not asked to authenticate by certificate.
+	au := xauth.AuthenticatorGroup(password, NewAuthenticator())
+	if id, ok := au.(xauth.CertAuthenticator).AuthenticateCert(ctx); !ok || id != "alice" {
+		t.Errorf("got %q %v", id, ok)
+	}
+	au = xauth.AuthenticatorGroup(password)
+	for i := 0; i < 3; i++ {
+		if _, ok := au.(xauth.CertAuthenticator).AuthenticateCert(ctx); ok {
+			t.Error("authenticated by a password authenticator")
+		}
+	}
+	if pa.calls > 0 {
+		t.Errorf("password authenticator called %d times", pa.calls)
+	}
+}
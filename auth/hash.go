@@ -0,0 +1,300 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// verifyPassword checks the password against the stored credential.
+// The stored value can be one of the following forms, anything else is treated as plaintext:
+//
+//	bcrypt:         $2a$, $2b$, $2y$ (htpasswd -B)
+//	argon2id:       $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+//	scrypt:         $scrypt$ln=15,r=8,p=1$<salt>$<hash>
+//	SHA-256 crypt:  $5$[rounds=N$]<salt>$<hash>
+//	SHA-512 crypt:  $6$[rounds=N$]<salt>$<hash>
+//	salted SHA-2:   {SSHA256}<base64(hash+salt)>, {SSHA512}<base64(hash+salt)>
+//
+// The salt and hash of argon2id and scrypt are encoded in base64 without padding.
+func verifyPassword(stored, password string) bool {
+	switch {
+	case strings.HasPrefix(stored, "$2a$"),
+		strings.HasPrefix(stored, "$2b$"),
+		strings.HasPrefix(stored, "$2y$"):
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	case strings.HasPrefix(stored, "$argon2id$"):
+		return verifyArgon2id(stored, password)
+	case strings.HasPrefix(stored, "$scrypt$"):
+		return verifyScrypt(stored, password)
+	case strings.HasPrefix(stored, "$5$"):
+		return verifySHACrypt(stored, password, sha256.New, "$5$")
+	case strings.HasPrefix(stored, "$6$"):
+		return verifySHACrypt(stored, password, sha512.New, "$6$")
+	case strings.HasPrefix(stored, "{SSHA256}"):
+		return verifySSHA(strings.TrimPrefix(stored, "{SSHA256}"), password, sha256.New, sha256.Size)
+	case strings.HasPrefix(stored, "{SSHA512}"):
+		return verifySSHA(strings.TrimPrefix(stored, "{SSHA512}"), password, sha512.New, sha512.Size)
+	default:
+		return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+	}
+}
+
+// isHashed reports whether the stored credential is hashed by a key derivation function,
+// which is expensive to verify.
+func isHashed(stored string) bool {
+	return strings.HasPrefix(stored, "$")
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+func parseParams(s string) map[string]int {
+	params := make(map[string]int)
+	for _, kv := range strings.Split(s, ",") {
+		k, v, _ := strings.Cut(kv, "=")
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			continue
+		}
+		params[k] = n
+	}
+	return params
+}
+
+func verifyArgon2id(stored, password string) bool {
+	// $argon2id$v=19$m=65536,t=3,p=4$salt$hash
+	parts := strings.Split(stored, "$")
+	if len(parts) != 6 {
+		return false
+	}
+	if parts[2] != "v=19" {
+		return false
+	}
+	params := parseParams(parts[3])
+	m, t, p := params["m"], params["t"], params["p"]
+	if m <= 0 || t <= 0 || p <= 0 || p > 255 {
+		return false
+	}
+
+	salt, err := decodeBase64(parts[4])
+	if err != nil {
+		return false
+	}
+	key, err := decodeBase64(parts[5])
+	if err != nil || len(key) == 0 {
+		return false
+	}
+
+	dk := argon2.IDKey([]byte(password), salt, uint32(t), uint32(m), uint8(p), uint32(len(key)))
+	return subtle.ConstantTimeCompare(dk, key) == 1
+}
+
+func verifyScrypt(stored, password string) bool {
+	// $scrypt$ln=15,r=8,p=1$salt$hash
+	parts := strings.Split(stored, "$")
+	if len(parts) != 5 {
+		return false
+	}
+	params := parseParams(parts[2])
+	ln, r, p := params["ln"], params["r"], params["p"]
+	if ln <= 0 || ln >= 32 || r <= 0 || p <= 0 {
+		return false
+	}
+
+	salt, err := decodeBase64(parts[3])
+	if err != nil {
+		return false
+	}
+	key, err := decodeBase64(parts[4])
+	if err != nil || len(key) == 0 {
+		return false
+	}
+
+	dk, err := scrypt.Key([]byte(password), salt, 1<<ln, r, p, len(key))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(dk, key) == 1
+}
+
+func verifySSHA(encoded, password string, h func() hash.Hash, size int) bool {
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(b) <= size {
+		return false
+	}
+	sum, salt := b[:size], b[size:]
+
+	d := h()
+	d.Write([]byte(password))
+	d.Write(salt)
+	return subtle.ConstantTimeCompare(d.Sum(nil), sum) == 1
+}
+
+const (
+	shaCryptDefaultRounds = 5000
+	shaCryptMinRounds     = 1000
+	shaCryptMaxRounds     = 999999999
+	shaCryptMaxSaltLen    = 16
+	shaCryptAlphabet      = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	sha256CryptPerm = [][3]int{
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29},
+	}
+	sha512CryptPerm = [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41},
+	}
+)
+
+// verifySHACrypt verifies the SHA-crypt ($5$, $6$) hash as specified by
+// https://www.akkadia.org/drepper/SHA-crypt.txt
+func verifySHACrypt(stored, password string, h func() hash.Hash, magic string) bool {
+	s := strings.TrimPrefix(stored, magic)
+
+	rounds := shaCryptDefaultRounds
+	roundsCustom := false
+	if v, ok := strings.CutPrefix(s, "rounds="); ok {
+		n, rest, found := strings.Cut(v, "$")
+		if !found {
+			return false
+		}
+		r, err := strconv.Atoi(n)
+		if err != nil {
+			return false
+		}
+		rounds = min(max(r, shaCryptMinRounds), shaCryptMaxRounds)
+		roundsCustom = true
+		s = rest
+	}
+
+	i := strings.LastIndexByte(s, '$')
+	if i < 0 {
+		return false
+	}
+	salt := s[:i]
+	if len(salt) > shaCryptMaxSaltLen {
+		salt = salt[:shaCryptMaxSaltLen]
+	}
+
+	dk := shaCrypt([]byte(password), []byte(salt), rounds, h)
+
+	var b bytes.Buffer
+	b.WriteString(magic)
+	if roundsCustom {
+		b.WriteString("rounds=")
+		b.WriteString(strconv.Itoa(rounds))
+		b.WriteByte('$')
+	}
+	b.WriteString(salt)
+	b.WriteByte('$')
+	if magic == "$5$" {
+		shaCryptEncode(&b, dk, sha256CryptPerm, []int{31, 30})
+	} else {
+		shaCryptEncode(&b, dk, sha512CryptPerm, []int{63})
+	}
+
+	return subtle.ConstantTimeCompare(b.Bytes(), []byte(stored)) == 1
+}
+
+func shaCrypt(password, salt []byte, rounds int, h func() hash.Hash) []byte {
+	b := h()
+	b.Write(password)
+	b.Write(salt)
+	b.Write(password)
+	sumB := b.Sum(nil)
+	size := len(sumB)
+
+	a := h()
+	a.Write(password)
+	a.Write(salt)
+	for n := len(password); n > 0; n -= size {
+		a.Write(sumB[:min(n, size)])
+	}
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			a.Write(sumB)
+		} else {
+			a.Write(password)
+		}
+	}
+	sumA := a.Sum(nil)
+
+	dp := h()
+	for range password {
+		dp.Write(password)
+	}
+	sumDP := dp.Sum(nil)
+	p := make([]byte, 0, len(password))
+	for n := len(password); n > 0; n -= size {
+		p = append(p, sumDP[:min(n, size)]...)
+	}
+
+	ds := h()
+	for i := 0; i < 16+int(sumA[0]); i++ {
+		ds.Write(salt)
+	}
+	sumDS := ds.Sum(nil)
+	sl := make([]byte, 0, len(salt))
+	for n := len(salt); n > 0; n -= size {
+		sl = append(sl, sumDS[:min(n, size)]...)
+	}
+
+	sum := sumA
+	for i := 0; i < rounds; i++ {
+		c := h()
+		if i&1 != 0 {
+			c.Write(p)
+		} else {
+			c.Write(sum)
+		}
+		if i%3 != 0 {
+			c.Write(sl)
+		}
+		if i%7 != 0 {
+			c.Write(p)
+		}
+		if i&1 != 0 {
+			c.Write(sum)
+		} else {
+			c.Write(p)
+		}
+		sum = c.Sum(nil)
+	}
+	return sum
+}
+
+func shaCryptEncode(b *bytes.Buffer, sum []byte, perm [][3]int, tail []int) {
+	encode := func(v uint, n int) {
+		for ; n > 0; n-- {
+			b.WriteByte(shaCryptAlphabet[v&0x3f])
+			v >>= 6
+		}
+	}
+
+	for _, p := range perm {
+		encode(uint(sum[p[0]])<<16|uint(sum[p[1]])<<8|uint(sum[p[2]]), 4)
+	}
+	if len(tail) == 2 {
+		encode(uint(sum[tail[0]])<<8|uint(sum[tail[1]]), 3)
+	} else {
+		encode(uint(sum[tail[0]]), 2)
+	}
+}
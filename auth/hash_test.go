@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+func TestVerifyPassword(t *testing.T) {
+	const password = "Hello world!"
+
+	bc, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt := []byte("0123456789abcdef")
+	b64 := base64.RawStdEncoding.EncodeToString
+	argon := "$argon2id$v=19$m=1024,t=1,p=1$" + b64(salt) + "$" +
+		b64(argon2.IDKey([]byte(password), salt, 1, 1024, 1, 32))
+	sk, err := scrypt.Key([]byte(password), salt, 1<<10, 8, 1, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := "$scrypt$ln=10,r=8,p=1$" + b64(salt) + "$" + b64(sk)
+	sum := sha256.Sum256(append([]byte(password), salt...))
+	ssha := "{SSHA256}" + base64.StdEncoding.EncodeToString(append(sum[:], salt...))
+
+	testCases := []struct {
+		stored string
+		ok     bool
+	}{
+		{stored: password, ok: true},
+		{stored: "hello world!", ok: false},
+		{stored: string(bc), ok: true},
+		{stored: "$2y$" + string(bc[4:]), ok: true},
+		{stored: argon, ok: true},
+		{stored: sc, ok: true},
+		{stored: ssha, ok: true},
+		{stored: "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", ok: true},
+		{stored: "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA", ok: true},
+		{stored: "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", ok: true},
+		{stored: "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.", ok: true},
+		{stored: "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc6", ok: false},
+		{stored: "$argon2id$v=19$m=1024,t=1,p=1$invalid", ok: false},
+	}
+
+	for _, tc := range testCases {
+		if got := verifyPassword(tc.stored, password); got != tc.ok {
+			t.Errorf("%s: got %v, want %v", tc.stored, got, tc.ok)
+		}
+	}
+}
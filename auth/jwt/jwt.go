@@ -0,0 +1,505 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/auth"
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/internal/loader"
+	xlogger "github.com/go-gost/x/logger"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+const (
+	defaultClientIDClaim = "sub"
+	// minRefreshInterval is the minimum interval of reloading the JWKS document
+	// when a token signed by an unknown key is received.
+	minRefreshInterval = 30 * time.Second
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrUnsupportedAlg   = errors.New("unsupported algorithm")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrKeyNotFound      = errors.New("key not found")
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrMissingExp       = errors.New("missing exp claim")
+	ErrTokenNotValidYet = errors.New("token is not valid yet")
+	ErrInvalidIssuer    = errors.New("invalid issuer")
+	ErrInvalidAudience  = errors.New("invalid audience")
+	ErrMissingClientID  = errors.New("missing client ID claim")
+)
+
+type options struct {
+	secret        []byte
+	keys          []crypto.PublicKey
+	jwksLoader    loader.Loader
+	period        time.Duration
+	issuer        string
+	audience      []string
+	clientIDClaim string
+	leeway        time.Duration
+	allowNoExp    bool
+	logger        logger.Logger
+}
+
+type Option func(opts *options)
+
+// SecretOption sets the shared secret for HS256.
+func SecretOption(secret []byte) Option {
+	return func(opts *options) {
+		opts.secret = secret
+	}
+}
+
+// KeysOption sets the static public keys for RS256 and ES256.
+func KeysOption(keys ...crypto.PublicKey) Option {
+	return func(opts *options) {
+		opts.keys = keys
+	}
+}
+
+// JWKSLoaderOption sets the loader of the JWKS document.
+func JWKSLoaderOption(jwksLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.jwksLoader = jwksLoader
+	}
+}
+
+func ReloadPeriodOption(period time.Duration) Option {
+	return func(opts *options) {
+		opts.period = period
+	}
+}
+
+// IssuerOption sets the expected iss claim.
+func IssuerOption(issuer string) Option {
+	return func(opts *options) {
+		opts.issuer = issuer
+	}
+}
+
+// AudienceOption sets the accepted audiences, the aud claim must contain one of them.
+func AudienceOption(audience []string) Option {
+	return func(opts *options) {
+		opts.audience = audience
+	}
+}
+
+// ClientIDClaimOption sets the claim used as the client ID, default is sub.
+func ClientIDClaimOption(claim string) Option {
+	return func(opts *options) {
+		opts.clientIDClaim = claim
+	}
+}
+
+// LeewayOption sets the allowed clock skew for the exp and nbf claims.
+func LeewayOption(leeway time.Duration) Option {
+	return func(opts *options) {
+		opts.leeway = leeway
+	}
+}
+
+// AllowNoExpOption accepts the tokens without the exp claim, which never expire.
+// By default the exp claim is required.
+func AllowNoExpOption(allow bool) Option {
+	return func(opts *options) {
+		opts.allowNoExp = allow
+	}
+}
+
+func LoggerOption(logger logger.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+type key struct {
+	kid string
+	alg string
+	// []byte for HMAC, *rsa.PublicKey or *ecdsa.PublicKey.
+	key any
+}
+
+// authenticator is an Authenticator that authenticates client by JSON Web Token (RFC 7519).
+// The token is passed as the password, and the configured claim is returned as the client ID.
+type authenticator struct {
+	keys       []key
+	jwksKeys   []key
+	loadTime   time.Time
+	mu         sync.RWMutex
+	reloadMu   sync.Mutex
+	cancelFunc context.CancelFunc
+	options    options
+	logger     logger.Logger
+}
+
+// NewAuthenticator creates an Authenticator that authenticates client by JWT.
+func NewAuthenticator(opts ...Option) auth.Authenticator {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.clientIDClaim == "" {
+		options.clientIDClaim = defaultClientIDClaim
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &authenticator{
+		cancelFunc: cancel,
+		options:    options,
+		logger:     options.logger,
+	}
+	if p.logger == nil {
+		p.logger = xlogger.Nop()
+	}
+
+	if len(options.secret) > 0 {
+		p.keys = append(p.keys, key{alg: AlgHS256, key: options.secret})
+	}
+	for _, k := range options.keys {
+		switch k.(type) {
+		case *rsa.PublicKey, *ecdsa.PublicKey:
+			p.keys = append(p.keys, key{key: k})
+		}
+	}
+
+	if options.jwksLoader != nil {
+		go p.periodReload(ctx)
+	}
+
+	return p
+}
+
+// Authenticate verifies the token in password, the username is ignored.
+func (p *authenticator) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
+	if p == nil {
+		return "", true
+	}
+
+	claims, err := p.verify(ctx, password, time.Now())
+	if err != nil {
+		p.logger.Debugf("jwt: %v", err)
+		return "", false
+	}
+
+	id := claimString(claims[p.options.clientIDClaim])
+	if id == "" {
+		p.logger.Debugf("jwt: %v: %s", ErrMissingClientID, p.options.clientIDClaim)
+		return "", false
+	}
+	return id, true
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (p *authenticator) verify(ctx context.Context, token string, now time.Time) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	var h header
+	if err := json.Unmarshal(b, &h); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if err := p.verifySignature(ctx, &h, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	b, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var claims map[string]any
+	if err := dec.Decode(&claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if err := p.validateClaims(claims, now); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (p *authenticator) verifySignature(ctx context.Context, h *header, input, sig []byte) error {
+	switch h.Alg {
+	case AlgHS256, AlgRS256, AlgES256:
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlg, h.Alg)
+	}
+
+	keys, found := p.keysFor(h)
+	if !found && p.options.jwksLoader != nil && h.Kid != "" {
+		// the signing key may be rotated, reload the JWKS document.
+		p.refresh(ctx)
+		keys, _ = p.keysFor(h)
+	}
+	if len(keys) == 0 {
+		return ErrKeyNotFound
+	}
+
+	for _, k := range keys {
+		if verifySignature(h.Alg, k.key, input, sig) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// keysFor returns the candidate keys for the token header h,
+// the bool value reports whether a key with the same key ID is found.
+func (p *authenticator) keysFor(h *header) (keys []key, found bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, list := range [][]key{p.keys, p.jwksKeys} {
+		for _, k := range list {
+			if k.alg != "" && k.alg != h.Alg {
+				continue
+			}
+			if h.Kid != "" && k.kid != "" {
+				if k.kid != h.Kid {
+					continue
+				}
+				found = true
+			}
+			if !keyMatchAlg(k.key, h.Alg) {
+				continue
+			}
+			keys = append(keys, k)
+		}
+	}
+	return
+}
+
+func keyMatchAlg(k any, alg string) bool {
+	switch v := k.(type) {
+	case []byte:
+		return alg == AlgHS256
+	case *rsa.PublicKey:
+		return alg == AlgRS256
+	case *ecdsa.PublicKey:
+		return alg == AlgES256 && v.Curve == elliptic.P256()
+	}
+	return false
+}
+
+func verifySignature(alg string, k any, input, sig []byte) bool {
+	sum := sha256.Sum256(input)
+
+	switch alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, k.([]byte))
+		mac.Write(input)
+		return hmac.Equal(mac.Sum(nil), sig)
+	case AlgRS256:
+		return rsa.VerifyPKCS1v15(k.(*rsa.PublicKey), crypto.SHA256, sum[:], sig) == nil
+	case AlgES256:
+		// the signature is the concatenation of R and S (RFC 7518, section 3.4).
+		if len(sig) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(k.(*ecdsa.PublicKey), sum[:], r, s)
+	}
+	return false
+}
+
+func (p *authenticator) validateClaims(claims map[string]any, now time.Time) error {
+	leeway := p.options.leeway
+
+	if v, ok := claims["exp"]; ok {
+		exp, ok := numericDate(v)
+		if !ok {
+			return ErrMalformedToken
+		}
+		if !now.Before(exp.Add(leeway)) {
+			return ErrTokenExpired
+		}
+	} else if !p.options.allowNoExp {
+		return ErrMissingExp
+	}
+	if v, ok := claims["nbf"]; ok {
+		nbf, ok := numericDate(v)
+		if !ok {
+			return ErrMalformedToken
+		}
+		if now.Add(leeway).Before(nbf) {
+			return ErrTokenNotValidYet
+		}
+	}
+
+	if p.options.issuer != "" && claimString(claims["iss"]) != p.options.issuer {
+		return ErrInvalidIssuer
+	}
+
+	if len(p.options.audience) > 0 {
+		var auds []string
+		switch v := claims["aud"].(type) {
+		case string:
+			auds = []string{v}
+		case []any:
+			for _, s := range v {
+				if s, ok := s.(string); ok {
+					auds = append(auds, s)
+				}
+			}
+		}
+		if !containsAny(auds, p.options.audience) {
+			return ErrInvalidAudience
+		}
+	}
+
+	return nil
+}
+
+func numericDate(v any) (time.Time, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), true
+}
+
+func claimString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	}
+	return ""
+}
+
+func containsAny(list, values []string) bool {
+	for _, s := range list {
+		for _, v := range values {
+			if s == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *authenticator) periodReload(ctx context.Context) error {
+	if err := p.reload(ctx); err != nil {
+		p.logger.Warnf("reload: %v", err)
+	}
+
+	period := p.options.period
+	if period <= 0 {
+		return nil
+	}
+	if period < time.Second {
+		period = time.Second
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.reload(ctx); err != nil {
+				p.logger.Warnf("reload: %v", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// refresh reloads the JWKS document if it has not been loaded recently.
+func (p *authenticator) refresh(ctx context.Context) {
+	p.mu.RLock()
+	loadTime := p.loadTime
+	p.mu.RUnlock()
+
+	if time.Since(loadTime) < minRefreshInterval {
+		return
+	}
+	if err := p.reload(ctx); err != nil {
+		p.logger.Warnf("reload: %v", err)
+	}
+}
+
+func (p *authenticator) reload(ctx context.Context) error {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+
+	p.mu.Lock()
+	p.loadTime = time.Now()
+	p.mu.Unlock()
+
+	r, err := p.options.jwksLoader.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(r)
+	if err != nil {
+		return err
+	}
+
+	p.logger.Debugf("load jwks keys %d", len(keys))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.jwksKeys = keys
+
+	return nil
+}
+
+func (p *authenticator) Close() error {
+	p.cancelFunc()
+	if p.options.jwksLoader != nil {
+		p.options.jwksLoader.Close()
+	}
+	return nil
+}
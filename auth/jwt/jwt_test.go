@@ -0,0 +1,187 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func sign(t *testing.T, alg, kid string, k any, claims map[string]any) string {
+	t.Helper()
+
+	h := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		h["kid"] = kid
+	}
+	hb, _ := json.Marshal(h)
+	cb, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(hb) + "." + base64.RawURLEncoding.EncodeToString(cb)
+	sum := sha256.Sum256([]byte(input))
+
+	var sig []byte
+	switch alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, k.([]byte))
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case AlgRS256:
+		b, err := rsa.SignPKCS1v15(rand.Reader, k.(*rsa.PrivateKey), crypto.SHA256, sum[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = b
+	case AlgES256:
+		r, s, err := ecdsa.Sign(rand.Reader, k.(*ecdsa.PrivateKey), sum[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	default:
+		sig = []byte("invalid")
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestAuthenticate(t *testing.T) {
+	secret := []byte("secret")
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	au := NewAuthenticator(
+		SecretOption(secret),
+		KeysOption(&rsaKey.PublicKey, &ecKey.PublicKey),
+		IssuerOption("https://sso.example.com"),
+		AudienceOption([]string{"gost"}),
+		LeewayOption(time.Second),
+	)
+
+	now := time.Now().Unix()
+	valid := map[string]any{
+		"sub": "alice",
+		"iss": "https://sso.example.com",
+		"aud": []string{"other", "gost"},
+		"exp": now + 60,
+		"nbf": now - 60,
+	}
+	with := func(k string, v any) map[string]any {
+		m := make(map[string]any)
+		for k, v := range valid {
+			m[k] = v
+		}
+		if v == nil {
+			delete(m, k)
+		} else {
+			m[k] = v
+		}
+		return m
+	}
+
+	cases := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"HS256", sign(t, AlgHS256, "", secret, valid), true},
+		{"RS256", sign(t, AlgRS256, "", rsaKey, valid), true},
+		{"ES256", sign(t, AlgES256, "", ecKey, valid), true},
+		{"wrong secret", sign(t, AlgHS256, "", []byte("guess"), valid), false},
+		{"none", sign(t, "none", "", nil, valid), false},
+		{"expired", sign(t, AlgHS256, "", secret, with("exp", now-10)), false},
+		{"not valid yet", sign(t, AlgHS256, "", secret, with("nbf", now+10)), false},
+		{"issuer", sign(t, AlgHS256, "", secret, with("iss", "https://evil.example.com")), false},
+		{"audience", sign(t, AlgHS256, "", secret, with("aud", "other")), false},
+		{"single audience", sign(t, AlgHS256, "", secret, with("aud", "gost")), true},
+		{"missing sub", sign(t, AlgHS256, "", secret, with("sub", nil)), false},
+		{"missing exp", sign(t, AlgHS256, "", secret, with("exp", nil)), false},
+		{"malformed", "a.b", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			id, ok := au.Authenticate(context.Background(), "", c.token)
+			if ok != c.ok {
+				t.Fatalf("got %v, want %v", ok, c.ok)
+			}
+			if ok && id != "alice" {
+				t.Errorf("client ID %q, want alice", id)
+			}
+		})
+	}
+}
+
+func TestAllowNoExp(t *testing.T) {
+	secret := []byte("secret")
+	token := sign(t, AlgHS256, "", secret, map[string]any{"sub": "alice"})
+
+	if _, ok := NewAuthenticator(SecretOption(secret)).Authenticate(context.Background(), "", token); ok {
+		t.Error("token without exp accepted by default")
+	}
+	if _, ok := NewAuthenticator(SecretOption(secret), AllowNoExpOption(true)).Authenticate(context.Background(), "", token); !ok {
+		t.Error("token without exp rejected")
+	}
+}
+
+type jwksLoader struct {
+	doc []byte
+}
+
+func (l *jwksLoader) Load(ctx context.Context) (io.Reader, error) {
+	return bytes.NewReader(l.doc), nil
+}
+
+func (l *jwksLoader) Close() error {
+	return nil
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	enc := base64.RawURLEncoding.EncodeToString
+	doc := fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"rsa1","use":"sig","alg":"RS256","n":%q,"e":"AQAB"},
+		{"kty":"EC","kid":"ec1","crv":"P-256","x":%q,"y":%q},
+		{"kty":"RSA","kid":"enc1","use":"enc","n":%q,"e":"AQAB"}
+	]}`,
+		enc(rsaKey.N.Bytes()),
+		enc(ecKey.X.FillBytes(make([]byte, 32))), enc(ecKey.Y.FillBytes(make([]byte, 32))),
+		enc(rsaKey.N.Bytes()),
+	)
+
+	au := NewAuthenticator(
+		JWKSLoaderOption(&jwksLoader{doc: []byte(doc)}),
+		ClientIDClaimOption("email"),
+	)
+	p := au.(*authenticator)
+	if err := p.reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(p.jwksKeys); n != 2 {
+		t.Fatalf("%d keys loaded, want 2", n)
+	}
+
+	claims := map[string]any{"email": "alice@example.com", "exp": time.Now().Unix() + 60}
+	if id, ok := au.Authenticate(context.Background(), "", sign(t, AlgRS256, "rsa1", rsaKey, claims)); !ok || id != "alice@example.com" {
+		t.Errorf("RS256: %q %v", id, ok)
+	}
+	if _, ok := au.Authenticate(context.Background(), "", sign(t, AlgES256, "ec1", ecKey, claims)); !ok {
+		t.Error("ES256 rejected")
+	}
+	// the key ID does not match the signing key.
+	if _, ok := au.Authenticate(context.Background(), "", sign(t, AlgRS256, "enc1", rsaKey, claims)); ok {
+		t.Error("token with unknown key ID accepted")
+	}
+}
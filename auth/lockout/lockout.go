@@ -0,0 +1,575 @@
+package lockout
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/admission"
+	"github.com/go-gost/core/auth"
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/metrics"
+	"github.com/go-gost/core/recorder"
+	xctx "github.com/go-gost/x/ctx"
+	xlogger "github.com/go-gost/x/logger"
+	xmetrics "github.com/go-gost/x/metrics"
+)
+
+const (
+	defaultThreshold    = 3
+	defaultWindow       = 10 * time.Minute
+	defaultBackoff      = time.Second
+	defaultMaxBackoff   = 5 * time.Minute
+	defaultBanThreshold = 10
+	defaultBanDuration  = 30 * time.Minute
+
+	sweepInterval = time.Minute
+)
+
+const (
+	EventFailure = "failure"
+	EventBackoff = "backoff"
+	EventBan     = "ban"
+	EventReject  = "reject"
+)
+
+// Event is the lockout event sent to the recorder.
+type Event struct {
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type"`
+	Auther   string    `json:"auther,omitempty"`
+	Service  string    `json:"service,omitempty"`
+	IP       string    `json:"ip,omitempty"`
+	Username string    `json:"username,omitempty"`
+	Failures int       `json:"failures,omitempty"`
+	Until    time.Time `json:"until,omitempty"`
+}
+
+type options struct {
+	name         string
+	threshold    int
+	window       time.Duration
+	backoff      time.Duration
+	maxBackoff   time.Duration
+	banThreshold int
+	banDuration  time.Duration
+	recorder     recorder.Recorder
+	logger       logger.Logger
+}
+
+type Option func(opts *options)
+
+func NameOption(name string) Option {
+	return func(opts *options) {
+		opts.name = name
+	}
+}
+
+// ThresholdOption sets the number of failures before the backoff applies.
+func ThresholdOption(n int) Option {
+	return func(opts *options) {
+		opts.threshold = n
+	}
+}
+
+// WindowOption sets the duration after which the failures are forgotten if no further failure occurs.
+func WindowOption(d time.Duration) Option {
+	return func(opts *options) {
+		opts.window = d
+	}
+}
+
+func BackoffOption(d time.Duration) Option {
+	return func(opts *options) {
+		opts.backoff = d
+	}
+}
+
+func MaxBackoffOption(d time.Duration) Option {
+	return func(opts *options) {
+		opts.maxBackoff = d
+	}
+}
+
+// BanThresholdOption sets the number of failures to ban the client, a negative value disables the ban.
+func BanThresholdOption(n int) Option {
+	return func(opts *options) {
+		opts.banThreshold = n
+	}
+}
+
+func BanDurationOption(d time.Duration) Option {
+	return func(opts *options) {
+		opts.banDuration = d
+	}
+}
+
+func RecorderOption(recorder recorder.Recorder) Option {
+	return func(opts *options) {
+		opts.recorder = recorder
+	}
+}
+
+func LoggerOption(logger logger.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+type entry struct {
+	failures    int
+	lastFailure time.Time
+	until       time.Time
+	banned      bool
+}
+
+// Tracker tracks the authentication failures per client IP and per username.
+// Once the number of failures reaches the threshold, further attempts are
+// blocked for an exponentially increasing backoff duration, and the client
+// is banned for a while when the number of failures reaches the ban threshold.
+//
+// Tracker is also an admission.Admission which rejects the blocked IPs,
+// so that they are dropped before the handshake.
+type Tracker struct {
+	entries   map[string]*entry
+	sweepTime time.Time
+	mu        sync.Mutex
+	options   options
+}
+
+func NewTracker(opts ...Option) *Tracker {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.threshold <= 0 {
+		options.threshold = defaultThreshold
+	}
+	if options.window <= 0 {
+		options.window = defaultWindow
+	}
+	if options.backoff <= 0 {
+		options.backoff = defaultBackoff
+	}
+	if options.maxBackoff <= 0 {
+		options.maxBackoff = defaultMaxBackoff
+	}
+	if options.maxBackoff < options.backoff {
+		options.maxBackoff = options.backoff
+	}
+	if options.banThreshold == 0 {
+		options.banThreshold = defaultBanThreshold
+	}
+	if options.banDuration <= 0 {
+		options.banDuration = defaultBanDuration
+	}
+	if options.logger == nil {
+		options.logger = xlogger.Nop()
+	}
+
+	return &Tracker{
+		entries: make(map[string]*entry),
+		options: options,
+	}
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func userKey(username string) string {
+	return "user:" + username
+}
+
+// Admit implements admission.Admission, the blocked IPs are rejected.
+func (t *Tracker) Admit(ctx context.Context, addr string, opts ...admission.Option) bool {
+	if t == nil {
+		return true
+	}
+
+	ip := hostOf(addr)
+	if ip == "" {
+		return true
+	}
+
+	if until, ok := t.blocked(ipKey(ip)); ok {
+		t.options.logger.Debugf("lockout: %s is blocked until %s", ip, until.Format(time.RFC3339))
+		t.reject(ctx, "", ip, "", until)
+		return false
+	}
+	return true
+}
+
+// Blocked reports whether the authentication from the client IP or for the username is blocked
+// and until when it is blocked.
+func (t *Tracker) Blocked(ip, username string) (until time.Time, ok bool) {
+	if t == nil {
+		return
+	}
+
+	if ip != "" {
+		if until, ok = t.blocked(ipKey(ip)); ok {
+			return
+		}
+	}
+	if username != "" {
+		until, ok = t.blocked(userKey(username))
+	}
+	return
+}
+
+func (t *Tracker) blocked(key string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e := t.entries[key]
+	if e == nil || e.until.IsZero() {
+		return time.Time{}, false
+	}
+	if time.Now().Before(e.until) {
+		return e.until, true
+	}
+	if e.banned {
+		// the failures are forgiven once the ban expires.
+		delete(t.entries, key)
+	}
+	return time.Time{}, false
+}
+
+// Fail records an authentication failure from the client IP for the username.
+func (t *Tracker) Fail(ctx context.Context, service, ip, username string) {
+	if t == nil {
+		return
+	}
+
+	t.options.logger.Debugf("lockout: authentication failure from %s, user %q", ip, username)
+	if v := xmetrics.GetCounter(xmetrics.MetricAuthFailuresCounter,
+		metrics.Labels{"auther": t.options.name}); v != nil {
+		v.Inc()
+	}
+
+	if ip != "" {
+		n, until, banned := t.fail(ipKey(ip))
+		t.notify(ctx, service, ip, "", n, until, banned)
+	}
+	if username != "" {
+		n, until, banned := t.fail(userKey(username))
+		t.notify(ctx, service, "", username, n, until, banned)
+	}
+}
+
+func (t *Tracker) fail(key string) (n int, until time.Time, banned bool) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sweep(now)
+
+	e := t.entries[key]
+	if e == nil {
+		e = &entry{}
+		t.entries[key] = e
+	}
+	if now.Sub(e.lastFailure) > t.options.window ||
+		(e.banned && !now.Before(e.until)) {
+		*e = entry{}
+	}
+
+	e.failures++
+	e.lastFailure = now
+
+	switch {
+	case t.options.banThreshold > 0 && e.failures >= t.options.banThreshold:
+		if !e.banned {
+			e.banned = true
+			e.until = now.Add(t.options.banDuration)
+			banned = true
+		}
+	case e.failures >= t.options.threshold:
+		d := t.options.backoff
+		for i := t.options.threshold; i < e.failures && d < t.options.maxBackoff; i++ {
+			d <<= 1
+		}
+		d = min(d, t.options.maxBackoff)
+		e.until = now.Add(d)
+	}
+
+	return e.failures, e.until, banned
+}
+
+// Reset clears the failures of the client IP and the username after a successful authentication.
+func (t *Tracker) Reset(ip, username string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if ip != "" {
+		delete(t.entries, ipKey(ip))
+	}
+	if username != "" {
+		delete(t.entries, userKey(username))
+	}
+}
+
+// sweep removes the expired entries, it must be called with the lock held.
+func (t *Tracker) sweep(now time.Time) {
+	if now.Sub(t.sweepTime) < sweepInterval {
+		return
+	}
+	t.sweepTime = now
+
+	for k, e := range t.entries {
+		if now.Before(e.until) {
+			continue
+		}
+		if e.banned || now.Sub(e.lastFailure) > t.options.window {
+			delete(t.entries, k)
+		}
+	}
+}
+
+func (t *Tracker) notify(ctx context.Context, service, ip, username string, failures int, until time.Time, banned bool) {
+	typ := EventFailure
+	switch {
+	case banned:
+		typ = EventBan
+		t.options.logger.Warnf("lockout: %s banned until %s after %d failures",
+			subject(ip, username), until.Format(time.RFC3339), failures)
+		if v := xmetrics.GetCounter(xmetrics.MetricAuthBansCounter,
+			metrics.Labels{"auther": t.options.name}); v != nil {
+			v.Inc()
+		}
+	case !until.IsZero() && time.Now().Before(until):
+		typ = EventBackoff
+		t.options.logger.Debugf("lockout: %s blocked until %s after %d failures",
+			subject(ip, username), until.Format(time.RFC3339), failures)
+	default:
+		until = time.Time{}
+	}
+
+	t.record(ctx, &Event{
+		Type:     typ,
+		Service:  service,
+		IP:       ip,
+		Username: username,
+		Failures: failures,
+		Until:    until,
+	})
+}
+
+func (t *Tracker) reject(ctx context.Context, service, ip, username string, until time.Time) {
+	if v := xmetrics.GetCounter(xmetrics.MetricAuthRejectsCounter,
+		metrics.Labels{"auther": t.options.name}); v != nil {
+		v.Inc()
+	}
+
+	t.record(ctx, &Event{
+		Type:     EventReject,
+		Service:  service,
+		IP:       ip,
+		Username: username,
+		Until:    until,
+	})
+}
+
+func (t *Tracker) record(ctx context.Context, event *Event) {
+	if t.options.recorder == nil {
+		return
+	}
+
+	event.Time = time.Now()
+	event.Auther = t.options.name
+	b, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if err := t.options.recorder.Record(ctx, b); err != nil {
+		t.options.logger.Errorf("lockout: record: %v", err)
+	}
+}
+
+func subject(ip, username string) string {
+	if username != "" {
+		return "user " + username
+	}
+	return ip
+}
+
+type authenticator struct {
+	auther  auth.Authenticator
+	tracker *Tracker
+}
+
+// WrapAuthenticator wraps the authenticator a with the brute-force protection of the tracker t.
+// The client IP is obtained from the source address in the context.
+// In a group wrapped by WrapGroup, the authentication is tracked by the group instead.
+func WrapAuthenticator(a auth.Authenticator, t *Tracker) auth.Authenticator {
+	if a == nil || t == nil {
+		return a
+	}
+	return &authenticator{
+		auther:  a,
+		tracker: t,
+	}
+}
+
+func (p *authenticator) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
+	if st, _ := ctx.Value(groupKey{}).(*groupState); st != nil {
+		// the result is tracked by the group.
+		id, ok := p.auther.Authenticate(ctx, user, password, opts...)
+		if ok {
+			st.accepted = true
+		}
+		return id, ok
+	}
+
+	return authenticate(ctx, p.auther, []*Tracker{p.tracker}, user, password, opts...)
+}
+
+func (p *authenticator) Close() error {
+	if closer, ok := p.auther.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// AuthenticateCert forwards the certificate authentication to the wrapped authenticator.
+func (p *authenticator) AuthenticateCert(ctx context.Context, opts ...auth.Option) (string, bool) {
+	if v, ok := p.auther.(interface {
+		AuthenticateCert(ctx context.Context, opts ...auth.Option) (string, bool)
+	}); ok {
+		return v.AuthenticateCert(ctx, opts...)
+	}
+	return "", false
+}
+
+// List forwards to the wrapped authenticator, it returns nil if the users cannot be enumerated.
+func (p *authenticator) List(ctx context.Context) map[string]string {
+	if lister, ok := p.auther.(interface {
+		List(ctx context.Context) map[string]string
+	}); ok {
+		return lister.List(ctx)
+	}
+	return nil
+}
+
+// Tracker returns the tracker of the wrapped authenticator.
+func (p *authenticator) Tracker() *Tracker {
+	return p.tracker
+}
+
+type groupKey struct{}
+
+// groupState is the state of an authentication by the group.
+type groupState struct {
+	// accepted reports whether the credentials are accepted by an auther with lockout,
+	// even if the login is then rejected by another wrapper, such as the quota.
+	accepted bool
+}
+
+type groupAuthenticator struct {
+	auther   auth.Authenticator
+	trackers []*Tracker
+}
+
+// WrapGroup wraps the group g of the authers with the lockout trackers of the authers.
+// The authers of the group are tried in turn, so the failure is recorded only if the login is rejected by the whole group,
+// and the failures are cleared if the login is accepted by any auther of the group.
+func WrapGroup(g auth.Authenticator, authers ...auth.Authenticator) auth.Authenticator {
+	var trackers []*Tracker
+	for _, a := range authers {
+		v, ok := a.(interface{ Tracker() *Tracker })
+		if !ok {
+			continue
+		}
+		if t := v.Tracker(); t != nil && !slices.Contains(trackers, t) {
+			trackers = append(trackers, t)
+		}
+	}
+	if g == nil || len(trackers) == 0 {
+		return g
+	}
+	return &groupAuthenticator{
+		auther:   g,
+		trackers: trackers,
+	}
+}
+
+func (p *groupAuthenticator) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
+	return authenticate(ctx, p.auther, p.trackers, user, password, opts...)
+}
+
+// AuthenticateCert forwards the certificate authentication to the wrapped authenticator.
+func (p *groupAuthenticator) AuthenticateCert(ctx context.Context, opts ...auth.Option) (string, bool) {
+	if v, ok := p.auther.(interface {
+		AuthenticateCert(ctx context.Context, opts ...auth.Option) (string, bool)
+	}); ok {
+		return v.AuthenticateCert(ctx, opts...)
+	}
+	return "", false
+}
+
+// List forwards to the group, it returns nil if the users cannot be enumerated.
+func (p *groupAuthenticator) List(ctx context.Context) map[string]string {
+	if lister, ok := p.auther.(interface {
+		List(ctx context.Context) map[string]string
+	}); ok {
+		return lister.List(ctx)
+	}
+	return nil
+}
+
+// authenticate authenticates by a with the brute-force protection of the trackers.
+// The failures are cleared if the login or the credentials are accepted.
+func authenticate(ctx context.Context, a auth.Authenticator, trackers []*Tracker, user, password string, opts ...auth.Option) (string, bool) {
+	var options auth.Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var ip string
+	if addr := xctx.SrcAddrFromContext(ctx); addr != nil {
+		ip = hostOf(addr.String())
+	}
+
+	for _, t := range trackers {
+		if until, ok := t.Blocked(ip, user); ok {
+			t.options.logger.Debugf("lockout: authentication from %s, user %q is blocked until %s",
+				ip, user, until.Format(time.RFC3339))
+			t.reject(ctx, options.Service, ip, user, until)
+			return "", false
+		}
+	}
+
+	st := &groupState{}
+	id, ok := a.Authenticate(context.WithValue(ctx, groupKey{}, st), user, password, opts...)
+	if user == "" && password == "" {
+		// an attempt without credentials, such as the client certificate authentication, is not counted.
+		return id, ok
+	}
+
+	for _, t := range trackers {
+		if ok || st.accepted {
+			t.Reset(ip, user)
+		} else {
+			t.Fail(ctx, options.Service, ip, user)
+		}
+	}
+	return id, ok
+}
+
+func hostOf(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
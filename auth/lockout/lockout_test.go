@@ -0,0 +1,187 @@
+package lockout
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/auth"
+	xauth "github.com/go-gost/x/auth"
+	xctx "github.com/go-gost/x/ctx"
+)
+
+type staticAuther map[string]string
+
+func (a staticAuther) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
+	if p, ok := a[user]; ok && p == password {
+		return user, true
+	}
+	return "", false
+}
+
+func TestTrackerBackoff(t *testing.T) {
+	tr := NewTracker(
+		ThresholdOption(2),
+		BackoffOption(time.Second),
+		MaxBackoffOption(4*time.Second),
+		BanThresholdOption(-1),
+	)
+
+	ctx := context.Background()
+	tr.Fail(ctx, "", "10.0.0.1", "")
+	if _, ok := tr.Blocked("10.0.0.1", ""); ok {
+		t.Fatal("blocked before reaching the threshold")
+	}
+
+	var prev time.Duration
+	for i := 0; i < 4; i++ {
+		tr.Fail(ctx, "", "10.0.0.1", "")
+		until, ok := tr.Blocked("10.0.0.1", "")
+		if !ok {
+			t.Fatalf("failure %d: not blocked", i+2)
+		}
+		d := time.Until(until).Round(time.Second)
+		if d < prev || d > 4*time.Second {
+			t.Errorf("failure %d: backoff %s, previous %s", i+2, d, prev)
+		}
+		prev = d
+	}
+	if prev != 4*time.Second {
+		t.Errorf("backoff %s, want capped at 4s", prev)
+	}
+
+	if _, ok := tr.Blocked("10.0.0.2", ""); ok {
+		t.Error("other IP is blocked")
+	}
+
+	tr.Reset("10.0.0.1", "")
+	if _, ok := tr.Blocked("10.0.0.1", ""); ok {
+		t.Error("blocked after reset")
+	}
+}
+
+func TestTrackerBan(t *testing.T) {
+	tr := NewTracker(
+		ThresholdOption(100),
+		BanThresholdOption(3),
+		BanDurationOption(time.Hour),
+	)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		tr.Fail(ctx, "", "10.0.0.1", "alice")
+	}
+
+	if tr.Admit(ctx, "10.0.0.1:1234") {
+		t.Error("banned IP is admitted")
+	}
+	if !tr.Admit(ctx, "10.0.0.2:1234") {
+		t.Error("IP is not admitted")
+	}
+	if _, ok := tr.Blocked("10.0.0.2", "alice"); !ok {
+		t.Error("banned username is not blocked")
+	}
+}
+
+func TestAuthenticator(t *testing.T) {
+	tr := NewTracker(ThresholdOption(1), BanThresholdOption(-1), BackoffOption(time.Hour))
+	au := WrapAuthenticator(staticAuther{"alice": "secret"}, tr)
+
+	ctx := xctx.ContextWithSrcAddr(context.Background(), &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234})
+	if _, ok := au.Authenticate(ctx, "alice", "secret"); !ok {
+		t.Fatal("valid credentials are rejected")
+	}
+	if _, ok := au.Authenticate(ctx, "bob", "guess"); ok {
+		t.Fatal("invalid credentials are accepted")
+	}
+	// the client is blocked even with valid credentials.
+	if _, ok := au.Authenticate(ctx, "alice", "secret"); ok {
+		t.Error("blocked client is authenticated")
+	}
+}
+
+type listAuther struct {
+	staticAuther
+}
+
+func (a listAuther) List(ctx context.Context) map[string]string {
+	return a.staticAuther
+}
+
+func TestAuthenticatorList(t *testing.T) {
+	tr := NewTracker()
+
+	au := WrapAuthenticator(listAuther{staticAuther{"alice": "secret"}}, tr)
+	lister, ok := au.(interface {
+		List(ctx context.Context) map[string]string
+	})
+	if !ok {
+		t.Fatal("wrapped authenticator is not a lister")
+	}
+	if m := lister.List(context.Background()); m["alice"] != "secret" {
+		t.Errorf("got %v, want the users of the wrapped authenticator", m)
+	}
+
+	au = WrapAuthenticator(staticAuther{"alice": "secret"}, tr)
+	if m := au.(interface {
+		List(ctx context.Context) map[string]string
+	}).List(context.Background()); m != nil {
+		t.Errorf("got %v, want nil for an authenticator which cannot list", m)
+	}
+}
+
+// rejectAuther rejects the logins accepted by the wrapped authenticator, like the quota.
+type rejectAuther struct {
+	auth.Authenticator
+}
+
+func (a rejectAuther) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
+	a.Authenticator.Authenticate(ctx, user, password, opts...)
+	return "", false
+}
+
+func TestGroup(t *testing.T) {
+	tr := NewTracker(ThresholdOption(2), BanThresholdOption(3), BackoffOption(time.Hour))
+	a := WrapAuthenticator(staticAuther{"alice": "secret"}, tr)
+	b := staticAuther{"bob": "secret"}
+	au := WrapGroup(xauth.AuthenticatorGroup(a, b), a, b)
+
+	ctx := xctx.ContextWithSrcAddr(context.Background(), &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234})
+	// the logins accepted by the other auther are not failures.
+	for i := 0; i < 5; i++ {
+		if _, ok := au.Authenticate(ctx, "bob", "secret"); !ok {
+			t.Fatalf("login %d: valid credentials are rejected", i)
+		}
+	}
+	// the attempts without credentials are not counted.
+	for i := 0; i < 5; i++ {
+		au.Authenticate(ctx, "", "")
+	}
+	if _, ok := tr.Blocked("10.0.0.1", ""); ok {
+		t.Fatal("blocked without failure")
+	}
+
+	// the credentials accepted by the auther with lockout are not failures.
+	ra := rejectAuther{a}
+	rau := WrapGroup(xauth.AuthenticatorGroup(ra), a)
+	for i := 0; i < 3; i++ {
+		rau.Authenticate(ctx, "alice", "secret")
+	}
+	if _, ok := tr.Blocked("10.0.0.1", "alice"); ok {
+		t.Fatal("blocked for accepted credentials")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, ok := au.Authenticate(ctx, "bob", "guess"); ok {
+			t.Fatal("invalid credentials are accepted")
+		}
+	}
+	if _, ok := au.Authenticate(ctx, "bob", "secret"); ok {
+		t.Error("blocked client is authenticated")
+	}
+
+	if _, ok := WrapGroup(b, b).(staticAuther); !ok {
+		t.Error("group without lockout is wrapped")
+	}
+}
@@ -65,6 +65,11 @@ func (c *Chain) AddHop(hop hop.Hop) {
 	c.hops = append(c.hops, hop)
 }
 
+// Hops returns the hops of the chain.
+func (c *Chain) Hops() []hop.Hop {
+	return c.hops
+}
+
 // Metadata implements metadata.Metadatable interface.
 func (c *Chain) Metadata() metadata.Metadata {
 	return c.metadata
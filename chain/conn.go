@@ -0,0 +1,109 @@
+package chain
+
+import (
+	"context"
+	"net"
+	"sync"
+	"syscall"
+
+	xctx "github.com/go-gost/x/ctx"
+	xio "github.com/go-gost/x/internal/io"
+)
+
+// releaseConn calls the release function once the connection is closed.
+type releaseConn struct {
+	net.Conn
+	release func()
+	once    sync.Once
+}
+
+func wrapConn(c net.Conn, release func()) net.Conn {
+	if c == nil {
+		release()
+		return c
+	}
+
+	cc := &releaseConn{
+		Conn:    c,
+		release: release,
+	}
+	if pc, ok := c.(net.PacketConn); ok {
+		return &releasePacketConn{
+			releaseConn: cc,
+			pc:          pc,
+		}
+	}
+	return cc
+}
+
+func (c *releaseConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
+
+func (c *releaseConn) CloseRead() error {
+	if sc, ok := c.Conn.(xio.CloseRead); ok {
+		return sc.CloseRead()
+	}
+	return xio.ErrUnsupported
+}
+
+func (c *releaseConn) CloseWrite() error {
+	if sc, ok := c.Conn.(xio.CloseWrite); ok {
+		return sc.CloseWrite()
+	}
+	return xio.ErrUnsupported
+}
+
+func (c *releaseConn) SyscallConn() (syscall.RawConn, error) {
+	if sc, ok := c.Conn.(syscall.Conn); ok {
+		return sc.SyscallConn()
+	}
+	return nil, xio.ErrUnsupported
+}
+
+func (c *releaseConn) Context() context.Context {
+	if v, ok := c.Conn.(xctx.Context); ok {
+		return v.Context()
+	}
+	return nil
+}
+
+// releasePacketConn keeps the net.PacketConn interface of the wrapped connection.
+type releasePacketConn struct {
+	*releaseConn
+	pc net.PacketConn
+}
+
+func (c *releasePacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	return c.pc.ReadFrom(p)
+}
+
+func (c *releasePacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	return c.pc.WriteTo(p, addr)
+}
+
+// releaseListener calls the release function once the listener is closed.
+type releaseListener struct {
+	net.Listener
+	release func()
+	once    sync.Once
+}
+
+func wrapListener(ln net.Listener, release func()) net.Listener {
+	if ln == nil {
+		release()
+		return ln
+	}
+	return &releaseListener{
+		Listener: ln,
+		release:  release,
+	}
+}
+
+func (ln *releaseListener) Close() error {
+	err := ln.Listener.Close()
+	ln.once.Do(ln.release)
+	return err
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/go-gost/x/internal/net/dialer"
 	"github.com/go-gost/x/internal/net/udp"
 	xmetrics "github.com/go-gost/x/metrics"
+	xs "github.com/go-gost/x/selector"
 )
 
 var (
@@ -149,7 +150,8 @@ func (r *chainRoute) Dial(ctx context.Context, network, address string, opts ...
 		}
 		return nil, err
 	}
-	return cc, nil
+
+	return wrapConn(cc, r.acquire()), nil
 }
 
 func (r *chainRoute) Bind(ctx context.Context, network, address string, opts ...chain.BindOption) (net.Listener, error) {
@@ -182,12 +184,13 @@ func (r *chainRoute) Bind(ctx context.Context, network, address string, opts ...
 		return nil, err
 	}
 
-	return ln, nil
+	return wrapListener(ln, r.acquire()), nil
 }
 
 func (r *chainRoute) connect(ctx context.Context, logger logger.Logger) (conn net.Conn, err error) {
 	network := "ip"
 	node := r.nodes[0]
+	begin := time.Now()
 
 	defer func() {
 		if r.options.Chain != nil {
@@ -223,6 +226,7 @@ func (r *chainRoute) connect(ctx context.Context, logger logger.Logger) (conn ne
 		if marker != nil {
 			marker.Mark()
 		}
+		xs.StatsOf(node).SetError(err)
 		return
 	}
 
@@ -232,6 +236,7 @@ func (r *chainRoute) connect(ctx context.Context, logger logger.Logger) (conn ne
 		if marker != nil {
 			marker.Mark()
 		}
+		xs.StatsOf(node).SetError(err)
 		return
 	}
 
@@ -241,12 +246,14 @@ func (r *chainRoute) connect(ctx context.Context, logger logger.Logger) (conn ne
 		if marker != nil {
 			marker.Mark()
 		}
+		xs.StatsOf(node).SetError(err)
 		return
 	}
 	if marker != nil {
 		marker.Reset()
 	}
 
+	xs.StatsOf(node).Observe(time.Since(start))
 	if r.options.Chain != nil {
 		var name string
 		if cn, _ := r.options.Chain.(chainNamer); cn != nil {
@@ -261,12 +268,14 @@ func (r *chainRoute) connect(ctx context.Context, logger logger.Logger) (conn ne
 	preNode := node
 	for _, node := range r.nodes[1:] {
 		marker := node.Marker()
+		start := time.Now()
 		addr, err = xnet.Resolve(ctx, network, node.Addr, node.Options().Resolver, node.Options().HostMapper, logger)
 		if err != nil {
 			cn.Close()
 			if marker != nil {
 				marker.Mark()
 			}
+			xs.StatsOf(node).SetError(err)
 			return
 		}
 		cc, err = preNode.Options().Transport.Connect(ctx, cn, "tcp", addr)
@@ -275,6 +284,7 @@ func (r *chainRoute) connect(ctx context.Context, logger logger.Logger) (conn ne
 			if marker != nil {
 				marker.Mark()
 			}
+			xs.StatsOf(node).SetError(err)
 			return
 		}
 		cc, err = node.Options().Transport.Handshake(ctx, cc)
@@ -283,20 +293,41 @@ func (r *chainRoute) connect(ctx context.Context, logger logger.Logger) (conn ne
 			if marker != nil {
 				marker.Mark()
 			}
+			xs.StatsOf(node).SetError(err)
 			return
 		}
 		if marker != nil {
 			marker.Reset()
 		}
+		xs.StatsOf(node).Observe(time.Since(start))
 
 		cn = cc
 		preNode = node
 	}
 
+	xs.StatsOf(r.options.Chain).Observe(time.Since(begin))
+
 	conn = cn
 	return
 }
 
+// acquire counts the connection established on this route as active
+// for the load-aware selector strategies, until the returned function is called.
+func (r *chainRoute) acquire() (release func()) {
+	var releases []func()
+	for _, node := range r.nodes {
+		releases = append(releases, xs.StatsOf(node).Acquire())
+	}
+	if r.options.Chain != nil {
+		releases = append(releases, xs.StatsOf(r.options.Chain).Acquire())
+	}
+	return func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+}
+
 func (r *chainRoute) getNode(index int) *chain.Node {
 	if r == nil || len(r.Nodes()) == 0 || index < 0 || index >= len(r.Nodes()) {
 		return nil
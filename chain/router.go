@@ -10,6 +10,7 @@ import (
 	"github.com/go-gost/core/chain"
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/core/recorder"
+	"github.com/go-gost/x/conntrack"
 	xctx "github.com/go-gost/x/ctx"
 	ictx "github.com/go-gost/x/internal/ctx"
 	xnet "github.com/go-gost/x/internal/net"
@@ -60,6 +61,14 @@ func (r *Router) Dial(ctx context.Context, network, address string) (conn net.Co
 		return
 	}
 
+	if tc := conntrack.ConnFromContext(ctx); tc != nil {
+		tc.SetClientID(string(xctx.ClientIDFromContext(ctx)))
+		tc.SetHost(address)
+		if addr := conn.RemoteAddr(); addr != nil {
+			tc.SetDstAddr(addr.String())
+		}
+	}
+
 	if network == "udp" || network == "udp4" || network == "udp6" {
 		if _, ok := conn.(net.PacketConn); !ok {
 			return &packetConn{conn}, nil
@@ -137,6 +146,7 @@ func (r *Router) dial(ctx context.Context, network, address string, log logger.L
 			chain.LoggerDialOption(log),
 		)
 		if err == nil {
+			conntrack.ConnFromContext(ctx).SetRoute(buf.String())
 			break
 		}
 		log.Errorf("route(retry=%d) %s", i, err)
@@ -193,6 +203,11 @@ func (r *Router) Bind(ctx context.Context, network, address string, opts ...chai
 		log.Errorf("route(retry=%d) %s", i, err)
 	}
 
+	if tc := conntrack.ConnFromContext(ctx); tc != nil && err == nil {
+		tc.SetClientID(string(xctx.ClientIDFromContext(ctx)))
+		tc.SetHost(address)
+	}
+
 	return
 }
 
@@ -0,0 +1,46 @@
+// gost-validate checks the gost config file and reports all the problems found with their paths in config.
+//
+// Usage:
+//
+//	gost-validate -C gost.yaml
+//
+// The exit code is 1 if any error is found, or any warning is found in strict mode.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-gost/x/config/parsing/parser"
+	"github.com/go-gost/x/config/validate"
+)
+
+func main() {
+	var (
+		cfgFile string
+		strict  bool
+	)
+	flag.StringVar(&cfgFile, "C", "", "configuration file")
+	flag.BoolVar(&strict, "strict", false, "treat warnings as errors")
+	flag.Parse()
+
+	parser.Init(parser.Args{
+		CfgFile: cfgFile,
+	})
+	cfg, err := parser.Parse()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	errs := validate.Validate(cfg)
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	if errs.Err() != nil || (strict && len(errs) > 0) {
+		os.Exit(1)
+	}
+	fmt.Println("config OK")
+}
@@ -291,7 +291,9 @@ func BuildConfigFromCmd(serviceList, nodeList []string) (*config.Config, error)
 		out := mdutil.GetString(md, "limiter.out")
 		cin := mdutil.GetString(md, "limiter.conn.in")
 		cout := mdutil.GetString(md, "limiter.conn.out")
-		if in != "" || cin != "" || out != "" || cout != "" {
+		uin := mdutil.GetString(md, "limiter.client.in")
+		uout := mdutil.GetString(md, "limiter.client.out")
+		if in != "" || cin != "" || uin != "" || out != "" || cout != "" || uout != "" {
 			limiter := &config.LimiterConfig{
 				Name: fmt.Sprintf("%slimiter-%d", namePrefix, len(cfg.Limiters)),
 			}
@@ -303,12 +305,18 @@ func BuildConfigFromCmd(serviceList, nodeList []string) (*config.Config, error)
 				limiter.Limits = append(limiter.Limits,
 					fmt.Sprintf("%s %s %s", traffic.ConnLimitKey, cin, cout))
 			}
+			if uin != "" || uout != "" {
+				limiter.Limits = append(limiter.Limits,
+					fmt.Sprintf("%s %s %s", traffic.ClientLimitKey, uin, uout))
+			}
 			service.Limiter = limiter.Name
 			cfg.Limiters = append(cfg.Limiters, limiter)
 			delete(mh, "limiter.in")
 			delete(mh, "limiter.out")
 			delete(mh, "limiter.conn.in")
 			delete(mh, "limiter.conn.out")
+			delete(mh, "limiter.client.in")
+			delete(mh, "limiter.client.out")
 		}
 
 		if climit := mdutil.GetInt(md, "climiter"); climit > 0 {
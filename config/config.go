@@ -140,6 +140,54 @@ type AutherConfig struct {
 	Redis  *RedisLoader  `yaml:",omitempty" json:"redis,omitempty"`
 	HTTP   *HTTPLoader   `yaml:"http,omitempty" json:"http,omitempty"`
 	Plugin *PluginConfig `yaml:",omitempty" json:"plugin,omitempty"`
+	JWT    *JWTConfig    `yaml:"jwt,omitempty" json:"jwt,omitempty"`
+	// TLS client certificate, the loaders provide the allow-list of client IDs
+	Cert *CertAutherConfig `yaml:",omitempty" json:"cert,omitempty"`
+	// brute-force protection
+	Lockout *LockoutConfig `yaml:",omitempty" json:"lockout,omitempty"`
+	// reject the clients which have used up their quota
+	Quota string `yaml:",omitempty" json:"quota,omitempty"`
+}
+
+type CertAutherConfig struct {
+	// certificate field used as the client ID: cn (default), san, spiffe
+	Source string `yaml:",omitempty" json:"source,omitempty"`
+	// allowed client IDs, wildcard is supported
+	Allows []string `yaml:",omitempty" json:"allows,omitempty"`
+}
+
+type JWTConfig struct {
+	// shared secret for HS256
+	Secret string `yaml:",omitempty" json:"secret,omitempty"`
+	// PEM encoded public keys or certificates for RS256 and ES256, file path or inline content
+	Keys []string `yaml:",omitempty" json:"keys,omitempty"`
+	// JWKS document loaded by HTTP
+	JWKS     *HTTPLoader `yaml:"jwks,omitempty" json:"jwks,omitempty"`
+	Issuer   string      `yaml:",omitempty" json:"issuer,omitempty"`
+	Audience []string    `yaml:",omitempty" json:"audience,omitempty"`
+	// claim used as the client ID, default is sub
+	ClientID string `yaml:"clientID,omitempty" json:"clientID,omitempty"`
+	// allowed clock skew for exp and nbf
+	Leeway time.Duration `yaml:",omitempty" json:"leeway,omitempty"`
+	// accept the tokens without the exp claim, by default the exp claim is required
+	AllowNoExp bool `yaml:"allowNoExp,omitempty" json:"allowNoExp,omitempty"`
+}
+
+type LockoutConfig struct {
+	// number of failures before the backoff applies
+	Threshold int `yaml:",omitempty" json:"threshold,omitempty"`
+	// failures are forgotten if no further failure occurs within the window
+	Window time.Duration `yaml:",omitempty" json:"window,omitempty"`
+	// initial backoff duration, doubled on each further failure
+	Backoff    time.Duration `yaml:",omitempty" json:"backoff,omitempty"`
+	MaxBackoff time.Duration `yaml:"maxBackoff,omitempty" json:"maxBackoff,omitempty"`
+	// number of failures to ban the client IP or username, negative value disables the ban
+	BanThreshold int           `yaml:"banThreshold,omitempty" json:"banThreshold,omitempty"`
+	BanDuration  time.Duration `yaml:"banDuration,omitempty" json:"banDuration,omitempty"`
+	// name of the admission registered for rejecting the blocked IPs
+	Admission string `yaml:",omitempty" json:"admission,omitempty"`
+	// name of the recorder for the lockout events
+	Recorder string `yaml:",omitempty" json:"recorder,omitempty"`
 }
 
 type AuthConfig struct {
@@ -154,6 +202,20 @@ type SelectorConfig struct {
 	FailTimeout time.Duration `yaml:"failTimeout" json:"failTimeout"`
 }
 
+type HealthCheckConfig struct {
+	// probe type: tcp, tls, http, chain
+	Type     string        `json:"type"`
+	Interval time.Duration `yaml:",omitempty" json:"interval,omitempty"`
+	Timeout  time.Duration `yaml:",omitempty" json:"timeout,omitempty"`
+	// number of consecutive successful probes to mark a node as healthy
+	Rise int `yaml:",omitempty" json:"rise,omitempty"`
+	// number of consecutive failed probes to mark a node as unhealthy
+	Fall int    `yaml:",omitempty" json:"fall,omitempty"`
+	URL  string `yaml:",omitempty" json:"url,omitempty"`
+	// chain used to reach the nodes for the chain probe
+	Chain string `yaml:",omitempty" json:"chain,omitempty"`
+}
+
 type AdmissionConfig struct {
 	Name string `json:"name"`
 	// Deprecated: use whitelist instead
@@ -248,10 +310,82 @@ type IngressConfig struct {
 }
 
 type SDConfig struct {
-	Name   string        `json:"name"`
+	Name string `json:"name"`
+	// lifetime of the services without renewal in the built-in backends,
+	// it should be larger than the renewal interval of the handlers.
+	TTL    time.Duration `yaml:",omitempty" json:"ttl,omitempty"`
+	File   *FileSD       `yaml:",omitempty" json:"file,omitempty"`
+	Redis  *RedisSD      `yaml:",omitempty" json:"redis,omitempty"`
+	Gossip *GossipSD     `yaml:",omitempty" json:"gossip,omitempty"`
 	Plugin *PluginConfig `yaml:",omitempty" json:"plugin,omitempty"`
 }
 
+// FileSD stores the services in a file shared by the nodes on the same host.
+type FileSD struct {
+	Path string `json:"path"`
+}
+
+// RedisSD stores the services in redis hashes, the key is the prefix of the redis keys.
+type RedisSD struct {
+	Addr     string `json:"addr"`
+	DB       int    `yaml:",omitempty" json:"db,omitempty"`
+	Username string `yaml:",omitempty" json:"username,omitempty"`
+	Password string `yaml:",omitempty" json:"password,omitempty"`
+	Key      string `yaml:",omitempty" json:"key,omitempty"`
+}
+
+// GossipSD replicates the services among a mesh of nodes.
+type GossipSD struct {
+	// address to listen on for the peers.
+	Addr string `json:"addr"`
+	// seed peers to join the mesh.
+	Peers    []string      `yaml:",omitempty" json:"peers,omitempty"`
+	Interval time.Duration `yaml:",omitempty" json:"interval,omitempty"`
+	// shared secret of the peers, the services from any host are accepted without it.
+	Secret string `yaml:",omitempty" json:"secret,omitempty"`
+}
+
+type QuotaConfig struct {
+	Name string `json:"name"`
+	// the default allowances of the clients.
+	Limits []*QuotaLimitConfig `yaml:",omitempty" json:"limits,omitempty"`
+	// the allowances of the specific clients, which override the default ones.
+	Clients []*QuotaClientConfig `yaml:",omitempty" json:"clients,omitempty"`
+	// the floor rate of the over-quota clients per second, such as 64KB.
+	Rate string `yaml:",omitempty" json:"rate,omitempty"`
+	// interval to persist the usage to the store.
+	Sync  time.Duration `yaml:",omitempty" json:"sync,omitempty"`
+	File  *FileQuota    `yaml:",omitempty" json:"file,omitempty"`
+	Redis *RedisQuota   `yaml:",omitempty" json:"redis,omitempty"`
+}
+
+type QuotaLimitConfig struct {
+	// daily, monthly or total (default).
+	Period string `yaml:",omitempty" json:"period,omitempty"`
+	// traffic allowance, such as 10GB.
+	Bytes    string        `yaml:",omitempty" json:"bytes,omitempty"`
+	ConnTime time.Duration `yaml:"connTime,omitempty" json:"connTime,omitempty"`
+}
+
+type QuotaClientConfig struct {
+	Client string              `json:"client"`
+	Limits []*QuotaLimitConfig `yaml:",omitempty" json:"limits,omitempty"`
+}
+
+// FileQuota persists the usage in a JSON file.
+type FileQuota struct {
+	Path string `json:"path"`
+}
+
+// RedisQuota persists the usage in redis hashes, the key is the prefix of the redis keys.
+type RedisQuota struct {
+	Addr     string `json:"addr"`
+	DB       int    `yaml:",omitempty" json:"db,omitempty"`
+	Username string `yaml:",omitempty" json:"username,omitempty"`
+	Password string `yaml:",omitempty" json:"password,omitempty"`
+	Key      string `yaml:",omitempty" json:"key,omitempty"`
+}
+
 type RouterRouteConfig struct {
 	// Deprecated: use dst instead
 	Net     string `yaml:",omitempty" json:"net,omitempty"`
@@ -270,12 +404,62 @@ type RouterConfig struct {
 }
 
 type RecorderConfig struct {
-	Name   string         `json:"name"`
-	File   *FileRecorder  `yaml:",omitempty" json:"file,omitempty"`
-	TCP    *TCPRecorder   `yaml:"tcp,omitempty" json:"tcp,omitempty"`
-	HTTP   *HTTPRecorder  `yaml:"http,omitempty" json:"http,omitempty"`
-	Redis  *RedisRecorder `yaml:",omitempty" json:"redis,omitempty"`
-	Plugin *PluginConfig  `yaml:",omitempty" json:"plugin,omitempty"`
+	Name   string          `json:"name"`
+	File   *FileRecorder   `yaml:",omitempty" json:"file,omitempty"`
+	TCP    *TCPRecorder    `yaml:"tcp,omitempty" json:"tcp,omitempty"`
+	HTTP   *HTTPRecorder   `yaml:"http,omitempty" json:"http,omitempty"`
+	Redis  *RedisRecorder  `yaml:",omitempty" json:"redis,omitempty"`
+	Syslog *SyslogRecorder `yaml:",omitempty" json:"syslog,omitempty"`
+	Kafka  *KafkaRecorder  `yaml:",omitempty" json:"kafka,omitempty"`
+	Plugin *PluginConfig   `yaml:",omitempty" json:"plugin,omitempty"`
+	// output format: json (default), ndjson, logfmt, cef, leef, har.
+	Format  *RecorderFormatConfig `yaml:",omitempty" json:"format,omitempty"`
+	Buffer  *RecorderBufferConfig `yaml:",omitempty" json:"buffer,omitempty"`
+	Capture *HTTPCaptureConfig    `yaml:",omitempty" json:"capture,omitempty"`
+}
+
+type RecorderFormatConfig struct {
+	Type string `json:"type"`
+	// device vendor, product and version of the CEF and LEEF formats.
+	Vendor  string `yaml:",omitempty" json:"vendor,omitempty"`
+	Product string `yaml:",omitempty" json:"product,omitempty"`
+	Version string `yaml:",omitempty" json:"version,omitempty"`
+}
+
+type RecorderBufferConfig struct {
+	// maximum number of the pending records.
+	QueueSize int `yaml:"queueSize,omitempty" json:"queueSize,omitempty"`
+	// maximum number of records written at once.
+	BatchSize     int           `yaml:"batchSize,omitempty" json:"batchSize,omitempty"`
+	FlushInterval time.Duration `yaml:"flushInterval,omitempty" json:"flushInterval,omitempty"`
+	// behavior when the queue is full: drop (default) or block.
+	Policy string `yaml:",omitempty" json:"policy,omitempty"`
+}
+
+// HTTPCaptureConfig is the capture policy of the HTTP request and response bodies.
+type HTTPCaptureConfig struct {
+	Rules       []*HTTPCaptureRuleConfig `yaml:",omitempty" json:"rules,omitempty"`
+	MaxBodySize int                      `yaml:"maxBodySize,omitempty" json:"maxBodySize,omitempty"`
+	// fraction of the requests whose bodies are captured, in range (0, 1].
+	SampleRate float64                  `yaml:"sampleRate,omitempty" json:"sampleRate,omitempty"`
+	Redact     *HTTPCaptureRedactConfig `yaml:",omitempty" json:"redact,omitempty"`
+}
+
+type HTTPCaptureRuleConfig struct {
+	// glob patterns, the empty pattern matches any value.
+	Host        string `yaml:",omitempty" json:"host,omitempty"`
+	Path        string `yaml:",omitempty" json:"path,omitempty"`
+	ContentType string `yaml:"contentType,omitempty" json:"contentType,omitempty"`
+	// skip the body capture of the matched messages.
+	Skip        bool `yaml:",omitempty" json:"skip,omitempty"`
+	MaxBodySize int  `yaml:"maxBodySize,omitempty" json:"maxBodySize,omitempty"`
+}
+
+type HTTPCaptureRedactConfig struct {
+	// headers to redact, Authorization, Proxy-Authorization, Cookie and Set-Cookie by default.
+	Headers []string `yaml:",omitempty" json:"headers,omitempty"`
+	// JSONPath expressions of the body fields to redact, such as $.password.
+	JSONPaths []string `yaml:"jsonPaths,omitempty" json:"jsonPaths,omitempty"`
 }
 
 type FileRecorder struct {
@@ -293,6 +477,8 @@ type HTTPRecorder struct {
 	URL     string            `yaml:"url" json:"url"`
 	Timeout time.Duration     `yaml:",omitempty" json:"timeout,omitempty"`
 	Header  map[string]string `yaml:",omitempty" json:"header,omitempty"`
+	// compress the request body with gzip.
+	Gzip bool `yaml:",omitempty" json:"gzip,omitempty"`
 }
 
 type RedisRecorder struct {
@@ -304,6 +490,35 @@ type RedisRecorder struct {
 	Type     string `yaml:",omitempty" json:"type,omitempty"`
 }
 
+// SyslogRecorder sends the records to syslog server in RFC 5424 format.
+// The app name and message ID are templates of the record fields, such as gost-{service} and {clientID}.
+type SyslogRecorder struct {
+	Addr string `json:"addr"`
+	// transport: udp (default), tcp, tls.
+	Network string `yaml:",omitempty" json:"network,omitempty"`
+	// facility name: kern, user, daemon, auth, local0 (default) - local7, etc.
+	Facility string        `yaml:",omitempty" json:"facility,omitempty"`
+	Hostname string        `yaml:",omitempty" json:"hostname,omitempty"`
+	AppName  string        `yaml:"appName,omitempty" json:"appName,omitempty"`
+	MsgID    string        `yaml:"msgID,omitempty" json:"msgID,omitempty"`
+	Timeout  time.Duration `yaml:",omitempty" json:"timeout,omitempty"`
+	TLS      *TLSConfig    `yaml:",omitempty" json:"tls,omitempty"`
+}
+
+// KafkaRecorder produces the records to Kafka topic.
+// The topic and key are templates of the record fields, such as gost-{service} and {clientID}.
+type KafkaRecorder struct {
+	Brokers  []string `json:"brokers"`
+	Topic    string   `json:"topic"`
+	Key      string   `yaml:",omitempty" json:"key,omitempty"`
+	ClientID string   `yaml:"clientID,omitempty" json:"clientID,omitempty"`
+	// required acks: 1 (default) for leader only, -1 for all in-sync replicas, 0 for no acknowledgement.
+	// A failed batch is resent, the records may be duplicated (at-least-once).
+	Acks    *int          `yaml:",omitempty" json:"acks,omitempty"`
+	Timeout time.Duration `yaml:",omitempty" json:"timeout,omitempty"`
+	TLS     *TLSConfig    `yaml:",omitempty" json:"tls,omitempty"`
+}
+
 type RecorderObject struct {
 	Name     string         `json:"name"`
 	Record   string         `json:"record"`
@@ -318,11 +533,39 @@ type LimiterConfig struct {
 	Redis  *RedisLoader  `yaml:",omitempty" json:"redis,omitempty"`
 	HTTP   *HTTPLoader   `yaml:"http,omitempty" json:"http,omitempty"`
 	Plugin *PluginConfig `yaml:",omitempty" json:"plugin,omitempty"`
+	// throttle the clients which have used up their quota to the rate of the quota,
+	// only for the traffic limiter.
+	Quota string `yaml:",omitempty" json:"quota,omitempty"`
 }
 
+// ObserverConfig writes the stats to one of the metrics, file, redis and plugin backends.
 type ObserverConfig struct {
-	Name   string        `json:"name"`
-	Plugin *PluginConfig `yaml:",omitempty" json:"plugin,omitempty"`
+	Name string `json:"name"`
+	// the traffic in the stats events is the increment since the last observation,
+	// it should match the observer.resetTraffic option of the handlers.
+	ResetTraffic bool `yaml:"resetTraffic,omitempty" json:"resetTraffic,omitempty"`
+	// export the per-client traffic counters and connection gauges on the metrics endpoint.
+	Metrics bool           `yaml:",omitempty" json:"metrics,omitempty"`
+	File    *FileObserver  `yaml:",omitempty" json:"file,omitempty"`
+	Redis   *RedisObserver `yaml:",omitempty" json:"redis,omitempty"`
+	Plugin  *PluginConfig  `yaml:",omitempty" json:"plugin,omitempty"`
+	// account the traffic and connection time of the clients to the quota.
+	Quota string `yaml:",omitempty" json:"quota,omitempty"`
+}
+
+// FileObserver writes the stats of each observe period to a rolling JSON lines file.
+type FileObserver struct {
+	Path     string             `json:"path"`
+	Rotation *LogRotationConfig `yaml:",omitempty" json:"rotation,omitempty"`
+}
+
+// RedisObserver accumulates the stats of the clients in redis hashes <key>:<client>.
+type RedisObserver struct {
+	Addr     string `json:"addr"`
+	DB       int    `yaml:",omitempty" json:"db,omitempty"`
+	Username string `yaml:",omitempty" json:"username,omitempty"`
+	Password string `yaml:",omitempty" json:"password,omitempty"`
+	Key      string `yaml:",omitempty" json:"key,omitempty"`
 }
 
 type ListenerConfig struct {
@@ -511,19 +754,20 @@ type HopConfig struct {
 	// Deprecated: use metadata.interface instead
 	Interface string `yaml:",omitempty" json:"interface,omitempty"`
 	// Deprecated: use metadata.so_mark instead
-	SockOpts *SockOptsConfig `yaml:"sockopts,omitempty" json:"sockopts,omitempty"`
-	Selector *SelectorConfig `yaml:",omitempty" json:"selector,omitempty"`
-	Bypass   string          `yaml:",omitempty" json:"bypass,omitempty"`
-	Bypasses []string        `yaml:",omitempty" json:"bypasses,omitempty"`
-	Resolver string          `yaml:",omitempty" json:"resolver,omitempty"`
-	Hosts    string          `yaml:",omitempty" json:"hosts,omitempty"`
-	Nodes    []*NodeConfig   `yaml:",omitempty" json:"nodes,omitempty"`
-	Reload   time.Duration   `yaml:",omitempty" json:"reload,omitempty"`
-	File     *FileLoader     `yaml:",omitempty" json:"file,omitempty"`
-	Redis    *RedisLoader    `yaml:",omitempty" json:"redis,omitempty"`
-	HTTP     *HTTPLoader     `yaml:"http,omitempty" json:"http,omitempty"`
-	Plugin   *PluginConfig   `yaml:",omitempty" json:"plugin,omitempty"`
-	Metadata map[string]any  `yaml:",omitempty" json:"metadata,omitempty"`
+	SockOpts    *SockOptsConfig    `yaml:"sockopts,omitempty" json:"sockopts,omitempty"`
+	Selector    *SelectorConfig    `yaml:",omitempty" json:"selector,omitempty"`
+	HealthCheck *HealthCheckConfig `yaml:"healthCheck,omitempty" json:"healthCheck,omitempty"`
+	Bypass      string             `yaml:",omitempty" json:"bypass,omitempty"`
+	Bypasses    []string           `yaml:",omitempty" json:"bypasses,omitempty"`
+	Resolver    string             `yaml:",omitempty" json:"resolver,omitempty"`
+	Hosts       string             `yaml:",omitempty" json:"hosts,omitempty"`
+	Nodes       []*NodeConfig      `yaml:",omitempty" json:"nodes,omitempty"`
+	Reload      time.Duration      `yaml:",omitempty" json:"reload,omitempty"`
+	File        *FileLoader        `yaml:",omitempty" json:"file,omitempty"`
+	Redis       *RedisLoader       `yaml:",omitempty" json:"redis,omitempty"`
+	HTTP        *HTTPLoader        `yaml:"http,omitempty" json:"http,omitempty"`
+	Plugin      *PluginConfig      `yaml:",omitempty" json:"plugin,omitempty"`
+	Metadata    map[string]any     `yaml:",omitempty" json:"metadata,omitempty"`
 }
 
 type NodeConfig struct {
@@ -567,6 +811,7 @@ type Config struct {
 	CLimiters  []*LimiterConfig   `yaml:"climiters,omitempty" json:"climiters,omitempty"`
 	RLimiters  []*LimiterConfig   `yaml:"rlimiters,omitempty" json:"rlimiters,omitempty"`
 	Observers  []*ObserverConfig  `yaml:",omitempty" json:"observers,omitempty"`
+	Quotas     []*QuotaConfig     `yaml:",omitempty" json:"quotas,omitempty"`
 	Loggers    []*LoggerConfig    `yaml:",omitempty" json:"loggers,omitempty"`
 	TLS        *TLSConfig         `yaml:",omitempty" json:"tls,omitempty"`
 	Log        *LogConfig         `yaml:",omitempty" json:"log,omitempty"`
@@ -0,0 +1,585 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-gost/core/auth"
+	"github.com/go-gost/core/logger"
+	reg "github.com/go-gost/core/registry"
+	"github.com/go-gost/x/config"
+	admission_parser "github.com/go-gost/x/config/parsing/admission"
+	auth_parser "github.com/go-gost/x/config/parsing/auth"
+	bypass_parser "github.com/go-gost/x/config/parsing/bypass"
+	chain_parser "github.com/go-gost/x/config/parsing/chain"
+	hop_parser "github.com/go-gost/x/config/parsing/hop"
+	hosts_parser "github.com/go-gost/x/config/parsing/hosts"
+	ingress_parser "github.com/go-gost/x/config/parsing/ingress"
+	limiter_parser "github.com/go-gost/x/config/parsing/limiter"
+	logger_parser "github.com/go-gost/x/config/parsing/logger"
+	observer_parser "github.com/go-gost/x/config/parsing/observer"
+	quota_parser "github.com/go-gost/x/config/parsing/quota"
+	recorder_parser "github.com/go-gost/x/config/parsing/recorder"
+	resolver_parser "github.com/go-gost/x/config/parsing/resolver"
+	router_parser "github.com/go-gost/x/config/parsing/router"
+	sd_parser "github.com/go-gost/x/config/parsing/sd"
+	service_parser "github.com/go-gost/x/config/parsing/service"
+	"github.com/go-gost/x/config/validate"
+	"github.com/go-gost/x/registry"
+)
+
+const (
+	KindLogger    = "logger"
+	KindQuota     = "quota"
+	KindAuther    = "auther"
+	KindAdmission = "admission"
+	KindBypass    = "bypass"
+	KindResolver  = "resolver"
+	KindHosts     = "hosts"
+	KindIngress   = "ingress"
+	KindRouter    = "router"
+	KindSD        = "sd"
+	KindObserver  = "observer"
+	KindRecorder  = "recorder"
+	KindLimiter   = "limiter"
+	KindCLimiter  = "climiter"
+	KindRLimiter  = "rlimiter"
+	KindHop       = "hop"
+	KindChain     = "chain"
+	KindService   = "service"
+)
+
+// Object identifies a named object in config.
+type Object struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// Diff is the difference of the named objects between two configs.
+type Diff struct {
+	Added   []Object `json:"added"`
+	Updated []Object `json:"updated"`
+	Removed []Object `json:"removed"`
+}
+
+// Empty reports whether there is no difference.
+func (d *Diff) Empty() bool {
+	return d == nil || len(d.Added)+len(d.Updated)+len(d.Removed) == 0
+}
+
+func (d *Diff) reverse() *Diff {
+	return &Diff{
+		Added:   d.Removed,
+		Updated: d.Updated,
+		Removed: d.Added,
+	}
+}
+
+func (d *Diff) names(kind string, objects []Object) (names []string) {
+	for _, o := range objects {
+		if o.Kind == kind {
+			names = append(names, o.Name)
+		}
+	}
+	return
+}
+
+// kind describes how the objects of a config section are compared, built and registered.
+type kind struct {
+	name string
+	// names returns the names of the objects in order, the duplicates are kept.
+	names func(c *config.Config) []string
+	items func(c *config.Config) map[string]any
+	merge func(dst, src *config.Config)
+	// copy replaces the objects in dst with the objects in src.
+	copy  func(dst, src *config.Config)
+	build func(c *config.Config, name string) (any, error)
+	// register registers the built object v, the previous object with the same name is replaced.
+	register func(c *config.Config, name string, v any) error
+	// unregister unregisters and closes the object.
+	unregister func(c *config.Config, name string)
+}
+
+func newKind[T any, V any](kindName string,
+	list func(c *config.Config) *[]*T, nameOf func(*T) string,
+	r reg.Registry[V], parse func(*T) (V, error)) *kind {
+
+	find := func(c *config.Config, name string) *T {
+		for _, v := range *list(c) {
+			if v != nil && nameOf(v) == name {
+				return v
+			}
+		}
+		return nil
+	}
+
+	return &kind{
+		name: kindName,
+		names: func(c *config.Config) (names []string) {
+			for _, v := range *list(c) {
+				if v != nil && nameOf(v) != "" {
+					names = append(names, nameOf(v))
+				}
+			}
+			return
+		},
+		items: func(c *config.Config) map[string]any {
+			m := make(map[string]any)
+			for _, v := range *list(c) {
+				if v != nil && nameOf(v) != "" {
+					m[nameOf(v)] = v
+				}
+			}
+			return m
+		},
+		copy: func(dst, src *config.Config) {
+			*list(dst) = *list(src)
+		},
+		merge: func(dst, src *config.Config) {
+			objects := append([]*T{}, *list(dst)...)
+		next:
+			for _, v := range *list(src) {
+				if v == nil {
+					continue
+				}
+				for i := range objects {
+					if objects[i] != nil && nameOf(objects[i]) == nameOf(v) {
+						objects[i] = v
+						continue next
+					}
+				}
+				objects = append(objects, v)
+			}
+			*list(dst) = objects
+		},
+		build: func(c *config.Config, name string) (any, error) {
+			cfg := find(c, name)
+			if cfg == nil {
+				return nil, fmt.Errorf("%s %s not found", kindName, name)
+			}
+			return parse(cfg)
+		},
+		register: func(c *config.Config, name string, v any) error {
+			t, _ := v.(V)
+			r.Unregister(name)
+			return r.Register(name, t)
+		},
+		unregister: func(c *config.Config, name string) {
+			r.Unregister(name)
+		},
+	}
+}
+
+// kinds are in the order of dependency, same as the order of registering in Load.
+var kinds = []*kind{
+	newKind(KindLogger, func(c *config.Config) *[]*config.LoggerConfig { return &c.Loggers },
+		func(v *config.LoggerConfig) string { return v.Name }, registry.LoggerRegistry(),
+		func(v *config.LoggerConfig) (logger.Logger, error) { return logger_parser.ParseLogger(v), nil }),
+	newKind(KindQuota, func(c *config.Config) *[]*config.QuotaConfig { return &c.Quotas },
+		func(v *config.QuotaConfig) string { return v.Name }, registry.QuotaRegistry(), quota_parser.ParseQuota),
+	autherKind(),
+	newKind(KindAdmission, func(c *config.Config) *[]*config.AdmissionConfig { return &c.Admissions },
+		func(v *config.AdmissionConfig) string { return v.Name }, registry.AdmissionRegistry(), noErr(admission_parser.ParseAdmission)),
+	newKind(KindBypass, func(c *config.Config) *[]*config.BypassConfig { return &c.Bypasses },
+		func(v *config.BypassConfig) string { return v.Name }, registry.BypassRegistry(), noErr(bypass_parser.ParseBypass)),
+	newKind(KindResolver, func(c *config.Config) *[]*config.ResolverConfig { return &c.Resolvers },
+		func(v *config.ResolverConfig) string { return v.Name }, registry.ResolverRegistry(), resolver_parser.ParseResolver),
+	newKind(KindHosts, func(c *config.Config) *[]*config.HostsConfig { return &c.Hosts },
+		func(v *config.HostsConfig) string { return v.Name }, registry.HostsRegistry(), noErr(hosts_parser.ParseHostMapper)),
+	newKind(KindIngress, func(c *config.Config) *[]*config.IngressConfig { return &c.Ingresses },
+		func(v *config.IngressConfig) string { return v.Name }, registry.IngressRegistry(), noErr(ingress_parser.ParseIngress)),
+	newKind(KindRouter, func(c *config.Config) *[]*config.RouterConfig { return &c.Routers },
+		func(v *config.RouterConfig) string { return v.Name }, registry.RouterRegistry(), noErr(router_parser.ParseRouter)),
+	newKind(KindSD, func(c *config.Config) *[]*config.SDConfig { return &c.SDs },
+		func(v *config.SDConfig) string { return v.Name }, registry.SDRegistry(), noErr(sd_parser.ParseSD)),
+	newKind(KindObserver, func(c *config.Config) *[]*config.ObserverConfig { return &c.Observers },
+		func(v *config.ObserverConfig) string { return v.Name }, registry.ObserverRegistry(), observer_parser.ParseObserver),
+	newKind(KindRecorder, func(c *config.Config) *[]*config.RecorderConfig { return &c.Recorders },
+		func(v *config.RecorderConfig) string { return v.Name }, registry.RecorderRegistry(), noErr(recorder_parser.ParseRecorder)),
+	newKind(KindLimiter, func(c *config.Config) *[]*config.LimiterConfig { return &c.Limiters },
+		func(v *config.LimiterConfig) string { return v.Name }, registry.TrafficLimiterRegistry(), noErr(limiter_parser.ParseTrafficLimiter)),
+	newKind(KindCLimiter, func(c *config.Config) *[]*config.LimiterConfig { return &c.CLimiters },
+		func(v *config.LimiterConfig) string { return v.Name }, registry.ConnLimiterRegistry(), noErr(limiter_parser.ParseConnLimiter)),
+	newKind(KindRLimiter, func(c *config.Config) *[]*config.LimiterConfig { return &c.RLimiters },
+		func(v *config.LimiterConfig) string { return v.Name }, registry.RateLimiterRegistry(), noErr(limiter_parser.ParseRateLimiter)),
+	newKind(KindHop, func(c *config.Config) *[]*config.HopConfig { return &c.Hops },
+		func(v *config.HopConfig) string { return v.Name }, registry.HopRegistry(), withLogger(hop_parser.ParseHop)),
+	newKind(KindChain, func(c *config.Config) *[]*config.ChainConfig { return &c.Chains },
+		func(v *config.ChainConfig) string { return v.Name }, registry.ChainRegistry(), withLogger(chain_parser.ParseChain)),
+	serviceKind(),
+}
+
+func noErr[T, V any](f func(T) V) func(T) (V, error) {
+	return func(v T) (V, error) {
+		return f(v), nil
+	}
+}
+
+func withLogger[T, V any](f func(T, logger.Logger) (V, error)) func(T) (V, error) {
+	return func(v T) (V, error) {
+		return f(v, logger.Default())
+	}
+}
+
+// autherKind also maintains the lockout admission of the auther.
+func autherKind() *kind {
+	k := newKind(KindAuther, func(c *config.Config) *[]*config.AutherConfig { return &c.Authers },
+		func(v *config.AutherConfig) string { return v.Name }, registry.AutherRegistry(), noErr(auth_parser.ParseAuther))
+
+	lockout := func(c *config.Config, name string) string {
+		for _, v := range c.Authers {
+			if v != nil && v.Name == name && v.Lockout != nil {
+				return v.Lockout.Admission
+			}
+		}
+		return ""
+	}
+
+	register, unregister := k.register, k.unregister
+	k.register = func(c *config.Config, name string, v any) error {
+		if err := register(c, name, v); err != nil {
+			return err
+		}
+		if adm := lockout(c, name); adm != "" {
+			registry.AdmissionRegistry().Unregister(adm)
+			auther, _ := v.(auth.Authenticator)
+			return registry.AdmissionRegistry().Register(adm, auth_parser.LockoutAdmission(auther))
+		}
+		return nil
+	}
+	k.unregister = func(c *config.Config, name string) {
+		if adm := lockout(c, name); adm != "" {
+			registry.AdmissionRegistry().Unregister(adm)
+		}
+		unregister(c, name)
+	}
+	return k
+}
+
+// serviceKind starts the service once it is registered.
+func serviceKind() *kind {
+	k := newKind(KindService, func(c *config.Config) *[]*config.ServiceConfig { return &c.Services },
+		func(v *config.ServiceConfig) string { return v.Name }, registry.ServiceRegistry(), service_parser.ParseService)
+
+	register := k.register
+	k.register = func(c *config.Config, name string, v any) error {
+		if err := register(c, name, v); err != nil {
+			return err
+		}
+		svc := registry.ServiceRegistry().Get(name)
+		go svc.Serve()
+		return nil
+	}
+	return k
+}
+
+const (
+	// ModeMerge adds the named objects of the config or replaces the ones with the same name, other objects are kept.
+	ModeMerge = "merge"
+	// ModeReplace applies the config as the full config, the objects not in the config are removed.
+	ModeReplace = "replace"
+)
+
+var (
+	// applyMux serializes the changes of the running objects and the global config.
+	applyMux sync.Mutex
+)
+
+// Exclusive runs f in the critical section of Apply,
+// the other changes of the running objects and the global config, such as reloading the config, should run in it.
+func Exclusive(f func()) {
+	applyMux.Lock()
+	defer applyMux.Unlock()
+
+	f()
+}
+
+// Merge returns a copy of the base config with the named objects in patch added or replaced.
+func Merge(base, patch *config.Config) *config.Config {
+	cfg := &config.Config{}
+	if base != nil {
+		*cfg = *base
+	}
+	if patch == nil {
+		return cfg
+	}
+	for _, k := range kinds {
+		k.merge(cfg, patch)
+	}
+	return cfg
+}
+
+// CopyObjects replaces the named objects in dst with the ones in src, the global settings of dst are kept.
+func CopyObjects(dst, src *config.Config) {
+	if dst == nil || src == nil {
+		return
+	}
+	for _, k := range kinds {
+		k.copy(dst, src)
+	}
+}
+
+// Compare returns the difference of the named objects between config from and to.
+func Compare(from, to *config.Config) *Diff {
+	if from == nil {
+		from = &config.Config{}
+	}
+	if to == nil {
+		to = &config.Config{}
+	}
+
+	diff := &Diff{}
+	for _, k := range kinds {
+		a, b := k.items(from), k.items(to)
+		for _, name := range sortedNames(b) {
+			v, ok := a[name]
+			if !ok {
+				diff.Added = append(diff.Added, Object{Kind: k.name, Name: name})
+				continue
+			}
+			if !equal(k.name, v, b[name]) {
+				diff.Updated = append(diff.Updated, Object{Kind: k.name, Name: name})
+			}
+		}
+		for _, name := range sortedNames(a) {
+			if _, ok := b[name]; !ok {
+				diff.Removed = append(diff.Removed, Object{Kind: k.name, Name: name})
+			}
+		}
+	}
+	return diff
+}
+
+// Plan compares the config from and to, and validates the config to without changing anything.
+func Plan(from, to *config.Config) (*Diff, error) {
+	diff := Compare(from, to)
+	if err := validate.Validate(to).Err(); err != nil {
+		return diff, err
+	}
+	return diff, nil
+}
+
+// Apply applies the config c to the running objects and the global config in mode, the default mode is merge.
+// In the replace mode only the named objects are applied, the global settings are kept.
+// If dryRun is true, the config is only validated and the changes are reported.
+//
+// The global config is read, changed and updated in one critical section,
+// so the concurrent applies are serialized.
+func Apply(c *config.Config, mode string, dryRun bool) (*Diff, error) {
+	applyMux.Lock()
+	defer applyMux.Unlock()
+
+	from := config.Global()
+
+	var to *config.Config
+	switch mode {
+	case ModeReplace:
+		to = &config.Config{}
+		if c != nil {
+			*to = *c
+		}
+		to.TLS, to.Log, to.Profiling, to.API, to.Metrics = from.TLS, from.Log, from.Profiling, from.API, from.Metrics
+	case "", ModeMerge:
+		to = Merge(from, c)
+	default:
+		return nil, fmt.Errorf("invalid mode %s", mode)
+	}
+
+	if dryRun {
+		return Plan(from, to)
+	}
+
+	diff, err := apply(from, to)
+	if err != nil {
+		return diff, err
+	}
+
+	config.OnUpdate(func(c *config.Config) error {
+		CopyObjects(c, to)
+		return nil
+	})
+	return diff, nil
+}
+
+// apply changes the running objects from config from to config to.
+//
+// All the added and updated objects except services are built before any registry is changed,
+// a build error leaves the running objects untouched.
+// The registries are then swapped in the order of dependency,
+// and the changed services are restarted last as they hold the listening ports.
+// If a service can not be started, all the changes are rolled back to config from.
+func apply(from, to *config.Config) (*Diff, error) {
+	diff, err := Plan(from, to)
+	if err != nil || diff.Empty() {
+		return diff, err
+	}
+
+	// build
+	type object struct {
+		k    *kind
+		name string
+		v    any
+	}
+	var objects []object
+	for _, k := range kinds {
+		if k.name == KindService {
+			continue
+		}
+		for _, name := range append(diff.names(k.name, diff.Added), diff.names(k.name, diff.Updated)...) {
+			v, err := k.build(to, name)
+			if err != nil {
+				for _, o := range objects {
+					closeObject(o.v)
+				}
+				return diff, fmt.Errorf("%s %s: %w", k.name, name, err)
+			}
+			objects = append(objects, object{k: k, name: name, v: v})
+		}
+	}
+
+	// swap
+	for _, k := range kinds {
+		if k.name == KindService {
+			continue
+		}
+		for _, name := range diff.names(k.name, diff.Removed) {
+			k.unregister(from, name)
+		}
+	}
+	var errs []error
+	for _, o := range objects {
+		if err := o.k.register(to, o.name, o.v); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", o.k.name, o.name, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return diff, rollback(diff, from, to, err)
+	}
+	if err := applyServices(diff, from, to); err != nil {
+		return diff, rollback(diff, from, to, err)
+	}
+
+	return diff, nil
+}
+
+func applyServices(diff *Diff, from, to *config.Config) error {
+	k := kinds[len(kinds)-1]
+
+	// stop the services first to release the ports.
+	for _, name := range append(diff.names(k.name, diff.Removed), diff.names(k.name, diff.Updated)...) {
+		k.unregister(from, name)
+	}
+
+	var errs []error
+	for _, name := range append(diff.names(k.name, diff.Added), diff.names(k.name, diff.Updated)...) {
+		v, err := k.build(to, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", k.name, name, err))
+			continue
+		}
+		if v == nil {
+			continue
+		}
+		if err := k.register(to, name, v); err != nil {
+			closeObject(v)
+			errs = append(errs, fmt.Errorf("%s %s: %w", k.name, name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rollback rebuilds the changed objects from config from.
+func rollback(diff *Diff, from, to *config.Config, cause error) error {
+	rdiff := diff.reverse()
+
+	var errs []error
+	for _, k := range kinds {
+		if k.name == KindService {
+			continue
+		}
+		for _, name := range diff.names(k.name, diff.Added) {
+			k.unregister(to, name)
+		}
+		for _, name := range append(rdiff.names(k.name, rdiff.Added), rdiff.names(k.name, rdiff.Updated)...) {
+			v, err := k.build(from, name)
+			if err == nil {
+				err = k.register(from, name, v)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", k.name, name, err))
+			}
+		}
+	}
+	if err := applyServices(rdiff, to, from); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%w; rollback failed: %v", cause, err)
+	}
+	return fmt.Errorf("%w; rolled back", cause)
+}
+
+func closeObject(v any) {
+	if closer, ok := v.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+func equal(kind string, a, b any) bool {
+	if kind == KindService {
+		a, b = normalizeService(a.(*config.ServiceConfig)), normalizeService(b.(*config.ServiceConfig))
+	}
+	va, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	vb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(va, vb)
+}
+
+// normalizeService strips the runtime status and fills the default listener and handler types,
+// which are filled in by the parser for the running services.
+func normalizeService(cfg *config.ServiceConfig) *config.ServiceConfig {
+	c := *cfg
+	c.Status = nil
+
+	listener := config.ListenerConfig{}
+	if c.Listener != nil {
+		listener = *c.Listener
+	}
+	if strings.TrimSpace(listener.Type) == "" {
+		listener.Type = "tcp"
+	}
+	c.Listener = &listener
+
+	handler := config.HandlerConfig{}
+	if c.Handler != nil {
+		handler = *c.Handler
+	}
+	if strings.TrimSpace(handler.Type) == "" {
+		handler.Type = "auto"
+	}
+	c.Handler = &handler
+
+	return &c
+}
+
+func sortedNames(m map[string]any) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
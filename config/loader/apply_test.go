@@ -0,0 +1,127 @@
+package loader
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/config"
+	xlogger "github.com/go-gost/x/logger"
+	"github.com/go-gost/x/registry"
+)
+
+func TestCompare(t *testing.T) {
+	from := &config.Config{
+		Services: []*config.ServiceConfig{
+			{Name: "svc-0", Addr: ":8080", Handler: &config.HandlerConfig{Type: "http"}, Listener: &config.ListenerConfig{Type: "tcp"},
+				Status: &config.ServiceStatus{State: "running"}},
+			{Name: "svc-1", Addr: ":8081"},
+		},
+		Hops: []*config.HopConfig{
+			{Name: "hop-0", Nodes: []*config.NodeConfig{{Name: "node-0", Addr: ":1080"}}},
+		},
+	}
+	patch := &config.Config{
+		Services: []*config.ServiceConfig{
+			// same as the running one, the default listener type is filled by the parser.
+			{Name: "svc-0", Addr: ":8080", Handler: &config.HandlerConfig{Type: "http"}},
+			{Name: "svc-2", Addr: ":8082"},
+		},
+		Hops: []*config.HopConfig{
+			{Name: "hop-0", Nodes: []*config.NodeConfig{{Name: "node-0", Addr: ":1081"}}},
+		},
+	}
+
+	to := Merge(from, patch)
+	if n := len(to.Services); n != 3 {
+		t.Fatalf("%d services after merge, want 3", n)
+	}
+	if len(from.Services) != 2 || from.Hops[0].Nodes[0].Addr != ":1080" {
+		t.Fatal("base config is modified by merge")
+	}
+
+	diff := Compare(from, to)
+	want := &Diff{
+		Added:   []Object{{Kind: KindService, Name: "svc-2"}},
+		Updated: []Object{{Kind: KindHop, Name: "hop-0"}},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("got %+v, want %+v", diff, want)
+	}
+
+	diff = Compare(from, &config.Config{Services: from.Services[:1]})
+	want = &Diff{
+		Removed: []Object{{Kind: KindHop, Name: "hop-0"}, {Kind: KindService, Name: "svc-1"}},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("got %+v, want %+v", diff, want)
+	}
+}
+
+func TestCopyObjects(t *testing.T) {
+	// every object list of the config is covered by the kinds.
+	src := &config.Config{}
+	v := reflect.ValueOf(src).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Field(i); f.Kind() == reflect.Slice {
+			f.Set(reflect.MakeSlice(f.Type(), 1, 1))
+		}
+	}
+
+	dst := &config.Config{}
+	CopyObjects(dst, src)
+
+	d := reflect.ValueOf(dst).Elem()
+	for i := 0; i < d.NumField(); i++ {
+		if f := d.Field(i); f.Kind() == reflect.Slice && f.Len() != 1 {
+			t.Errorf("%s is not copied", d.Type().Field(i).Name)
+		}
+	}
+}
+
+func TestApplyConcurrent(t *testing.T) {
+	logger.SetDefault(xlogger.Nop())
+	config.Set(&config.Config{})
+
+	const n = 8
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := &config.Config{
+				Bypasses: []*config.BypassConfig{{Name: fmt.Sprintf("bypass-%d", i), Matchers: []string{"example.com"}}},
+			}
+			if _, err := Apply(c, ModeMerge, false); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	t.Cleanup(func() {
+		for i := 0; i < n; i++ {
+			registry.BypassRegistry().Unregister(fmt.Sprintf("bypass-%d", i))
+		}
+	})
+
+	// every merge is based on the global config updated by the previous one.
+	if m := len(config.Global().Bypasses); m != n {
+		t.Errorf("%d bypasses in the global config, want %d", m, n)
+	}
+	for i := 0; i < n; i++ {
+		if !registry.BypassRegistry().IsRegistered(fmt.Sprintf("bypass-%d", i)) {
+			t.Errorf("bypass-%d is not registered", i)
+		}
+	}
+
+	diff, err := Apply(&config.Config{}, ModeReplace, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Removed) != n || len(config.Global().Bypasses) != n {
+		t.Errorf("dry run: %d removed, %d bypasses in the global config", len(diff.Removed), len(config.Global().Bypasses))
+	}
+}
@@ -1,6 +1,7 @@
 package loader
 
 import (
+	"github.com/go-gost/core/admission"
 	"github.com/go-gost/core/logger"
 	"github.com/go-gost/x/config"
 	"github.com/go-gost/x/config/parsing"
@@ -14,6 +15,7 @@ import (
 	limiter_parser "github.com/go-gost/x/config/parsing/limiter"
 	logger_parser "github.com/go-gost/x/config/parsing/logger"
 	observer_parser "github.com/go-gost/x/config/parsing/observer"
+	quota_parser "github.com/go-gost/x/config/parsing/quota"
 	recorder_parser "github.com/go-gost/x/config/parsing/recorder"
 	resolver_parser "github.com/go-gost/x/config/parsing/resolver"
 	router_parser "github.com/go-gost/x/config/parsing/router"
@@ -66,13 +68,31 @@ func register(cfg *config.Config) error {
 		}
 	}
 
+	for name := range registry.QuotaRegistry().GetAll() {
+		registry.QuotaRegistry().Unregister(name)
+	}
+	for _, quotaCfg := range cfg.Quotas {
+		q, err := quota_parser.ParseQuota(quotaCfg)
+		if err != nil {
+			return err
+		}
+		if err := registry.QuotaRegistry().Register(quotaCfg.Name, q); err != nil {
+			return err
+		}
+	}
+
 	for name := range registry.AutherRegistry().GetAll() {
 		registry.AutherRegistry().Unregister(name)
 	}
+	lockouts := make(map[string]admission.Admission)
 	for _, autherCfg := range cfg.Authers {
-		if err := registry.AutherRegistry().Register(autherCfg.Name, auth_parser.ParseAuther(autherCfg)); err != nil {
+		auther := auth_parser.ParseAuther(autherCfg)
+		if err := registry.AutherRegistry().Register(autherCfg.Name, auther); err != nil {
 			return err
 		}
+		if lc := autherCfg.Lockout; lc != nil && lc.Admission != "" {
+			lockouts[lc.Admission] = auth_parser.LockoutAdmission(auther)
+		}
 	}
 
 	for name := range registry.AdmissionRegistry().GetAll() {
@@ -83,6 +103,11 @@ func register(cfg *config.Config) error {
 			return err
 		}
 	}
+	for name, adm := range lockouts {
+		if err := registry.AdmissionRegistry().Register(name, adm); err != nil {
+			return err
+		}
+	}
 
 	for name := range registry.BypassRegistry().GetAll() {
 		registry.BypassRegistry().Unregister(name)
@@ -146,7 +171,11 @@ func register(cfg *config.Config) error {
 		registry.ObserverRegistry().Unregister(name)
 	}
 	for _, observerCfg := range cfg.Observers {
-		if err := registry.ObserverRegistry().Register(observerCfg.Name, observer_parser.ParseObserver(observerCfg)); err != nil {
+		o, err := observer_parser.ParseObserver(observerCfg)
+		if err != nil {
+			return err
+		}
+		if err := registry.ObserverRegistry().Register(observerCfg.Name, o); err != nil {
 			return err
 		}
 	}
@@ -2,19 +2,25 @@ package auth
 
 import (
 	"bufio"
+	"crypto"
 	"crypto/tls"
 	"io"
 	"net/url"
 	"os"
 	"strings"
 
+	"github.com/go-gost/core/admission"
 	"github.com/go-gost/core/auth"
 	"github.com/go-gost/core/logger"
 	xauth "github.com/go-gost/x/auth"
+	"github.com/go-gost/x/auth/cert"
+	"github.com/go-gost/x/auth/jwt"
+	"github.com/go-gost/x/auth/lockout"
 	auth_plugin "github.com/go-gost/x/auth/plugin"
 	"github.com/go-gost/x/config"
 	"github.com/go-gost/x/internal/loader"
 	"github.com/go-gost/x/internal/plugin"
+	"github.com/go-gost/x/quota"
 	"github.com/go-gost/x/registry"
 )
 
@@ -23,6 +29,46 @@ func ParseAuther(cfg *config.AutherConfig) auth.Authenticator {
 		return nil
 	}
 
+	auther := parseAuther(cfg)
+	if cfg.Lockout != nil {
+		auther = lockout.WrapAuthenticator(auther, parseLockout(cfg))
+	}
+	if cfg.Quota != "" {
+		auther = quota.WrapAuthenticator(auther, registry.QuotaRegistry().Get(cfg.Quota))
+	}
+	return auther
+}
+
+// LockoutAdmission returns the admission of the lockout tracker of the auther,
+// nil is returned if the lockout is not enabled for the auther.
+func LockoutAdmission(auther auth.Authenticator) admission.Admission {
+	if v, ok := auther.(interface{ Tracker() *lockout.Tracker }); ok {
+		if t := v.Tracker(); t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+func parseLockout(cfg *config.AutherConfig) *lockout.Tracker {
+	lc := cfg.Lockout
+	return lockout.NewTracker(
+		lockout.NameOption(cfg.Name),
+		lockout.ThresholdOption(lc.Threshold),
+		lockout.WindowOption(lc.Window),
+		lockout.BackoffOption(lc.Backoff),
+		lockout.MaxBackoffOption(lc.MaxBackoff),
+		lockout.BanThresholdOption(lc.BanThreshold),
+		lockout.BanDurationOption(lc.BanDuration),
+		lockout.RecorderOption(registry.RecorderRegistry().Get(lc.Recorder)),
+		lockout.LoggerOption(logger.Default().WithFields(map[string]any{
+			"kind":   "auther",
+			"auther": cfg.Name,
+		})),
+	)
+}
+
+func parseAuther(cfg *config.AutherConfig) auth.Authenticator {
 	if cfg.Plugin != nil {
 		var tlsCfg *tls.Config
 		if cfg.Plugin.TLS != nil {
@@ -47,6 +93,13 @@ func ParseAuther(cfg *config.AutherConfig) auth.Authenticator {
 		}
 	}
 
+	if cfg.JWT != nil {
+		return parseJWT(cfg)
+	}
+	if cfg.Cert != nil {
+		return parseCert(cfg)
+	}
+
 	m := make(map[string]string)
 
 	for _, user := range cfg.Auths {
@@ -85,6 +138,84 @@ func ParseAuther(cfg *config.AutherConfig) auth.Authenticator {
 	return xauth.NewAuthenticator(opts...)
 }
 
+func parseCert(cfg *config.AutherConfig) auth.Authenticator {
+	opts := []cert.Option{
+		cert.SourceOption(cfg.Cert.Source),
+		cert.AllowsOption(cfg.Cert.Allows),
+		cert.ReloadPeriodOption(cfg.Reload),
+		cert.LoggerOption(logger.Default().WithFields(map[string]any{
+			"kind":   "auther",
+			"auther": cfg.Name,
+		})),
+	}
+	if cfg.File != nil && cfg.File.Path != "" {
+		opts = append(opts, cert.FileLoaderOption(loader.FileLoader(cfg.File.Path)))
+	}
+	if cfg.Redis != nil && cfg.Redis.Addr != "" {
+		opts = append(opts, cert.RedisLoaderOption(loader.RedisSetLoader(
+			cfg.Redis.Addr,
+			loader.DBRedisLoaderOption(cfg.Redis.DB),
+			loader.UsernameRedisLoaderOption(cfg.Redis.Username),
+			loader.PasswordRedisLoaderOption(cfg.Redis.Password),
+			loader.KeyRedisLoaderOption(cfg.Redis.Key),
+		)))
+	}
+	if cfg.HTTP != nil && cfg.HTTP.URL != "" {
+		opts = append(opts, cert.HTTPLoaderOption(loader.HTTPLoader(
+			cfg.HTTP.URL,
+			loader.TimeoutHTTPLoaderOption(cfg.HTTP.Timeout),
+		)))
+	}
+	return cert.NewAuthenticator(opts...)
+}
+
+func parseJWT(cfg *config.AutherConfig) auth.Authenticator {
+	log := logger.Default().WithFields(map[string]any{
+		"kind":   "auther",
+		"auther": cfg.Name,
+	})
+
+	var keys []crypto.PublicKey
+	for _, s := range cfg.JWT.Keys {
+		data := []byte(s)
+		if !strings.HasPrefix(strings.TrimSpace(s), "-----BEGIN") {
+			b, err := os.ReadFile(s)
+			if err != nil {
+				log.Error(err)
+				continue
+			}
+			data = b
+		}
+		key, err := jwt.ParsePublicKey(data)
+		if err != nil {
+			log.Errorf("%s: %v", s, err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	opts := []jwt.Option{
+		jwt.KeysOption(keys...),
+		jwt.IssuerOption(cfg.JWT.Issuer),
+		jwt.AudienceOption(cfg.JWT.Audience),
+		jwt.ClientIDClaimOption(cfg.JWT.ClientID),
+		jwt.LeewayOption(cfg.JWT.Leeway),
+		jwt.AllowNoExpOption(cfg.JWT.AllowNoExp),
+		jwt.ReloadPeriodOption(cfg.Reload),
+		jwt.LoggerOption(log),
+	}
+	if cfg.JWT.Secret != "" {
+		opts = append(opts, jwt.SecretOption([]byte(cfg.JWT.Secret)))
+	}
+	if cfg.JWT.JWKS != nil && cfg.JWT.JWKS.URL != "" {
+		opts = append(opts, jwt.JWKSLoaderOption(loader.HTTPLoader(
+			cfg.JWT.JWKS.URL,
+			loader.TimeoutHTTPLoaderOption(cfg.JWT.JWKS.Timeout),
+		)))
+	}
+	return jwt.NewAuthenticator(opts...)
+}
+
 func ParseAutherFromAuth(au *config.AuthConfig) auth.Authenticator {
 	if au == nil || au.Username == "" {
 		return nil
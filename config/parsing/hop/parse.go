@@ -19,6 +19,7 @@ import (
 	"github.com/go-gost/x/internal/plugin"
 	"github.com/go-gost/x/metadata"
 	mdutil "github.com/go-gost/x/metadata/util"
+	"github.com/go-gost/x/registry"
 )
 
 func ParseHop(cfg *config.HopConfig, log logger.Logger) (hop.Hop, error) {
@@ -165,6 +166,22 @@ func ParseHop(cfg *config.HopConfig, log logger.Logger) (hop.Hop, error) {
 		})),
 	}
 
+	if hc := cfg.HealthCheck; hc != nil {
+		var c chain.Chainer
+		if hc.Chain != "" {
+			c = registry.ChainRegistry().Get(hc.Chain)
+		}
+		opts = append(opts, xhop.HealthCheckOption(&xhop.HealthCheck{
+			Type:     strings.ToLower(hc.Type),
+			Interval: hc.Interval,
+			Timeout:  hc.Timeout,
+			Rise:     hc.Rise,
+			Fall:     hc.Fall,
+			URL:      hc.URL,
+			Chain:    c,
+		}))
+	}
+
 	if cfg.File != nil && cfg.File.Path != "" {
 		opts = append(opts, xhop.FileLoaderOption(loader.FileLoader(cfg.File.Path)))
 	}
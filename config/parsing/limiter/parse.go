@@ -15,13 +15,24 @@ import (
 	xrate "github.com/go-gost/x/limiter/rate"
 	xtraffic "github.com/go-gost/x/limiter/traffic"
 	traffic_plugin "github.com/go-gost/x/limiter/traffic/plugin"
+	"github.com/go-gost/x/quota"
+	"github.com/go-gost/x/registry"
 )
 
-func ParseTrafficLimiter(cfg *config.LimiterConfig) (lim traffic.TrafficLimiter) {
+func ParseTrafficLimiter(cfg *config.LimiterConfig) traffic.TrafficLimiter {
 	if cfg == nil {
 		return nil
 	}
 
+	lim := parseTrafficLimiter(cfg)
+	if cfg.Quota != "" {
+		lim = quota.WrapTrafficLimiter(lim, registry.QuotaRegistry().Get(cfg.Quota))
+	}
+	return lim
+}
+
+func parseTrafficLimiter(cfg *config.LimiterConfig) traffic.TrafficLimiter {
+
 	if cfg.Plugin != nil {
 		var tlsCfg *tls.Config
 		if cfg.Plugin.TLS != nil {
@@ -2,38 +2,117 @@ package observer
 
 import (
 	"crypto/tls"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/go-gost/core/observer"
 	"github.com/go-gost/x/config"
 	"github.com/go-gost/x/internal/plugin"
+	xobserver "github.com/go-gost/x/observer"
 	observer_plugin "github.com/go-gost/x/observer/plugin"
+	"github.com/go-gost/x/registry"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-func ParseObserver(cfg *config.ObserverConfig) observer.Observer {
-	if cfg == nil || cfg.Plugin == nil {
-		return nil
+func ParseObserver(cfg *config.ObserverConfig) (observer.Observer, error) {
+	if cfg == nil {
+		return nil, nil
 	}
 
-	var tlsCfg *tls.Config
-	if cfg.Plugin.TLS != nil {
-		tlsCfg = &tls.Config{
-			ServerName:         cfg.Plugin.TLS.ServerName,
-			InsecureSkipVerify: !cfg.Plugin.TLS.Secure,
-		}
+	if err := CheckBackends(cfg); err != nil {
+		return nil, err
 	}
-	switch strings.ToLower(cfg.Plugin.Type) {
-	case "http":
-		return observer_plugin.NewHTTPPlugin(
-			cfg.Name, cfg.Plugin.Addr,
-			plugin.TLSConfigOption(tlsCfg),
-			plugin.TimeoutOption(cfg.Plugin.Timeout),
-		)
-	default:
-		return observer_plugin.NewGRPCPlugin(
-			cfg.Name, cfg.Plugin.Addr,
-			plugin.TokenOption(cfg.Plugin.Token),
-			plugin.TLSConfigOption(tlsCfg),
+
+	o := parseObserver(cfg)
+	if cfg.Quota != "" {
+		o = xobserver.QuotaObserver(registry.QuotaRegistry().Get(cfg.Quota), o,
+			xobserver.ResetTrafficOption(cfg.ResetTraffic),
 		)
 	}
+	return o, nil
+}
+
+// CheckBackends reports an error if more than one of the plugin, redis, file and metrics backends is set,
+// an observer only writes the stats to one of them.
+func CheckBackends(cfg *config.ObserverConfig) error {
+	var backends []string
+	if cfg.Plugin != nil {
+		backends = append(backends, "plugin")
+	}
+	if cfg.Redis != nil && cfg.Redis.Addr != "" {
+		backends = append(backends, "redis")
+	}
+	if cfg.File != nil && cfg.File.Path != "" {
+		backends = append(backends, "file")
+	}
+	if cfg.Metrics {
+		backends = append(backends, "metrics")
+	}
+	if len(backends) > 1 {
+		return fmt.Errorf("only one backend is allowed, got %s", strings.Join(backends, ", "))
+	}
+	return nil
+}
+
+func parseObserver(cfg *config.ObserverConfig) observer.Observer {
+
+	if cfg.Plugin != nil {
+		var tlsCfg *tls.Config
+		if cfg.Plugin.TLS != nil {
+			tlsCfg = &tls.Config{
+				ServerName:         cfg.Plugin.TLS.ServerName,
+				InsecureSkipVerify: !cfg.Plugin.TLS.Secure,
+			}
+		}
+		switch strings.ToLower(cfg.Plugin.Type) {
+		case "http":
+			return observer_plugin.NewHTTPPlugin(
+				cfg.Name, cfg.Plugin.Addr,
+				plugin.TLSConfigOption(tlsCfg),
+				plugin.TimeoutOption(cfg.Plugin.Timeout),
+			)
+		default:
+			return observer_plugin.NewGRPCPlugin(
+				cfg.Name, cfg.Plugin.Addr,
+				plugin.TokenOption(cfg.Plugin.Token),
+				plugin.TLSConfigOption(tlsCfg),
+			)
+		}
+	}
+
+	opts := []xobserver.Option{
+		xobserver.ResetTrafficOption(cfg.ResetTraffic),
+	}
+
+	if cfg.Redis != nil && cfg.Redis.Addr != "" {
+		return xobserver.RedisObserver(cfg.Redis.Addr, append(opts,
+			xobserver.DBOption(cfg.Redis.DB),
+			xobserver.UsernameOption(cfg.Redis.Username),
+			xobserver.PasswordOption(cfg.Redis.Password),
+			xobserver.KeyOption(cfg.Redis.Key),
+		)...)
+	}
+
+	if cfg.File != nil && cfg.File.Path != "" {
+		os.MkdirAll(filepath.Dir(cfg.File.Path), 0755)
+		out := &lumberjack.Logger{
+			Filename: cfg.File.Path,
+		}
+		if cfg.File.Rotation != nil {
+			out.MaxSize = cfg.File.Rotation.MaxSize
+			out.MaxAge = cfg.File.Rotation.MaxAge
+			out.MaxBackups = cfg.File.Rotation.MaxBackups
+			out.LocalTime = cfg.File.Rotation.LocalTime
+			out.Compress = cfg.File.Rotation.Compress
+		}
+		return xobserver.FileObserver(out, opts...)
+	}
+
+	if cfg.Metrics {
+		return xobserver.MetricsObserver(opts...)
+	}
+
+	return nil
 }
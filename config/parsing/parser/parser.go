@@ -194,6 +194,7 @@ func mergeConfig(cfg1, cfg2 *config.Config) *config.Config {
 		Loggers:    append(cfg1.Loggers, cfg2.Loggers...),
 		Routers:    append(cfg1.Routers, cfg2.Routers...),
 		Observers:  append(cfg1.Observers, cfg2.Observers...),
+		Quotas:     append(cfg1.Quotas, cfg2.Quotas...),
 		TLS:        cfg1.TLS,
 		Log:        cfg1.Log,
 		API:        cfg1.API,
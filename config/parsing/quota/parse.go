@@ -0,0 +1,116 @@
+package quota
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/units"
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/config"
+	xquota "github.com/go-gost/x/quota"
+)
+
+func ParseQuota(cfg *config.QuotaConfig) (xquota.Quota, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	limits, err := parseLimits(cfg.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	clientLimits := make(map[string][]xquota.Limit)
+	for _, c := range cfg.Clients {
+		if c == nil || c.Client == "" {
+			continue
+		}
+		if clientLimits[c.Client], err = parseLimits(c.Limits); err != nil {
+			return nil, fmt.Errorf("client %s: %w", c.Client, err)
+		}
+	}
+
+	rate, err := ParseBytes(cfg.Rate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate %s: %w", cfg.Rate, err)
+	}
+
+	opts := []xquota.Option{
+		xquota.LimitsOption(limits...),
+		xquota.ClientLimitsOption(clientLimits),
+		xquota.RateOption(int(rate)),
+		xquota.SyncPeriodOption(cfg.Sync),
+		xquota.LoggerOption(logger.Default().WithFields(map[string]any{
+			"kind":  "quota",
+			"quota": cfg.Name,
+		})),
+	}
+
+	if cfg.Redis != nil && cfg.Redis.Addr != "" {
+		opts = append(opts, xquota.StoreOption(xquota.RedisStore(
+			cfg.Redis.Addr,
+			xquota.DBRedisStoreOption(cfg.Redis.DB),
+			xquota.UsernameRedisStoreOption(cfg.Redis.Username),
+			xquota.PasswordRedisStoreOption(cfg.Redis.Password),
+			xquota.KeyRedisStoreOption(cfg.Redis.Key),
+		)))
+	} else if cfg.File != nil && cfg.File.Path != "" {
+		opts = append(opts, xquota.StoreOption(xquota.FileStore(cfg.File.Path)))
+	}
+
+	return xquota.NewQuota(opts...), nil
+}
+
+func parseLimits(cfgs []*config.QuotaLimitConfig) (limits []xquota.Limit, err error) {
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+
+		period, err := ParsePeriod(cfg.Period)
+		if err != nil {
+			return nil, err
+		}
+		bytes, err := ParseBytes(cfg.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bytes %s: %w", cfg.Bytes, err)
+		}
+		if bytes <= 0 && cfg.ConnTime <= 0 {
+			continue
+		}
+
+		limits = append(limits, xquota.Limit{
+			Period:   period,
+			Bytes:    bytes,
+			ConnTime: cfg.ConnTime,
+		})
+	}
+	return
+}
+
+// ParsePeriod returns the period of the limit, the empty period is total.
+func ParsePeriod(s string) (string, error) {
+	switch period := strings.ToLower(strings.TrimSpace(s)); period {
+	case "":
+		return xquota.PeriodTotal, nil
+	case xquota.PeriodDaily, xquota.PeriodMonthly, xquota.PeriodTotal:
+		return period, nil
+	default:
+		return "", fmt.Errorf("invalid period %s, must be daily, monthly or total", s)
+	}
+}
+
+// ParseBytes parses the byte size such as 10GB, the empty value is zero.
+func ParseBytes(s string) (int64, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return 0, nil
+	}
+	v, err := units.ParseBase2Bytes(s)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("negative size")
+	}
+	return int64(v), nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/go-gost/core/recorder"
 	"github.com/go-gost/x/config"
 	"github.com/go-gost/x/internal/plugin"
+	tls_util "github.com/go-gost/x/internal/util/tls"
 	xrecorder "github.com/go-gost/x/recorder"
 	recorder_plugin "github.com/go-gost/x/recorder/plugin"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -27,6 +28,138 @@ func ParseRecorder(cfg *config.RecorderConfig) (r recorder.Recorder) {
 		return nil
 	}
 
+	log := logger.Default().WithFields(map[string]any{
+		"kind":     "recorder",
+		"recorder": cfg.Name,
+	})
+
+	var encoder xrecorder.Encoder
+	if cfg.Format != nil {
+		encoder = xrecorder.NewEncoder(cfg.Format.Type,
+			xrecorder.NameEncoderOption(cfg.Name),
+			xrecorder.VendorEncoderOption(cfg.Format.Vendor),
+			xrecorder.ProductEncoderOption(cfg.Format.Product),
+			xrecorder.VersionEncoderOption(cfg.Format.Version),
+		)
+	}
+
+	switch {
+	case cfg.Plugin != nil:
+		return xrecorder.CaptureRecorder(parseRecorder(cfg, nil), parseCapturePolicy(cfg, log))
+	// syslog and kafka recorders execute the templates with the original records.
+	case cfg.Syslog != nil && cfg.Syslog.Addr != "":
+		r = parseSyslogRecorder(cfg, encoder, log)
+	case cfg.Kafka != nil && len(cfg.Kafka.Brokers) > 0:
+		r = parseKafkaRecorder(cfg, encoder, log)
+	default:
+		r = xrecorder.EncoderRecorder(parseRecorder(cfg, encoder), encoder)
+	}
+	if r == nil {
+		return
+	}
+
+	if cfg.Buffer != nil {
+		r = xrecorder.BufferRecorder(r,
+			xrecorder.RecorderBufferRecorderOption(cfg.Name),
+			xrecorder.QueueSizeBufferRecorderOption(cfg.Buffer.QueueSize),
+			xrecorder.BatchSizeBufferRecorderOption(cfg.Buffer.BatchSize),
+			xrecorder.FlushIntervalBufferRecorderOption(cfg.Buffer.FlushInterval),
+			xrecorder.PolicyBufferRecorderOption(cfg.Buffer.Policy),
+			xrecorder.LogBufferRecorderOption(log),
+		)
+	}
+
+	return xrecorder.CaptureRecorder(r, parseCapturePolicy(cfg, log))
+}
+
+func parseCapturePolicy(cfg *config.RecorderConfig, log logger.Logger) *xrecorder.HTTPCapturePolicy {
+	if cfg.Capture == nil {
+		return nil
+	}
+
+	var rules []xrecorder.HTTPCaptureRule
+	for _, rule := range cfg.Capture.Rules {
+		if rule == nil {
+			continue
+		}
+		rules = append(rules, xrecorder.HTTPCaptureRule{
+			Host:        rule.Host,
+			Path:        rule.Path,
+			ContentType: rule.ContentType,
+			Skip:        rule.Skip,
+			MaxBodySize: rule.MaxBodySize,
+		})
+	}
+
+	opts := []xrecorder.HTTPCapturePolicyOption{
+		xrecorder.RulesHTTPCapturePolicyOption(rules...),
+		xrecorder.MaxBodySizeHTTPCapturePolicyOption(cfg.Capture.MaxBodySize),
+		xrecorder.SampleRateHTTPCapturePolicyOption(cfg.Capture.SampleRate),
+		xrecorder.LoggerHTTPCapturePolicyOption(log),
+	}
+	if redact := cfg.Capture.Redact; redact != nil {
+		if len(redact.Headers) > 0 {
+			opts = append(opts, xrecorder.RedactHeadersHTTPCapturePolicyOption(redact.Headers...))
+		}
+		opts = append(opts, xrecorder.RedactJSONPathsHTTPCapturePolicyOption(redact.JSONPaths...))
+	}
+
+	return xrecorder.NewHTTPCapturePolicy(opts...)
+}
+
+func parseSyslogRecorder(cfg *config.RecorderConfig, encoder xrecorder.Encoder, log logger.Logger) recorder.Recorder {
+	var tlsConfig *tls.Config
+	if cfg.Syslog.TLS != nil {
+		var err error
+		if tlsConfig, err = tls_util.LoadClientConfig(cfg.Syslog.TLS); err != nil {
+			log.Error(err)
+			return nil
+		}
+	}
+
+	return xrecorder.SyslogRecorder(cfg.Syslog.Addr,
+		xrecorder.RecorderSyslogRecorderOption(cfg.Name),
+		xrecorder.NetworkSyslogRecorderOption(strings.ToLower(cfg.Syslog.Network)),
+		xrecorder.FacilitySyslogRecorderOption(xrecorder.SyslogFacility(cfg.Syslog.Facility)),
+		xrecorder.HostnameSyslogRecorderOption(cfg.Syslog.Hostname),
+		xrecorder.AppNameSyslogRecorderOption(cfg.Syslog.AppName),
+		xrecorder.MsgIDSyslogRecorderOption(cfg.Syslog.MsgID),
+		xrecorder.TimeoutSyslogRecorderOption(cfg.Syslog.Timeout),
+		xrecorder.TLSConfigSyslogRecorderOption(tlsConfig),
+		xrecorder.EncoderSyslogRecorderOption(encoder),
+		xrecorder.LogSyslogRecorderOption(log),
+	)
+}
+
+func parseKafkaRecorder(cfg *config.RecorderConfig, encoder xrecorder.Encoder, log logger.Logger) recorder.Recorder {
+	var tlsConfig *tls.Config
+	if cfg.Kafka.TLS != nil {
+		var err error
+		if tlsConfig, err = tls_util.LoadClientConfig(cfg.Kafka.TLS); err != nil {
+			log.Error(err)
+			return nil
+		}
+	}
+
+	opts := []xrecorder.KafkaRecorderOption{
+		xrecorder.RecorderKafkaRecorderOption(cfg.Name),
+		xrecorder.TopicKafkaRecorderOption(cfg.Kafka.Topic),
+		xrecorder.KeyKafkaRecorderOption(cfg.Kafka.Key),
+		xrecorder.ClientIDKafkaRecorderOption(cfg.Kafka.ClientID),
+		xrecorder.TimeoutKafkaRecorderOption(cfg.Kafka.Timeout),
+		xrecorder.TLSConfigKafkaRecorderOption(tlsConfig),
+		xrecorder.EncoderKafkaRecorderOption(encoder),
+		xrecorder.LogKafkaRecorderOption(log),
+	}
+	// acks 0 is a valid setting, only the unset value falls back to the default.
+	if cfg.Kafka.Acks != nil {
+		opts = append(opts, xrecorder.AcksKafkaRecorderOption(*cfg.Kafka.Acks))
+	}
+	return xrecorder.KafkaRecorder(cfg.Kafka.Brokers, opts...)
+}
+
+// parseRecorder creates the recorder of the records encoded by encoder.
+func parseRecorder(cfg *config.RecorderConfig, encoder xrecorder.Encoder) (r recorder.Recorder) {
 	if cfg.Plugin != nil {
 		var tlsCfg *tls.Config
 		if cfg.Plugin.TLS != nil {
@@ -95,6 +228,8 @@ func ParseRecorder(cfg *config.RecorderConfig) (r recorder.Recorder) {
 			xrecorder.RecorderHTTPRecorderOption(cfg.Name),
 			xrecorder.TimeoutHTTPRecorderOption(cfg.HTTP.Timeout),
 			xrecorder.HeaderHTTPRecorderOption(h),
+			xrecorder.GzipHTTPRecorderOption(cfg.HTTP.Gzip),
+			xrecorder.ContentTypeHTTPRecorderOption(xrecorder.ContentType(encoder)),
 		)
 	}
 
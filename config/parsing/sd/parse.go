@@ -4,36 +4,71 @@ import (
 	"crypto/tls"
 	"strings"
 
+	"github.com/go-gost/core/logger"
 	"github.com/go-gost/core/sd"
 	"github.com/go-gost/x/config"
 	"github.com/go-gost/x/internal/plugin"
+	xsd "github.com/go-gost/x/sd"
 	sd_plugin "github.com/go-gost/x/sd/plugin"
 )
 
 func ParseSD(cfg *config.SDConfig) sd.SD {
-	if cfg == nil || cfg.Plugin == nil {
+	if cfg == nil {
 		return nil
 	}
 
-	var tlsCfg *tls.Config
-	if cfg.Plugin.TLS != nil {
-		tlsCfg = &tls.Config{
-			ServerName:         cfg.Plugin.TLS.ServerName,
-			InsecureSkipVerify: !cfg.Plugin.TLS.Secure,
+	if cfg.Plugin != nil {
+		var tlsCfg *tls.Config
+		if cfg.Plugin.TLS != nil {
+			tlsCfg = &tls.Config{
+				ServerName:         cfg.Plugin.TLS.ServerName,
+				InsecureSkipVerify: !cfg.Plugin.TLS.Secure,
+			}
+		}
+		switch strings.ToLower(cfg.Plugin.Type) {
+		case "http":
+			return sd_plugin.NewHTTPPlugin(
+				cfg.Name, cfg.Plugin.Addr,
+				plugin.TLSConfigOption(tlsCfg),
+				plugin.TimeoutOption(cfg.Plugin.Timeout),
+			)
+		default:
+			return sd_plugin.NewGRPCPlugin(
+				cfg.Name, cfg.Plugin.Addr,
+				plugin.TokenOption(cfg.Plugin.Token),
+				plugin.TLSConfigOption(tlsCfg),
+			)
 		}
 	}
-	switch strings.ToLower(cfg.Plugin.Type) {
-	case "http":
-		return sd_plugin.NewHTTPPlugin(
-			cfg.Name, cfg.Plugin.Addr,
-			plugin.TLSConfigOption(tlsCfg),
-			plugin.TimeoutOption(cfg.Plugin.Timeout),
-		)
-	default:
-		return sd_plugin.NewGRPCPlugin(
-			cfg.Name, cfg.Plugin.Addr,
-			plugin.TokenOption(cfg.Plugin.Token),
-			plugin.TLSConfigOption(tlsCfg),
-		)
+
+	opts := []xsd.Option{
+		xsd.TTLOption(cfg.TTL),
+		xsd.LoggerOption(logger.Default().WithFields(map[string]any{
+			"kind": "sd",
+			"sd":   cfg.Name,
+		})),
 	}
+
+	if cfg.Redis != nil && cfg.Redis.Addr != "" {
+		return xsd.RedisSD(cfg.Redis.Addr, append(opts,
+			xsd.DBOption(cfg.Redis.DB),
+			xsd.UsernameOption(cfg.Redis.Username),
+			xsd.PasswordOption(cfg.Redis.Password),
+			xsd.KeyOption(cfg.Redis.Key),
+		)...)
+	}
+
+	if cfg.Gossip != nil && cfg.Gossip.Addr != "" {
+		return xsd.GossipSD(cfg.Gossip.Addr, append(opts,
+			xsd.PeersOption(cfg.Gossip.Peers),
+			xsd.IntervalOption(cfg.Gossip.Interval),
+			xsd.SecretOption(cfg.Gossip.Secret),
+		)...)
+	}
+
+	if cfg.File != nil && cfg.File.Path != "" {
+		return xsd.FileSD(cfg.File.Path, opts...)
+	}
+
+	return nil
 }
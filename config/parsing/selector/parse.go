@@ -22,6 +22,14 @@ func ParseChainSelector(cfg *config.SelectorConfig) selector.Selector[chain.Chai
 		strategy = xs.FIFOStrategy[chain.Chainer]()
 	case "hash":
 		strategy = xs.HashStrategy[chain.Chainer]()
+	case "leastconn", "lc":
+		strategy = xs.LeastConnStrategy[chain.Chainer]()
+	case "ewma", "latency":
+		strategy = xs.EWMAStrategy[chain.Chainer]()
+	case "p2c":
+		strategy = xs.P2CStrategy[chain.Chainer]()
+	case "chash", "consistent":
+		strategy = xs.ConsistentHashStrategy[chain.Chainer]()
 	default:
 		strategy = xs.RoundRobinStrategy[chain.Chainer]()
 	}
@@ -47,6 +55,14 @@ func ParseNodeSelector(cfg *config.SelectorConfig) selector.Selector[*chain.Node
 		strategy = xs.FIFOStrategy[*chain.Node]()
 	case "hash":
 		strategy = xs.HashStrategy[*chain.Node]()
+	case "leastconn", "lc":
+		strategy = xs.LeastConnStrategy[*chain.Node]()
+	case "ewma", "latency":
+		strategy = xs.EWMAStrategy[*chain.Node]()
+	case "p2c":
+		strategy = xs.P2CStrategy[*chain.Node]()
+	case "chash", "consistent":
+		strategy = xs.ConsistentHashStrategy[*chain.Node]()
 	default:
 		strategy = xs.RoundRobinStrategy[*chain.Node]()
 	}
@@ -18,6 +18,7 @@ import (
 	"github.com/go-gost/core/service"
 	xadmission "github.com/go-gost/x/admission"
 	xauth "github.com/go-gost/x/auth"
+	"github.com/go-gost/x/auth/lockout"
 	xbypass "github.com/go-gost/x/bypass"
 	xchain "github.com/go-gost/x/chain"
 	"github.com/go-gost/x/config"
@@ -87,7 +88,7 @@ func ParseService(cfg *config.ServiceConfig) (service.Service, error) {
 	}
 	var auther auth.Authenticator
 	if len(authers) > 0 {
-		auther = xauth.AuthenticatorGroup(authers...)
+		auther = lockout.WrapGroup(xauth.AuthenticatorGroup(authers...), authers...)
 	}
 
 	admissions := admission_parser.List(cfg.Admission, cfg.Admissions...)
@@ -256,7 +257,7 @@ func ParseService(cfg *config.ServiceConfig) (service.Service, error) {
 
 	auther = nil
 	if len(authers) > 0 {
-		auther = xauth.AuthenticatorGroup(authers...)
+		auther = lockout.WrapGroup(xauth.AuthenticatorGroup(authers...), authers...)
 	}
 
 	var recorders []recorder.RecorderObject
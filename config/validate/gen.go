@@ -0,0 +1,330 @@
+//go:build ignore
+
+// gen.go generates the metadata keys read by the registered listeners, handlers, dialers and connectors,
+// and the imports of all the components for the gost-validate command.
+//
+// The keys are collected from the calls of the metadata/util functions in the package of the component
+// and all the packages of this module it imports.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	modulePath = "github.com/go-gost/x"
+	mdutilPath = modulePath + "/metadata/util"
+)
+
+var categories = map[string]string{
+	"ListenerRegistry":  "listener",
+	"HandlerRegistry":   "handler",
+	"DialerRegistry":    "dialer",
+	"ConnectorRegistry": "connector",
+}
+
+type pkg struct {
+	path    string
+	consts  map[string][]string
+	imports map[string]bool
+	// unresolved keys: package-qualified constants.
+	refs  [][2]string
+	keys  map[string]bool
+	types map[string][]string
+}
+
+func main() {
+	root := filepath.Join("..", "..")
+
+	pkgs := make(map[string]*pkg)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); p != root && (strings.HasPrefix(name, ".") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, filepath.Dir(p))
+		importPath := path.Join(modulePath, filepath.ToSlash(rel))
+		if rel == "." {
+			importPath = modulePath
+		}
+		pk := pkgs[importPath]
+		if pk == nil {
+			pk = &pkg{
+				path:    importPath,
+				consts:  make(map[string][]string),
+				imports: make(map[string]bool),
+				keys:    make(map[string]bool),
+				types:   make(map[string][]string),
+			}
+		}
+		ok, err := parseFile(pk, p)
+		if err != nil {
+			return err
+		}
+		if ok {
+			pkgs[importPath] = pk
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// resolve the package-qualified constants.
+	for _, pk := range pkgs {
+		for _, ref := range pk.refs {
+			if p := pkgs[ref[0]]; p != nil {
+				for _, v := range p.consts[ref[1]] {
+					pk.keys[strings.ToLower(v)] = true
+				}
+			}
+		}
+	}
+
+	keys := make(map[string]map[string][]string)
+	var components []string
+	for _, pk := range pkgs {
+		if len(pk.types) == 0 {
+			continue
+		}
+		components = append(components, pk.path)
+
+		all := make(map[string]bool)
+		visit(pkgs, pk.path, make(map[string]bool), all)
+
+		var list []string
+		for k := range all {
+			list = append(list, k)
+		}
+		sort.Strings(list)
+
+		for category, types := range pk.types {
+			if keys[category] == nil {
+				keys[category] = make(map[string][]string)
+			}
+			for _, t := range types {
+				keys[category][t] = append(keys[category][t], list...)
+			}
+		}
+	}
+	sort.Strings(components)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go; DO NOT EDIT.\n\npackage validate\n\n")
+	for _, category := range []string{"listener", "handler", "dialer", "connector"} {
+		fmt.Fprintf(&buf, "var %sMetadataKeys = map[string][]string{\n", category)
+		m := keys[category]
+		var types []string
+		for t := range m {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			fmt.Fprintf(&buf, "%q: {", t)
+			for i, k := range dedup(m[t]) {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				fmt.Fprintf(&buf, "%q", k)
+			}
+			buf.WriteString("},\n")
+		}
+		buf.WriteString("}\n\n")
+	}
+	write("metadata_keys.go", buf.Bytes())
+
+	buf.Reset()
+	buf.WriteString("// Code generated by config/validate/gen.go; DO NOT EDIT.\n\npackage main\n\nimport (\n")
+	for _, c := range components {
+		fmt.Fprintf(&buf, "_ %q\n", c)
+	}
+	buf.WriteString(")\n")
+	write(filepath.Join(root, "cmd", "gost-validate", "register.go"), buf.Bytes())
+}
+
+func write(file string, b []byte) {
+	src, err := format.Source(b)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(file, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func visit(pkgs map[string]*pkg, importPath string, visited map[string]bool, keys map[string]bool) {
+	if visited[importPath] {
+		return
+	}
+	visited[importPath] = true
+
+	pk := pkgs[importPath]
+	if pk == nil {
+		return
+	}
+	for k := range pk.keys {
+		keys[k] = true
+	}
+	for imp := range pk.imports {
+		visit(pkgs, imp, visited, keys)
+	}
+}
+
+func dedup(ss []string) (list []string) {
+	sort.Strings(ss)
+	for i, s := range ss {
+		if i == 0 || s != ss[i-1] {
+			list = append(list, s)
+		}
+	}
+	return
+}
+
+func parseFile(pk *pkg, file string) (bool, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return false, err
+	}
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if c.Pos() < f.Package && strings.HasPrefix(c.Text, "//go:build") &&
+				strings.Contains(c.Text, "ignore") {
+				return false, nil
+			}
+		}
+	}
+	if f.Name.Name == "main" {
+		return false, nil
+	}
+
+	imports := make(map[string]string)
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		name := path.Base(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = p
+		if strings.HasPrefix(p, modulePath+"/") {
+			pk.imports[p] = true
+		}
+	}
+
+	var calls []*ast.CallExpr
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.GenDecl:
+			if t.Tok != token.CONST {
+				return true
+			}
+			for _, spec := range t.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if i < len(vs.Values) {
+						if v, ok := stringLit(vs.Values[i]); ok {
+							pk.consts[name.Name] = append(pk.consts[name.Name], v)
+						}
+					}
+				}
+			}
+		case *ast.CallExpr:
+			calls = append(calls, t)
+		}
+		return true
+	})
+
+	for _, call := range calls {
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+
+		// registry.XxxRegistry().Register("type", ...)
+		if sel.Sel.Name == "Register" && len(call.Args) > 0 {
+			if inner, ok := sel.X.(*ast.CallExpr); ok {
+				if isel, ok := inner.Fun.(*ast.SelectorExpr); ok {
+					if category, ok := categories[isel.Sel.Name]; ok {
+						if t := resolve(pk, call.Args[0]); t != "" {
+							pk.types[category] = append(pk.types[category], t)
+						}
+					}
+				}
+			}
+			continue
+		}
+
+		var args []ast.Expr
+		switch x := sel.X.(type) {
+		case *ast.Ident:
+			if imports[x.Name] == mdutilPath && len(call.Args) > 1 {
+				args = call.Args[1:]
+			} else if (sel.Sel.Name == "Get" || sel.Sel.Name == "IsExists") &&
+				strings.Contains(strings.ToLower(x.Name), "md") {
+				args = call.Args
+			}
+		}
+		for _, arg := range args {
+			switch a := arg.(type) {
+			case *ast.SelectorExpr:
+				if x, ok := a.X.(*ast.Ident); ok && imports[x.Name] != "" {
+					pk.refs = append(pk.refs, [2]string{imports[x.Name], a.Sel.Name})
+				}
+			default:
+				if v := resolve(pk, arg); v != "" {
+					pk.keys[strings.ToLower(v)] = true
+				} else if id, ok := arg.(*ast.Ident); ok {
+					// the constant may be declared in another file of the package.
+					pk.refs = append(pk.refs, [2]string{pk.path, id.Name})
+				}
+			}
+		}
+	}
+	return true, nil
+}
+
+func resolve(pk *pkg, e ast.Expr) string {
+	if v, ok := stringLit(e); ok {
+		return v
+	}
+	if id, ok := e.(*ast.Ident); ok {
+		if vs := pk.consts[id.Name]; len(vs) > 0 {
+			return vs[0]
+		}
+	}
+	return ""
+}
+
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	v, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	return v, true
+}
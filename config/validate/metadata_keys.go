@@ -0,0 +1,144 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package validate
+
+var listenerMetadataKeys = map[string][]string{
+	"dns":      {"backlog", "handshaketimeout", "keepalive", "maxidletimeout", "maxstreams", "mode", "mptcp", "readbuffersize", "readtimeout", "ttl", "writetimeout"},
+	"dtls":     {"buffersize", "dtls.buffersize", "dtls.flightinterval", "dtls.mtu", "flightinterval", "mtu"},
+	"ftcp":     {"backlog", "readbuffersize", "readqueuesize", "ttl"},
+	"grpc":     {"backlog", "cf-connecting-ip", "grpc.backlog", "grpc.insecure", "grpc.keepalive", "grpc.keepalive.maxconnectionidle", "grpc.keepalive.mintime", "grpc.keepalive.permitwithoutstream", "grpc.keepalive.time", "grpc.keepalive.timeout", "grpc.path", "grpcinsecure", "insecure", "keepalive", "keepalive.maxconnectionidle", "keepalive.mintime", "keepalive.permitwithoutstream", "keepalive.time", "keepalive.timeout", "mptcp", "path", "x-forwarded-for", "x-real-ip"},
+	"h2":       {"backlog", "mptcp", "path"},
+	"h2c":      {"backlog", "mptcp", "path"},
+	"h3":       {"authorizepath", "backlog", "handshaketimeout", "keepalive", "maxidletimeout", "maxstreams", "pht.authorizepath", "pht.pullpath", "pht.pushpath", "pullpath", "pushpath", "ttl"},
+	"http2":    {"backlog", "mptcp"},
+	"http3":    {"backlog", "handshaketimeout", "keepalive", "maxidletimeout", "maxstreams", "ttl"},
+	"icmp":     {"backlog", "handshaketimeout", "keepalive", "maxidletimeout", "ttl"},
+	"icmp6":    {"backlog", "handshaketimeout", "keepalive", "maxidletimeout", "ttl"},
+	"kcp":      {"backlog", "c", "config", "configfile", "kcp.config", "kcp.configfile", "kcp.crypt", "kcp.interval", "kcp.keepalive", "kcp.key", "kcp.mode", "kcp.mtu", "kcp.nocomp", "kcp.rcvwnd", "kcp.smuxbuf", "kcp.smuxver", "kcp.sndwnd", "kcp.streambuf", "kcp.tcp", "tcp"},
+	"mtcp":     {"backlog", "mptcp", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version"},
+	"mtls":     {"backlog", "mptcp", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version"},
+	"mws":      {"backlog", "enablecompression", "handshaketimeout", "header", "mptcp", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "path", "readbuffersize", "readheadertimeout", "writebuffersize", "ws.backlog", "ws.enablecompression", "ws.handshaketimeout", "ws.header", "ws.path", "ws.readbuffersize", "ws.readheadertimeout", "ws.writebuffersize"},
+	"mwss":     {"backlog", "enablecompression", "handshaketimeout", "header", "mptcp", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "path", "readbuffersize", "readheadertimeout", "writebuffersize", "ws.backlog", "ws.enablecompression", "ws.handshaketimeout", "ws.header", "ws.path", "ws.readbuffersize", "ws.readheadertimeout", "ws.writebuffersize"},
+	"ohttp":    {"header", "mptcp"},
+	"otls":     {"mptcp"},
+	"pht":      {"authorizepath", "backlog", "mptcp", "pullpath", "pushpath"},
+	"phts":     {"authorizepath", "backlog", "mptcp", "pullpath", "pushpath"},
+	"quic":     {"backlog", "cipherkey", "enabledatagram", "handshaketimeout", "keepalive", "maxidletimeout", "maxstreams", "quic.enabledatagram", "ttl"},
+	"red":      {"mptcp", "tproxy"},
+	"redir":    {"mptcp", "tproxy"},
+	"redirect": {"mptcp", "tproxy"},
+	"redu":     {"readbuffersize", "ttl"},
+	"rtcp":     {},
+	"rudp":     {"backlog", "readbuffersize", "readqueuesize", "ttl"},
+	"serial":   {"listener.serial.timeout", "serial.timeout", "timeout"},
+	"ssh":      {"authorizedkeys", "backlog", "mptcp", "passphrase", "privatekeyfile"},
+	"sshd":     {"authorizedkeys", "backlog", "mptcp", "passphrase", "passphrasefromkeyring", "privatekeyfile"},
+	"tap":      {"componentid", "gw", "mtu", "name", "net", "route", "routes"},
+	"tcp":      {"mptcp"},
+	"tls":      {"mptcp"},
+	"tun":      {"dns", "guid", "gw", "mtu", "name", "net", "peer", "route", "router", "routes", "tun.dns", "tun.guid", "tun.gw", "tun.mtu", "tun.name", "tun.net", "tun.peer", "tun.route", "tun.router", "tun.routes"},
+	"tungo":    {"dns", "guid", "gw", "mtu", "name", "net", "peer", "route", "routes", "tun.dns", "tun.guid", "tun.gw", "tun.mtu", "tun.name", "tun.net", "tun.peer", "tun.route", "tun.routes"},
+	"udp":      {"backlog", "keepalive", "keepalive.ttl", "readbuffersize", "readqueuesize", "recvqueuesize", "ttl", "udp.buffersize"},
+	"unix":     {},
+	"wg":       {"backlog", "mtu", "peers.reloadperiod", "privatekey", "tcpmoderatereceivebuffer", "tcpreceivebuffersize", "tcpsendbuffersize"},
+	"ws":       {"backlog", "enablecompression", "handshaketimeout", "header", "mptcp", "path", "readbuffersize", "readheadertimeout", "writebuffersize", "ws.backlog", "ws.enablecompression", "ws.handshaketimeout", "ws.header", "ws.path", "ws.readbuffersize", "ws.readheadertimeout", "ws.writebuffersize"},
+	"wss":      {"backlog", "enablecompression", "handshaketimeout", "header", "mptcp", "path", "readbuffersize", "readheadertimeout", "writebuffersize", "ws.backlog", "ws.enablecompression", "ws.handshaketimeout", "ws.header", "ws.path", "ws.readbuffersize", "ws.readheadertimeout", "ws.writebuffersize"},
+	"wt":       {"backlog", "handshaketimeout", "keepalive", "maxidletimeout", "maxstreams", "path", "ttl", "wt.path"},
+}
+
+var handlerMetadataKeys = map[string][]string{
+	"api":      {"accesslog", "admission", "api.accesslog", "api.pathprefix", "auth", "backup", "bypass", "ca", "cafile", "cert", "certfile", "climiter", "dialtimeout", "direction", "dns", "enablestats", "fail_timeout", "failtimeout", "hexdump", "hosts", "http.body", "http.maxbodysize", "ignorechain", "interface", "key", "keyfile", "limiter.cleanupinterval", "limiter.client.in", "limiter.client.out", "limiter.conn.in", "limiter.conn.out", "limiter.in", "limiter.out", "limiter.refreshinterval", "limiter.scope", "max_fails", "maxfails", "netns", "netns.out", "observeperiod", "observer.period", "observer.resettraffic", "path", "pathprefix", "postdown", "postup", "predown", "prefer", "preup", "proxyprotocol", "resolver", "retries", "rlimiter", "secure", "servername", "so_mark", "strategy", "timestampformat", "tls.cafile", "tls.certfile", "tls.keyfile", "tls.secure", "tls.servername", "weight"},
+	"auto":     {},
+	"dns":      {"async", "backup", "block", "block.answer", "block.matchers", "block.response", "buffersize", "clientip", "dns", "dns.routes", "failtimeout", "interface", "maxfails", "netns", "proxyprotocol", "readtimeout", "so_mark", "timeout", "ttl", "weight", "zone.file", "zone.http.timeout", "zone.http.url", "zone.records", "zone.redis.addr", "zone.redis.db", "zone.redis.key", "zone.redis.password", "zone.redis.type", "zone.redis.username", "zone.reload"},
+	"file":     {"dir", "file.dir"},
+	"forward":  {"http.keepalive", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "proxyprotocol", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate"},
+	"http":     {"authbasicrealm", "browserprofile", "clienthellospecfile", "compression", "hash", "header", "http.compression", "http.header", "http.keepalive", "http.proxyagent", "ja3", "ja4", "keepalive", "knock", "limiter.cleanupinterval", "limiter.refreshinterval", "mitm.alpn", "mitm.browserprofile", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.clienthellospecfile", "mitm.ja3", "mitm.ja4", "mitm.keyfile", "observeperiod", "observer.observeperiod", "observer.period", "observer.resettraffic", "probe_resist", "proberesist", "proxyagent", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate", "udp", "udp.buffersize", "udpbuffersize"},
+	"http2":    {"authbasicrealm", "hash", "header", "http.header", "knock", "limiter.cleanupinterval", "limiter.refreshinterval", "observeperiod", "observer.observeperiod", "observer.period", "observer.resettraffic", "probe_resist", "proberesist", "r", "w"},
+	"http3":    {"browserprofile", "clienthellospecfile", "hash", "header", "ja4", "ja4hash", "knock", "probe_resist", "proberesistance", "r", "w"},
+	"metrics":  {"metrics.path", "path"},
+	"red":      {"mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "readtimeout", "sniffing", "sniffing.fallback", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate", "tproxy"},
+	"redir":    {"mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "readtimeout", "sniffing", "sniffing.fallback", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate", "tproxy"},
+	"redirect": {"mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "readtimeout", "sniffing", "sniffing.fallback", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate", "tproxy"},
+	"redu":     {},
+	"relay":    {"bind", "hash", "limiter.cleanupinterval", "limiter.refreshinterval", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "nodelay", "observeperiod", "observer.observeperiod", "observer.period", "observer.resettraffic", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate", "udp.buffersize", "udpbuffersize"},
+	"router":   {"backup", "buffersize", "entrypoint", "failtimeout", "ingress", "limiter.cleanupinterval", "limiter.refreshinterval", "maxfails", "observeperiod", "observer.observeperiod", "observer.period", "observer.resettraffic", "readtimeout", "router", "router.buffersize", "router.cache", "router.cache.expiration", "sd", "sd.cache.expiration", "sd.renewinterval", "weight"},
+	"rtcp":     {"host", "http.keepalive", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "proxyprotocol", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate"},
+	"rudp":     {"host", "http.keepalive", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "proxyprotocol", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate"},
+	"serial":   {"serial.timeout", "timeout"},
+	"sni":      {"hash", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "readtimeout", "sniffing.websocket", "sniffing.websocket.samplerate"},
+	"socks":    {"bind", "comp", "hash", "limiter.cleanupinterval", "limiter.refreshinterval", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "notls", "observeperiod", "observer.observeperiod", "observer.period", "observer.resettraffic", "publicaddr", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate", "socks.publicaddr", "udp", "udp.buffersize", "udpbuffersize"},
+	"socks4":   {"hash", "limiter.cleanupinterval", "limiter.refreshinterval", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "observeperiod", "observer.observeperiod", "observer.period", "observer.resettraffic", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate"},
+	"socks4a":  {"hash", "limiter.cleanupinterval", "limiter.refreshinterval", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "observeperiod", "observer.observeperiod", "observer.period", "observer.resettraffic", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate"},
+	"socks5":   {"bind", "comp", "hash", "limiter.cleanupinterval", "limiter.refreshinterval", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "notls", "observeperiod", "observer.observeperiod", "observer.period", "observer.resettraffic", "publicaddr", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate", "socks.publicaddr", "udp", "udp.buffersize", "udpbuffersize"},
+	"ss":       {"hash", "key", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate"},
+	"sshd":     {"mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate"},
+	"ssu":      {"key", "readtimeout", "udp.buffersize", "udpbuffersize"},
+	"tap":      {"config", "key"},
+	"tcp":      {"http.keepalive", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "proxyprotocol", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate"},
+	"tun":      {"config", "keepalive", "p2p", "passphrase", "token", "ttl", "tun.keepalive", "tun.p2p", "tun.token", "tun.ttl"},
+	"tungo":    {"config", "ipv6", "limiter.cleanupinterval", "limiter.refreshinterval", "multicastgroups", "observeperiod", "observer.observeperiod", "observer.period", "observer.resettraffic", "sniffing", "sniffing.fallback", "sniffing.responsetimeout", "sniffing.timeout", "sniffing.udp", "tcpmoderatereceivebuffer", "tcpreceivebuffersize", "tcpsendbuffersize", "tungo.multicastgroups", "tungo.tcpmoderatereceivebuffer", "tungo.tcpreceivebuffersize", "tungo.tcpsendbuffersize", "tungo.udptimeout", "udp.buffersize", "udpbuffersize", "udptimeout"},
+	"tunnel":   {"backup", "entrypoint", "entrypoint.compression", "entrypoint.id", "entrypoint.keepalive", "entrypoint.proxyprotocol", "entrypoint.readtimeout", "entrypoints", "failtimeout", "ingress", "limiter.cleanupinterval", "limiter.refreshinterval", "maxfails", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "observeperiod", "observer.observeperiod", "observer.period", "observer.resettraffic", "readtimeout", "sd", "sniffing.websocket", "sniffing.websocket.samplerate", "tunnel", "tunnel.direct", "tunnel.ttl", "weight"},
+	"udp":      {"http.keepalive", "mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "proxyprotocol", "readtimeout", "sniffing", "sniffing.timeout", "sniffing.websocket", "sniffing.websocket.samplerate"},
+	"unix":     {"mitm.alpn", "mitm.bypass", "mitm.cacertfile", "mitm.cakeyfile", "mitm.certfile", "mitm.keyfile", "readtimeout", "sniffing", "sniffing.timeout"},
+	"wg":       {"limiter.cleanupinterval", "limiter.refreshinterval", "observeperiod", "observer.observeperiod", "observer.period", "observer.resettraffic", "udp.buffersize", "udpbuffersize", "udptimeout"},
+}
+
+var dialerMetadataKeys = map[string][]string{
+	"direct":  {},
+	"dtls":    {"buffersize", "dtls.buffersize", "dtls.flightinterval", "dtls.mtu", "flightinterval", "mtu"},
+	"ftcp":    {},
+	"grpc":    {"grpc.authority", "grpc.host", "grpc.insecure", "grpc.keepalive", "grpc.keepalive.permitwithoutstream", "grpc.keepalive.time", "grpc.keepalive.timeout", "grpc.minconnecttimeout", "grpc.path", "grpcinsecure", "host", "insecure", "keepalive", "keepalive.permitwithoutstream", "keepalive.time", "keepalive.timeout", "minconnecttimeout", "path"},
+	"h2":      {"header", "host", "path"},
+	"h2c":     {"header", "host", "path"},
+	"h3":      {"authorizepath", "handshaketimeout", "host", "keepalive", "maxidletimeout", "maxstreams", "pht.authorizepath", "pht.pullpath", "pht.pushpath", "pullpath", "pushpath", "ttl"},
+	"http2":   {},
+	"http3":   {"authorizepath", "handshaketimeout", "host", "keepalive", "maxidletimeout", "maxstreams", "pht.authorizepath", "pht.pullpath", "pht.pushpath", "pullpath", "pushpath", "ttl"},
+	"icmp":    {"handshaketimeout", "keepalive", "maxidletimeout", "ttl"},
+	"icmp6":   {"handshaketimeout", "keepalive", "maxidletimeout", "ttl"},
+	"kcp":     {"c", "config", "configfile", "handshaketimeout", "kcp.config", "kcp.configfile", "kcp.crypt", "kcp.interval", "kcp.keepalive", "kcp.key", "kcp.mode", "kcp.mtu", "kcp.nocomp", "kcp.rcvwnd", "kcp.smuxbuf", "kcp.smuxver", "kcp.sndwnd", "kcp.streambuf", "kcp.tcp", "tcp"},
+	"mtcp":    {"handshaketimeout", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version"},
+	"mtls":    {"handshaketimeout", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version"},
+	"mws":     {"enablecompression", "handshaketimeout", "header", "host", "keepalive", "keepalive.interval", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "path", "readbuffersize", "readheadertimeout", "ttl", "writebuffersize", "ws.enablecompression", "ws.handshaketimeout", "ws.header", "ws.host", "ws.keepalive", "ws.path", "ws.readbuffersize", "ws.readheadertimeout", "ws.writebuffersize"},
+	"mwss":    {"enablecompression", "handshaketimeout", "header", "host", "keepalive", "keepalive.interval", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "path", "readbuffersize", "readheadertimeout", "ttl", "writebuffersize", "ws.enablecompression", "ws.handshaketimeout", "ws.header", "ws.host", "ws.keepalive", "ws.path", "ws.readbuffersize", "ws.readheadertimeout", "ws.writebuffersize"},
+	"ohttp":   {"header", "host", "obfs.header", "obfs.host", "obfs.path", "path"},
+	"ohttps":  {"header", "host", "obfs.header", "obfs.host", "obfs.path", "path"},
+	"otls":    {"host"},
+	"pht":     {"authorizepath", "host", "pullpath", "pushpath"},
+	"phts":    {"authorizepath", "host", "pullpath", "pushpath"},
+	"quic":    {"cipherkey", "enabledatagram", "handshaketimeout", "keepalive", "maxidletimeout", "maxstreams", "quic.enabledatagram", "ttl"},
+	"serial":  {},
+	"ssh":     {"handshaketimeout", "keepalive", "keepalive.interval", "keepalive.retries", "keepalive.timeout", "passphrase", "passphrasefromkeyring", "privatekeyfile", "ttl"},
+	"sshd":    {"handshaketimeout", "keepalive", "keepalive.interval", "keepalive.retries", "keepalive.timeout", "passphrase", "passphrasefromkeyring", "privatekeyfile", "ttl"},
+	"tcp":     {},
+	"tls":     {"handshaketimeout"},
+	"udp":     {},
+	"unix":    {},
+	"virtual": {},
+	"wg":      {"address", "allowedips", "dns", "keepalive", "mtu", "peer.allowedips", "peer.keepalive", "peer.publickey", "presharedkey", "privatekey", "publickey"},
+	"ws":      {"enablecompression", "handshaketimeout", "header", "host", "keepalive", "keepalive.interval", "path", "readbuffersize", "readheadertimeout", "ttl", "writebuffersize", "ws.enablecompression", "ws.handshaketimeout", "ws.header", "ws.host", "ws.keepalive", "ws.path", "ws.readbuffersize", "ws.readheadertimeout", "ws.writebuffersize"},
+	"wss":     {"enablecompression", "handshaketimeout", "header", "host", "keepalive", "keepalive.interval", "path", "readbuffersize", "readheadertimeout", "ttl", "writebuffersize", "ws.enablecompression", "ws.handshaketimeout", "ws.header", "ws.host", "ws.keepalive", "ws.path", "ws.readbuffersize", "ws.readheadertimeout", "ws.writebuffersize"},
+	"wt":      {"handshaketimeout", "header", "host", "keepalive", "maxidletimeout", "maxstreams", "path", "ttl", "wt.header", "wt.host", "wt.path"},
+}
+
+var connectorMetadataKeys = map[string][]string{
+	"direct":  {"action"},
+	"forward": {},
+	"http":    {"header", "timeout"},
+	"http2":   {"client", "header", "timeout"},
+	"relay":   {"connecttimeout", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "nodelay"},
+	"router":  {"connecttimeout", "router.id"},
+	"serial":  {},
+	"sni":     {"host", "timeout"},
+	"socks":   {"mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "notls", "relay", "timeout", "udp.buffersize", "udp.timeout", "udpbuffersize"},
+	"socks4":  {"disable4a", "timeout"},
+	"socks4a": {"disable4a", "timeout"},
+	"socks5":  {"mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "notls", "relay", "timeout", "udp.buffersize", "udp.timeout", "udpbuffersize"},
+	"ss":      {"key", "nodelay", "timeout"},
+	"sshd":    {},
+	"ssu":     {"connecttimeout", "key", "timeout", "udp.buffersize", "udpbuffersize"},
+	"tcp":     {},
+	"tunnel":  {"connecttimeout", "mux.keepalivedisabled", "mux.keepaliveinterval", "mux.keepalivetimeout", "mux.maxframesize", "mux.maxreceivebuffer", "mux.maxstreambuffer", "mux.version", "tunnel.id", "tunnel.weight", "tunnelid"},
+	"unix":    {},
+	"virtual": {"action"},
+	"wg":      {},
+}
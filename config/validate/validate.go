@@ -0,0 +1,517 @@
+// Package validate checks the config without loading it.
+package validate
+
+//go:generate go run gen.go
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/go-gost/x/config"
+	observer_parser "github.com/go-gost/x/config/parsing/observer"
+	quota_parser "github.com/go-gost/x/config/parsing/quota"
+	"github.com/go-gost/x/registry"
+)
+
+// Error is a problem found in config.
+type Error struct {
+	// Path is the location of the problem in config, such as services[0].handler.chain.
+	Path string `json:"path"`
+	Msg  string `json:"msg"`
+	// Warning reports whether the problem does not prevent the config from being loaded,
+	// such as an unknown metadata key.
+	Warning bool `json:"warning,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Warning {
+		return fmt.Sprintf("%s: %s (warning)", e.Path, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+}
+
+type Errors []*Error
+
+func (e Errors) Error() string {
+	var b strings.Builder
+	for i, err := range e {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Err returns the errors without the warnings as an error, nil is returned if there is no error.
+func (e Errors) Err() error {
+	var errs Errors
+	for _, err := range e {
+		if !err.Warning {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks the config and reports all the problems found:
+//   - duplicate object names.
+//   - unregistered listener, handler, dialer and connector types.
+//   - unknown metadata keys of listener, handler, dialer and connector, reported as warnings.
+//   - references to the objects not defined in config, such as chain, hop, bypass and auther.
+//   - port conflicts between services.
+func Validate(cfg *config.Config) Errors {
+	if cfg == nil {
+		return nil
+	}
+
+	v := &validator{
+		cfg:   cfg,
+		names: make(map[string]map[string]bool),
+	}
+	v.collectNames()
+	v.checkServices()
+	v.checkHops()
+	v.checkChains()
+	v.checkOthers()
+	v.checkPorts()
+
+	return v.errs
+}
+
+const (
+	kindAdmission = "admission"
+	kindAuther    = "auther"
+	kindBypass    = "bypass"
+	kindChain     = "chain"
+	kindCLimiter  = "climiter"
+	kindHop       = "hop"
+	kindHosts     = "hosts"
+	kindLimiter   = "limiter"
+	kindLogger    = "logger"
+	kindObserver  = "observer"
+	kindQuota     = "quota"
+	kindRecorder  = "recorder"
+	kindResolver  = "resolver"
+	kindRLimiter  = "rlimiter"
+)
+
+type validator struct {
+	cfg   *config.Config
+	names map[string]map[string]bool
+	errs  Errors
+}
+
+func (v *validator) errorf(path string, format string, args ...any) {
+	v.errs = append(v.errs, &Error{Path: path, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(path string, format string, args ...any) {
+	v.errs = append(v.errs, &Error{Path: path, Msg: fmt.Sprintf(format, args...), Warning: true})
+}
+
+func (v *validator) collectNames() {
+	cfg := v.cfg
+
+	add := func(section, kind string, names []string) {
+		m := v.names[kind]
+		if m == nil {
+			m = make(map[string]bool)
+			v.names[kind] = m
+		}
+		for i, name := range names {
+			if name == "" {
+				continue
+			}
+			if m[name] {
+				v.errorf(fmt.Sprintf("%s[%d].name", section, i), "duplicate %s name %s", kind, name)
+			}
+			m[name] = true
+		}
+	}
+
+	add("services", "service", namesOf(cfg.Services, func(c *config.ServiceConfig) string { return c.Name }))
+	add("chains", kindChain, namesOf(cfg.Chains, func(c *config.ChainConfig) string { return c.Name }))
+	add("hops", kindHop, namesOf(cfg.Hops, func(c *config.HopConfig) string { return c.Name }))
+	add("authers", kindAuther, namesOf(cfg.Authers, func(c *config.AutherConfig) string { return c.Name }))
+	add("admissions", kindAdmission, namesOf(cfg.Admissions, func(c *config.AdmissionConfig) string { return c.Name }))
+	add("bypasses", kindBypass, namesOf(cfg.Bypasses, func(c *config.BypassConfig) string { return c.Name }))
+	add("resolvers", kindResolver, namesOf(cfg.Resolvers, func(c *config.ResolverConfig) string { return c.Name }))
+	add("hosts", kindHosts, namesOf(cfg.Hosts, func(c *config.HostsConfig) string { return c.Name }))
+	add("ingresses", "ingress", namesOf(cfg.Ingresses, func(c *config.IngressConfig) string { return c.Name }))
+	add("routers", "router", namesOf(cfg.Routers, func(c *config.RouterConfig) string { return c.Name }))
+	add("sds", "sd", namesOf(cfg.SDs, func(c *config.SDConfig) string { return c.Name }))
+	add("recorders", kindRecorder, namesOf(cfg.Recorders, func(c *config.RecorderConfig) string { return c.Name }))
+	add("limiters", kindLimiter, namesOf(cfg.Limiters, func(c *config.LimiterConfig) string { return c.Name }))
+	add("climiters", kindCLimiter, namesOf(cfg.CLimiters, func(c *config.LimiterConfig) string { return c.Name }))
+	add("rlimiters", kindRLimiter, namesOf(cfg.RLimiters, func(c *config.LimiterConfig) string { return c.Name }))
+	add("observers", kindObserver, namesOf(cfg.Observers, func(c *config.ObserverConfig) string { return c.Name }))
+	add("quotas", kindQuota, namesOf(cfg.Quotas, func(c *config.QuotaConfig) string { return c.Name }))
+	add("loggers", kindLogger, namesOf(cfg.Loggers, func(c *config.LoggerConfig) string { return c.Name }))
+
+	// the lockout admissions are registered by the authers.
+	for _, c := range cfg.Authers {
+		if c != nil && c.Lockout != nil && c.Lockout.Admission != "" {
+			v.names[kindAdmission][c.Lockout.Admission] = true
+		}
+	}
+}
+
+func namesOf[T any](list []*T, name func(*T) string) []string {
+	names := make([]string, len(list))
+	for i, c := range list {
+		if c != nil {
+			names[i] = name(c)
+		}
+	}
+	return names
+}
+
+// ref checks the referenced object of kind exists, path is the field path of the reference.
+func (v *validator) ref(path string, kind string, name string) {
+	if name != "" && !v.names[kind][name] {
+		v.errorf(path, "%s %s not found", kind, name)
+	}
+}
+
+func (v *validator) checkServices() {
+	for i, c := range v.cfg.Services {
+		if c == nil {
+			continue
+		}
+		path := fmt.Sprintf("services[%d]", i)
+
+		v.ref(path+".admission", kindAdmission, c.Admission)
+		v.list(path+".admissions", kindAdmission, c.Admissions)
+		v.ref(path+".bypass", kindBypass, c.Bypass)
+		v.list(path+".bypasses", kindBypass, c.Bypasses)
+		v.ref(path+".resolver", kindResolver, c.Resolver)
+		v.ref(path+".hosts", kindHosts, c.Hosts)
+		v.ref(path+".limiter", kindLimiter, c.Limiter)
+		v.ref(path+".climiter", kindCLimiter, c.CLimiter)
+		v.ref(path+".rlimiter", kindRLimiter, c.RLimiter)
+		v.ref(path+".logger", kindLogger, c.Logger)
+		v.list(path+".loggers", kindLogger, c.Loggers)
+		v.ref(path+".observer", kindObserver, c.Observer)
+		for j, r := range c.Recorders {
+			if r != nil {
+				v.ref(fmt.Sprintf("%s.recorders[%d].name", path, j), kindRecorder, r.Name)
+			}
+		}
+
+		listenerType := "tcp"
+		if c.Listener != nil {
+			l := c.Listener
+			if t := strings.TrimSpace(l.Type); t != "" {
+				listenerType = t
+			}
+			v.ref(path+".listener.chain", kindChain, l.Chain)
+			v.chainGroup(path+".listener.chainGroup", l.ChainGroup)
+			v.ref(path+".listener.auther", kindAuther, l.Auther)
+			v.list(path+".listener.authers", kindAuther, l.Authers)
+			v.metadata(path+".listener.metadata", "listener", listenerType, listenerMetadataKeys, l.Metadata)
+		}
+		if !registry.ListenerRegistry().IsRegistered(listenerType) {
+			v.errorf(path+".listener.type", "unregistered listener %s", listenerType)
+		}
+
+		handlerType := "auto"
+		if c.Handler != nil {
+			h := c.Handler
+			if t := strings.TrimSpace(h.Type); t != "" {
+				handlerType = t
+			}
+			v.ref(path+".handler.chain", kindChain, h.Chain)
+			v.chainGroup(path+".handler.chainGroup", h.ChainGroup)
+			v.ref(path+".handler.auther", kindAuther, h.Auther)
+			v.list(path+".handler.authers", kindAuther, h.Authers)
+			v.ref(path+".handler.limiter", kindLimiter, h.Limiter)
+			v.ref(path+".handler.observer", kindObserver, h.Observer)
+			v.metadata(path+".handler.metadata", "handler", handlerType, handlerMetadataKeys, h.Metadata)
+		}
+		if !registry.HandlerRegistry().IsRegistered(handlerType) {
+			v.errorf(path+".handler.type", "unregistered handler %s", handlerType)
+		}
+
+		if f := c.Forwarder; f != nil {
+			v.ref(path+".forwarder.hop", kindHop, f.Hop)
+			for j, node := range f.Nodes {
+				if node == nil {
+					continue
+				}
+				npath := fmt.Sprintf("%s.forwarder.nodes[%d]", path, j)
+				v.ref(npath+".bypass", kindBypass, node.Bypass)
+				v.list(npath+".bypasses", kindBypass, node.Bypasses)
+			}
+		}
+	}
+}
+
+func (v *validator) list(path string, kind string, names []string) {
+	for i, name := range names {
+		v.ref(fmt.Sprintf("%s[%d]", path, i), kind, name)
+	}
+}
+
+func (v *validator) chainGroup(path string, c *config.ChainGroupConfig) {
+	if c != nil {
+		v.list(path+".chains", kindChain, c.Chains)
+	}
+}
+
+func (v *validator) checkHops() {
+	for i, c := range v.cfg.Hops {
+		if c != nil {
+			v.hop(fmt.Sprintf("hops[%d]", i), c)
+		}
+	}
+}
+
+func (v *validator) checkChains() {
+	for i, c := range v.cfg.Chains {
+		if c == nil {
+			continue
+		}
+		for j, hop := range c.Hops {
+			if hop == nil {
+				continue
+			}
+			path := fmt.Sprintf("chains[%d].hops[%d]", i, j)
+			// a hop without nodes refers to a hop defined in hops.
+			if hop.Nodes == nil && hop.Plugin == nil {
+				v.ref(path+".name", kindHop, hop.Name)
+				continue
+			}
+			v.hop(path, hop)
+		}
+	}
+}
+
+func (v *validator) hop(path string, c *config.HopConfig) {
+	v.ref(path+".bypass", kindBypass, c.Bypass)
+	v.list(path+".bypasses", kindBypass, c.Bypasses)
+	v.ref(path+".resolver", kindResolver, c.Resolver)
+	v.ref(path+".hosts", kindHosts, c.Hosts)
+	if c.HealthCheck != nil {
+		v.ref(path+".healthCheck.chain", kindChain, c.HealthCheck.Chain)
+	}
+
+	for i, node := range c.Nodes {
+		if node == nil {
+			continue
+		}
+		npath := fmt.Sprintf("%s.nodes[%d]", path, i)
+		v.ref(npath+".bypass", kindBypass, node.Bypass)
+		v.list(npath+".bypasses", kindBypass, node.Bypasses)
+		v.ref(npath+".resolver", kindResolver, node.Resolver)
+		v.ref(npath+".hosts", kindHosts, node.Hosts)
+
+		connectorType := "http"
+		if node.Connector != nil {
+			if t := strings.TrimSpace(node.Connector.Type); t != "" {
+				connectorType = t
+			}
+			v.metadata(npath+".connector.metadata", "connector", connectorType, connectorMetadataKeys, node.Connector.Metadata)
+		}
+		if !registry.ConnectorRegistry().IsRegistered(connectorType) {
+			v.errorf(npath+".connector.type", "unregistered connector %s", connectorType)
+		}
+
+		dialerType := "tcp"
+		if node.Dialer != nil {
+			if t := strings.TrimSpace(node.Dialer.Type); t != "" {
+				dialerType = t
+			}
+			v.metadata(npath+".dialer.metadata", "dialer", dialerType, dialerMetadataKeys, node.Dialer.Metadata)
+		}
+		if !registry.DialerRegistry().IsRegistered(dialerType) {
+			v.errorf(npath+".dialer.type", "unregistered dialer %s", dialerType)
+		}
+	}
+}
+
+func (v *validator) checkOthers() {
+	for i, c := range v.cfg.Authers {
+		if c == nil {
+			continue
+		}
+		if c.Lockout != nil {
+			v.ref(fmt.Sprintf("authers[%d].lockout.recorder", i), kindRecorder, c.Lockout.Recorder)
+		}
+		v.ref(fmt.Sprintf("authers[%d].quota", i), kindQuota, c.Quota)
+	}
+	for i, c := range v.cfg.Limiters {
+		if c != nil {
+			v.ref(fmt.Sprintf("limiters[%d].quota", i), kindQuota, c.Quota)
+		}
+	}
+	for i, c := range v.cfg.Observers {
+		if c == nil {
+			continue
+		}
+		path := fmt.Sprintf("observers[%d]", i)
+		if err := observer_parser.CheckBackends(c); err != nil {
+			v.errorf(path, "%v", err)
+		}
+		v.ref(path+".quota", kindQuota, c.Quota)
+	}
+	for i, c := range v.cfg.Quotas {
+		if c == nil {
+			continue
+		}
+		path := fmt.Sprintf("quotas[%d]", i)
+		if _, err := quota_parser.ParseBytes(c.Rate); err != nil {
+			v.errorf(path+".rate", "invalid rate %s", c.Rate)
+		}
+		v.quotaLimits(path+".limits", c.Limits)
+		for j, cc := range c.Clients {
+			if cc != nil {
+				v.quotaLimits(fmt.Sprintf("%s.clients[%d].limits", path, j), cc.Limits)
+			}
+		}
+	}
+	for i, c := range v.cfg.Resolvers {
+		if c == nil {
+			continue
+		}
+		for j, ns := range c.Nameservers {
+			if ns != nil {
+				v.ref(fmt.Sprintf("resolvers[%d].nameservers[%d].chain", i, j), kindChain, ns.Chain)
+			}
+		}
+	}
+}
+
+func (v *validator) quotaLimits(path string, limits []*config.QuotaLimitConfig) {
+	for i, c := range limits {
+		if c == nil {
+			continue
+		}
+		if _, err := quota_parser.ParsePeriod(c.Period); err != nil {
+			v.errorf(fmt.Sprintf("%s[%d].period", path, i), "invalid period %s, must be daily, monthly or total", c.Period)
+		}
+		if _, err := quota_parser.ParseBytes(c.Bytes); err != nil {
+			v.errorf(fmt.Sprintf("%s[%d].bytes", path, i), "invalid bytes %s", c.Bytes)
+		}
+	}
+}
+
+// metadata checks the metadata keys against the keys read by the component,
+// the component types not known at build time, such as plugins, are skipped.
+func (v *validator) metadata(path string, kind string, typ string, known map[string][]string, md map[string]any) {
+	keys, ok := known[typ]
+	if !ok || len(md) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(md))
+	for k := range md {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		if !contains(keys, strings.ToLower(k)) {
+			v.warnf(path+"."+k, "unknown metadata key %s for %s %s", k, kind, typ)
+		}
+	}
+}
+
+func contains(keys []string, key string) bool {
+	n := sort.SearchStrings(keys, key)
+	return n < len(keys) && keys[n] == key
+}
+
+// the listeners that listen on UDP port.
+var udpListeners = map[string]bool{
+	"udp":   true,
+	"dtls":  true,
+	"dns":   true,
+	"kcp":   true,
+	"quic":  true,
+	"http3": true,
+	"h3":    true,
+	"wt":    true,
+	"redu":  true,
+}
+
+// the listeners that do not listen on a local port.
+var portlessListeners = map[string]bool{
+	"rtcp":   true,
+	"rudp":   true,
+	"ftcp":   true,
+	"icmp":   true,
+	"icmp6":  true,
+	"tun":    true,
+	"tungo":  true,
+	"tap":    true,
+	"unix":   true,
+	"serial": true,
+}
+
+type bind struct {
+	path    string
+	network string
+	host    string
+	port    string
+}
+
+func (v *validator) checkPorts() {
+	var binds []bind
+
+	add := func(path, network, addr string) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || port == "" || port == "0" {
+			return
+		}
+		if host == "0.0.0.0" || host == "::" {
+			host = ""
+		}
+		binds = append(binds, bind{path: path, network: network, host: host, port: port})
+	}
+
+	for i, c := range v.cfg.Services {
+		if c == nil || c.Addr == "" {
+			continue
+		}
+		typ := "tcp"
+		if c.Listener != nil && c.Listener.Type != "" {
+			typ = c.Listener.Type
+		}
+		if portlessListeners[typ] {
+			continue
+		}
+		network := "tcp"
+		if udpListeners[typ] {
+			network = "udp"
+		}
+		add(fmt.Sprintf("services[%d].addr", i), network, c.Addr)
+	}
+	if c := v.cfg.API; c != nil {
+		add("api.addr", "tcp", c.Addr)
+	}
+	if c := v.cfg.Metrics; c != nil {
+		add("metrics.addr", "tcp", c.Addr)
+	}
+	if c := v.cfg.Profiling; c != nil {
+		add("profiling.addr", "tcp", c.Addr)
+	}
+
+	for i := range binds {
+		for j := 0; j < i; j++ {
+			a, b := binds[j], binds[i]
+			if a.network != b.network || a.port != b.port {
+				continue
+			}
+			if a.host == b.host || a.host == "" || b.host == "" {
+				v.errorf(b.path, "%s port %s conflicts with %s", b.network, b.port, a.path)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/go-gost/core/connector"
+	"github.com/go-gost/core/dialer"
+	"github.com/go-gost/core/handler"
+	"github.com/go-gost/core/listener"
+	"github.com/go-gost/x/config"
+	"github.com/go-gost/x/registry"
+)
+
+func init() {
+	registry.ListenerRegistry().Register("tcp", func(opts ...listener.Option) listener.Listener { return nil })
+	registry.ListenerRegistry().Register("udp", func(opts ...listener.Option) listener.Listener { return nil })
+	registry.HandlerRegistry().Register("http", func(opts ...handler.Option) handler.Handler { return nil })
+	registry.DialerRegistry().Register("tcp", func(opts ...dialer.Option) dialer.Dialer { return nil })
+	registry.ConnectorRegistry().Register("http", func(opts ...connector.Option) connector.Connector { return nil })
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &config.Config{
+		Services: []*config.ServiceConfig{
+			{
+				Name:      "svc-0",
+				Addr:      ":8080",
+				Admission: "lockout-0",
+				Handler: &config.HandlerConfig{
+					Type:     "http",
+					Chain:    "chain-0",
+					Auther:   "auther-0",
+					Metadata: map[string]any{"readTimeout": "5s", "keepalve": true},
+				},
+			},
+			{
+				Name:    "svc-1",
+				Addr:    "0.0.0.0:8080",
+				Limiter: "limiter-0",
+				Handler: &config.HandlerConfig{Type: "http", ChainGroup: &config.ChainGroupConfig{Chains: []string{"chain-0", "chain-1"}}},
+			},
+			{
+				// same port on UDP.
+				Name:     "svc-2",
+				Addr:     ":8080",
+				Handler:  &config.HandlerConfig{Type: "http"},
+				Listener: &config.ListenerConfig{Type: "udp"},
+			},
+			{
+				Name:     "svc-3",
+				Addr:     "127.0.0.1:8081",
+				Handler:  &config.HandlerConfig{Type: "socks6"},
+				Listener: &config.ListenerConfig{Type: "tcp"},
+			},
+		},
+		Chains: []*config.ChainConfig{
+			{Name: "chain-0", Hops: []*config.HopConfig{{Name: "hop-0"}, {Name: "hop-1"}}},
+		},
+		Hops: []*config.HopConfig{
+			{Name: "hop-0", Nodes: []*config.NodeConfig{{Name: "node-0", Bypass: "bypass-0"}}},
+		},
+		Authers: []*config.AutherConfig{
+			{Name: "auther-0", Lockout: &config.LockoutConfig{Admission: "lockout-0"}},
+			{Name: "auther-0"},
+		},
+		Quotas: []*config.QuotaConfig{
+			{
+				Name:    "quota-0",
+				Rate:    "64KB",
+				Limits:  []*config.QuotaLimitConfig{{Period: "Daily", Bytes: "10GB"}, {Period: "weekly", Bytes: "10GB"}},
+				Clients: []*config.QuotaClientConfig{{Client: "u1", Limits: []*config.QuotaLimitConfig{{Bytes: "10 apples"}}}},
+			},
+		},
+		Observers: []*config.ObserverConfig{
+			{Name: "observer-0", Metrics: true, File: &config.FileObserver{Path: "stats.jsonl"}},
+		},
+		API: &config.APIConfig{Addr: "127.0.0.1:8081"},
+	}
+
+	want := map[string]bool{
+		"authers[1].name":                          false,
+		"services[0].handler.metadata.keepalve":    true,
+		"services[1].addr":                         false,
+		"services[1].limiter":                      false,
+		"services[1].handler.chainGroup.chains[1]": false,
+		"services[3].handler.type":                 false,
+		"chains[0].hops[1].name":                   false,
+		"hops[0].nodes[0].bypass":                  false,
+		"api.addr":                                 false,
+		"quotas[0].limits[1].period":               false,
+		"quotas[0].clients[0].limits[0].bytes":     false,
+		"observers[0]":                             false,
+	}
+
+	errs := Validate(cfg)
+	for _, err := range errs {
+		warning, ok := want[err.Path]
+		if !ok {
+			t.Errorf("unexpected error %v", err)
+			continue
+		}
+		if warning != err.Warning {
+			t.Errorf("%v: warning %v, want %v", err, err.Warning, warning)
+		}
+		delete(want, err.Path)
+	}
+	for path := range want {
+		t.Errorf("%s is not reported", path)
+	}
+
+	if errs.Err() == nil {
+		t.Error("no error returned")
+	}
+	if err := (Errors{{Path: "a", Msg: "b", Warning: true}}).Err(); err != nil {
+		t.Errorf("warnings returned as error: %v", err)
+	}
+}
+
+func TestValidateReferences(t *testing.T) {
+	cfg := &config.Config{
+		Services: []*config.ServiceConfig{
+			{
+				Name:      "svc-0",
+				Admission: "lockout-0",
+				Handler:   &config.HandlerConfig{Type: "http", Chain: "chain-0", Auther: "auther-0"},
+			},
+			{
+				Name:    "svc-1",
+				Limiter: "limiter-0",
+				Handler: &config.HandlerConfig{Type: "http", ChainGroup: &config.ChainGroupConfig{Chains: []string{"chain-0", "chain-1"}}},
+			},
+		},
+		Chains: []*config.ChainConfig{
+			{Name: "chain-0", Hops: []*config.HopConfig{{Name: "hop-0"}, {Name: "hop-1"}}},
+		},
+		Hops: []*config.HopConfig{
+			{Name: "hop-0", Nodes: []*config.NodeConfig{{Name: "node-0", Bypass: "bypass-0"}}},
+		},
+		Authers: []*config.AutherConfig{
+			{Name: "auther-0", Lockout: &config.LockoutConfig{Admission: "lockout-0"}},
+			{Name: "auther-0"},
+		},
+	}
+
+	want := map[string]string{
+		"authers[1].name":                          "duplicate auther name auther-0",
+		"chains[0].hops[1].name":                   "hop hop-1 not found",
+		"hops[0].nodes[0].bypass":                  "bypass bypass-0 not found",
+		"services[1].limiter":                      "limiter limiter-0 not found",
+		"services[1].handler.chainGroup.chains[1]": "chain chain-1 not found",
+	}
+
+	errs := Validate(cfg)
+	for _, err := range errs {
+		msg, ok := want[err.Path]
+		if !ok {
+			t.Errorf("unexpected error %v", err)
+			continue
+		}
+		if msg != err.Msg {
+			t.Errorf("%s: got %q, want %q", err.Path, err.Msg, msg)
+		}
+		delete(want, err.Path)
+	}
+	for path := range want {
+		t.Errorf("%s is not reported", path)
+	}
+
+	if errs.Err() == nil {
+		t.Error("no error returned")
+	}
+	if err := Errors(nil).Err(); err != nil {
+		t.Errorf("got error %v for no error", err)
+	}
+}
@@ -0,0 +1,84 @@
+package wg
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/go-gost/core/connector"
+	md "github.com/go-gost/core/metadata"
+	ctxvalue "github.com/go-gost/x/ctx"
+	wg_util "github.com/go-gost/x/internal/util/wg"
+	"github.com/go-gost/x/registry"
+)
+
+func init() {
+	registry.ConnectorRegistry().Register("wg", NewConnector)
+}
+
+type wgConnector struct {
+	options connector.Options
+}
+
+func NewConnector(opts ...connector.Option) connector.Connector {
+	options := connector.Options{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &wgConnector{
+		options: options,
+	}
+}
+
+func (c *wgConnector) Init(md md.Metadata) (err error) {
+	return nil
+}
+
+func (c *wgConnector) Connect(ctx context.Context, conn net.Conn, network, address string, opts ...connector.ConnectOption) (net.Conn, error) {
+	log := c.options.Logger.WithFields(map[string]any{
+		"remote":  conn.RemoteAddr().String(),
+		"local":   conn.LocalAddr().String(),
+		"network": network,
+		"address": address,
+		"sid":     string(ctxvalue.SidFromContext(ctx)),
+	})
+	log.Debugf("connect %s/%s", address, network)
+
+	cc, ok := conn.(*wg_util.ClientConn)
+	if !ok {
+		return nil, errors.New("wg: invalid connection")
+	}
+
+	conn, err := cc.Dial(ctx, network, address)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Bind implements connector.Binder.
+func (c *wgConnector) Bind(ctx context.Context, conn net.Conn, network, address string, opts ...connector.BindOption) (net.Listener, error) {
+	log := c.options.Logger.WithFields(map[string]any{
+		"remote":  conn.RemoteAddr().String(),
+		"local":   conn.LocalAddr().String(),
+		"network": network,
+		"address": address,
+	})
+	log.Debugf("bind on %s/%s", address, network)
+
+	cc, ok := conn.(*wg_util.ClientConn)
+	if !ok {
+		return nil, errors.New("wg: invalid connection")
+	}
+
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, errors.New("wg: only TCP bind is supported")
+	}
+
+	return cc.Listen(network, address)
+}
@@ -0,0 +1,281 @@
+// Package conntrack keeps track of the live connections of the services,
+// so they can be inspected and closed at runtime.
+package conntrack
+
+import (
+	"context"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-gost/core/observer/stats"
+)
+
+// Conn is a tracked client connection.
+// It implements stats.Stats, the byte counters are fed by xnet.Pipe
+// while the handler relays the traffic, and by the connection returned by Detach.
+type Conn struct {
+	sid        string
+	service    string
+	clientAddr string
+	start      time.Time
+	closer     io.Closer
+
+	inputBytes  atomic.Uint64
+	outputBytes atomic.Uint64
+	updated     atomic.Bool
+	detached    atomic.Bool
+
+	clientID string
+	host     string
+	dstAddr  string
+	route    string
+	mu       sync.RWMutex
+}
+
+func NewConn(service string, sid string, clientAddr string, closer io.Closer) *Conn {
+	return &Conn{
+		sid:        sid,
+		service:    service,
+		clientAddr: clientAddr,
+		start:      time.Now(),
+		closer:     closer,
+	}
+}
+
+func (c *Conn) SID() string {
+	return c.sid
+}
+
+func (c *Conn) Service() string {
+	return c.service
+}
+
+func (c *Conn) SetClientID(clientID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clientID = clientID
+}
+
+// SetHost sets the target host requested by the client.
+func (c *Conn) SetHost(host string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.host = host
+}
+
+// SetDstAddr sets the address of the established upstream connection.
+func (c *Conn) SetDstAddr(addr string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dstAddr = addr
+}
+
+// SetRoute sets the chain route of the upstream connection.
+func (c *Conn) SetRoute(route string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.route = route
+}
+
+func (c *Conn) Add(kind stats.Kind, n int64) {
+	if c == nil {
+		return
+	}
+	switch kind {
+	case stats.KindInputBytes:
+		c.inputBytes.Add(uint64(n))
+	case stats.KindOutputBytes:
+		c.outputBytes.Add(uint64(n))
+	default:
+		return
+	}
+	c.updated.Store(true)
+}
+
+func (c *Conn) Get(kind stats.Kind) uint64 {
+	if c == nil {
+		return 0
+	}
+	switch kind {
+	case stats.KindInputBytes:
+		return c.inputBytes.Load()
+	case stats.KindOutputBytes:
+		return c.outputBytes.Load()
+	case stats.KindTotalConns, stats.KindCurrentConns:
+		return 1
+	}
+	return 0
+}
+
+func (c *Conn) IsUpdated() bool {
+	return c.updated.Swap(false)
+}
+
+func (c *Conn) Reset() {
+	c.updated.Store(false)
+	c.inputBytes.Store(0)
+	c.outputBytes.Store(0)
+}
+
+// Close closes the underlying client connection.
+func (c *Conn) Close() error {
+	if c == nil || c.closer == nil {
+		return nil
+	}
+	return c.closer.Close()
+}
+
+// Detached reports whether the connection outlives its handler, see Detach.
+func (c *Conn) Detached() bool {
+	if c == nil {
+		return false
+	}
+	return c.detached.Load()
+}
+
+// Info returns a snapshot of the connection.
+func (c *Conn) Info() ConnInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return ConnInfo{
+		SID:         c.sid,
+		Service:     c.service,
+		ClientAddr:  c.clientAddr,
+		ClientID:    c.clientID,
+		Host:        c.host,
+		DstAddr:     c.dstAddr,
+		Route:       c.route,
+		InputBytes:  c.inputBytes.Load(),
+		OutputBytes: c.outputBytes.Load(),
+		Time:        c.start,
+		Age:         time.Since(c.start).Truncate(time.Millisecond).String(),
+	}
+}
+
+type ConnInfo struct {
+	SID         string    `json:"sid"`
+	Service     string    `json:"service"`
+	ClientAddr  string    `json:"clientAddr"`
+	ClientID    string    `json:"clientID,omitempty"`
+	Host        string    `json:"host,omitempty"`
+	DstAddr     string    `json:"dstAddr,omitempty"`
+	Route       string    `json:"route,omitempty"`
+	InputBytes  uint64    `json:"inputBytes"`
+	OutputBytes uint64    `json:"outputBytes"`
+	Time        time.Time `json:"time"`
+	Age         string    `json:"age"`
+}
+
+// Registry is a set of tracked connections indexed by session ID.
+type Registry struct {
+	conns sync.Map
+}
+
+func (r *Registry) Register(c *Conn) {
+	if c == nil || c.sid == "" {
+		return
+	}
+	r.conns.Store(c.sid, c)
+}
+
+func (r *Registry) Unregister(sid string) {
+	r.conns.Delete(sid)
+}
+
+func (r *Registry) Get(sid string) *Conn {
+	if v, ok := r.conns.Load(sid); ok {
+		return v.(*Conn)
+	}
+	return nil
+}
+
+// List returns the connections of the service ordered by start time,
+// all connections are returned if service is empty.
+func (r *Registry) List(service string) (conns []*Conn) {
+	r.conns.Range(func(key, value any) bool {
+		if c := value.(*Conn); service == "" || c.service == service {
+			conns = append(conns, c)
+		}
+		return true
+	})
+	sort.Slice(conns, func(i, j int) bool {
+		return conns[i].start.Before(conns[j].start)
+	})
+	return
+}
+
+var defaultRegistry = &Registry{}
+
+// Default returns the global connection registry.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+type connKey struct{}
+
+func ContextWithConn(ctx context.Context, c *Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, c)
+}
+
+func ConnFromContext(ctx context.Context) *Conn {
+	v, _ := ctx.Value(connKey{}).(*Conn)
+	return v
+}
+
+// Detach hands the client connection of ctx over to a long-lived session
+// (e.g. a multiplexed tunnel) which keeps it open after the handler returns.
+// The tracked connection stays registered until the returned conn is closed,
+// the traffic through the returned conn is added to its byte counters.
+func Detach(ctx context.Context, c net.Conn) net.Conn {
+	tc := ConnFromContext(ctx)
+	if tc == nil || c == nil {
+		return c
+	}
+	tc.detached.Store(true)
+	return &detachedConn{
+		Conn: c,
+		tc:   tc,
+	}
+}
+
+type detachedConn struct {
+	net.Conn
+	tc   *Conn
+	once sync.Once
+}
+
+func (c *detachedConn) Read(b []byte) (n int, err error) {
+	n, err = c.Conn.Read(b)
+	c.tc.Add(stats.KindInputBytes, int64(n))
+	return
+}
+
+func (c *detachedConn) Write(b []byte) (n int, err error) {
+	n, err = c.Conn.Write(b)
+	c.tc.Add(stats.KindOutputBytes, int64(n))
+	return
+}
+
+func (c *detachedConn) Close() error {
+	c.once.Do(func() {
+		Default().Unregister(c.tc.sid)
+	})
+	return c.Conn.Close()
+}
@@ -0,0 +1,65 @@
+package conntrack
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/go-gost/core/observer/stats"
+)
+
+func TestDetach(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c2.Close()
+
+	r := Default()
+	tc := NewConn("svc", "sid-detach", "127.0.0.1:1234", c1)
+	r.Register(tc)
+	defer r.Unregister(tc.SID())
+
+	if tc.Detached() {
+		t.Fatal("conn is detached before Detach")
+	}
+
+	dc := Detach(ContextWithConn(context.Background(), tc), c1)
+	if !tc.Detached() {
+		t.Fatal("conn is not detached")
+	}
+
+	go func() {
+		c2.Write([]byte("hello"))
+		io.ReadFull(c2, make([]byte, 3))
+	}()
+
+	if _, err := io.ReadFull(dc, make([]byte, 5)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dc.Write([]byte("bye")); err != nil {
+		t.Fatal(err)
+	}
+	if n := tc.Get(stats.KindInputBytes); n != 5 {
+		t.Errorf("input bytes: got %d, want 5", n)
+	}
+	if n := tc.Get(stats.KindOutputBytes); n != 3 {
+		t.Errorf("output bytes: got %d, want 3", n)
+	}
+
+	if r.Get(tc.SID()) == nil {
+		t.Fatal("detached conn is unregistered before it is closed")
+	}
+	dc.Close()
+	if r.Get(tc.SID()) != nil {
+		t.Fatal("detached conn is still registered after it is closed")
+	}
+}
+
+func TestDetachUntracked(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	if dc := Detach(context.Background(), c1); dc != c1 {
+		t.Error("untracked conn is wrapped")
+	}
+}
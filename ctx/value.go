@@ -2,6 +2,7 @@ package ctx
 
 import (
 	"context"
+	"crypto/x509"
 	"net"
 )
 
@@ -90,3 +91,43 @@ func ClientIDFromContext(ctx context.Context) ClientID {
 	v, _ := ctx.Value(clientIDKey{}).(ClientID)
 	return v
 }
+
+type (
+	// TLSInfo is the TLS properties of the client connection obtained from the ClientHello message.
+	TLSInfo struct {
+		ServerName string
+		ALPN       []string
+		// JA3 is the JA3 fingerprint string, not hashed.
+		JA3 string
+		JA4 string
+	}
+	tlsInfoKey struct{}
+)
+
+func ContextWithTLSInfo(ctx context.Context, info *TLSInfo) context.Context {
+	return context.WithValue(ctx, tlsInfoKey{}, info)
+}
+
+func TLSInfoFromContext(ctx context.Context) *TLSInfo {
+	v, _ := ctx.Value(tlsInfoKey{}).(*TLSInfo)
+	return v
+}
+
+type (
+	// PeerCertificateFunc returns the verified certificate of the client,
+	// it is called lazily as the TLS handshake may not be completed when the context is created.
+	PeerCertificateFunc func() *x509.Certificate
+	peerCertificateKey  struct{}
+)
+
+func ContextWithPeerCertificate(ctx context.Context, f PeerCertificateFunc) context.Context {
+	return context.WithValue(ctx, peerCertificateKey{}, f)
+}
+
+// PeerCertificateFromContext returns the verified certificate of the client, nil if not available.
+func PeerCertificateFromContext(ctx context.Context) *x509.Certificate {
+	if f, _ := ctx.Value(peerCertificateKey{}).(PeerCertificateFunc); f != nil {
+		return f()
+	}
+	return nil
+}
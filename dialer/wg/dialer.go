@@ -2,12 +2,18 @@ package wg
 
 import (
 	"context"
+	"fmt"
 	"net"
+	"strings"
+	"sync"
 
 	"github.com/go-gost/core/dialer"
 	"github.com/go-gost/core/logger"
 	md "github.com/go-gost/core/metadata"
+	wg_util "github.com/go-gost/x/internal/util/wg"
 	"github.com/go-gost/x/registry"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun/netstack"
 )
 
 func init() {
@@ -15,8 +21,10 @@ func init() {
 }
 
 type wgDialer struct {
-	md     metadata
-	logger logger.Logger
+	sessions     map[string]*session
+	sessionMutex sync.Mutex
+	md           metadata
+	logger       logger.Logger
 }
 
 func NewDialer(opts ...dialer.Option) dialer.Dialer {
@@ -26,7 +34,8 @@ func NewDialer(opts ...dialer.Option) dialer.Dialer {
 	}
 
 	return &wgDialer{
-		logger: options.Logger,
+		sessions: make(map[string]*session),
+		logger:   options.Logger,
 	}
 }
 
@@ -34,15 +43,112 @@ func (d *wgDialer) Init(md md.Metadata) (err error) {
 	return d.parseMetadata(md)
 }
 
+// Multiplex implements dialer.Multiplexer interface.
+func (d *wgDialer) Multiplex() bool {
+	return true
+}
+
+// Dial establishes the WireGuard tunnel to the peer endpoint addr on a userspace network stack.
+// The tunnel is shared by all the connections to the same endpoint.
 func (d *wgDialer) Dial(ctx context.Context, addr string, opts ...dialer.DialOption) (net.Conn, error) {
-	var options dialer.DialOptions
-	for _, opt := range opts {
-		opt(&options)
+	d.sessionMutex.Lock()
+	defer d.sessionMutex.Unlock()
+
+	s, ok := d.sessions[addr]
+	if s != nil && s.IsClosed() {
+		s.Close()
+		delete(d.sessions, addr) // session is dead
+		ok = false
 	}
+	if !ok {
+		var options dialer.DialOptions
+		for _, opt := range opts {
+			opt(&options)
+		}
+
+		var err error
+		s, err = d.initSession(addr, &options)
+		if err != nil {
+			d.logger.Error(err)
+			return nil, err
+		}
+		d.sessions[addr] = s
+	}
+
+	return wg_util.NewClientConn(s.tnet, len(d.md.dns) > 0, s.localAddr, s.remoteAddr), nil
+}
 
-	conn, err := options.Dialer.Dial(ctx, "tcp", addr)
+func (d *wgDialer) initSession(addr string, options *dialer.DialOptions) (*session, error) {
+	tun, tnet, err := netstack.CreateNetTUN(d.md.addrs, d.md.dns, d.md.mtu)
 	if err != nil {
-		d.logger.Error(err)
+		return nil, err
+	}
+
+	bind := wg_util.NewClientBind(func() (net.Conn, error) {
+		// the bind may be reopened after the dial, so it does not inherit the dial context.
+		return options.Dialer.Dial(context.Background(), "udp", addr)
+	})
+	dev := device.NewDevice(tun, bind, wg_util.NewLogger(d.logger.WithFields(map[string]any{
+		"endpoint": addr,
+	})))
+
+	if err := dev.IpcSet(d.ipcConfig(addr)); err != nil {
+		dev.Close()
+		return nil, err
+	}
+	if err := dev.Up(); err != nil {
+		dev.Close()
+		return nil, err
+	}
+	d.logger.Debugf("wg tunnel to %s is up", addr)
+
+	s := &session{
+		dev:       dev,
+		bind:      bind,
+		tnet:      tnet,
+		localAddr: &net.IPAddr{IP: d.md.addrs[0].AsSlice()},
 	}
-	return conn, err
+	if s.remoteAddr, err = net.ResolveUDPAddr("udp", addr); err != nil {
+		s.remoteAddr = &net.UDPAddr{}
+	}
+	return s, nil
+}
+
+// ipcConfig builds the device configuration in the cross-platform userspace protocol.
+func (d *wgDialer) ipcConfig(endpoint string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "private_key=%s\n", d.md.privateKey)
+	fmt.Fprintf(&b, "public_key=%s\n", d.md.publicKey)
+	if d.md.presharedKey != "" {
+		fmt.Fprintf(&b, "preshared_key=%s\n", d.md.presharedKey)
+	}
+	fmt.Fprintf(&b, "endpoint=%s\n", endpoint)
+	if d.md.keepalive > 0 {
+		fmt.Fprintf(&b, "persistent_keepalive_interval=%d\n", int(d.md.keepalive.Seconds()))
+	}
+	for _, prefix := range d.md.allowedIPs {
+		fmt.Fprintf(&b, "allowed_ip=%s\n", prefix)
+	}
+	return b.String()
+}
+
+type session struct {
+	dev        *device.Device
+	bind       *wg_util.ClientBind
+	tnet       *netstack.Net
+	localAddr  net.Addr
+	remoteAddr net.Addr
+}
+
+func (s *session) IsClosed() bool {
+	select {
+	case <-s.dev.Wait():
+		return true
+	default:
+	}
+	return s.bind.Failed()
+}
+
+func (s *session) Close() {
+	s.dev.Close()
 }
@@ -0,0 +1,153 @@
+package wg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/connector"
+	"github.com/go-gost/core/dialer"
+	wg_connector "github.com/go-gost/x/connector/wg"
+	wg_util "github.com/go-gost/x/internal/util/wg"
+	xlogger "github.com/go-gost/x/logger"
+	xmd "github.com/go-gost/x/metadata"
+	"golang.org/x/crypto/curve25519"
+	"golang.zx2c4.com/wireguard/conn"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun/netstack"
+)
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func (f dialFunc) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	return f(ctx, network, addr)
+}
+
+func genKey(t *testing.T) (private, public []byte) {
+	private = make([]byte, 32)
+	if _, err := rand.Read(private); err != nil {
+		t.Fatal(err)
+	}
+	public, err := curve25519.X25519(private, curve25519.Basepoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestDialer(t *testing.T) {
+	serverPriv, serverPub := genKey(t)
+	clientPriv, clientPub := genKey(t)
+
+	// the peer runs on the standard UDP bind.
+	tun, tnet, err := netstack.CreateNetTUN([]netip.Addr{netip.MustParseAddr("10.9.0.1")}, nil, wg_util.DefaultMTU)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev := device.NewDevice(tun, conn.NewDefaultBind(), wg_util.NewLogger(xlogger.Nop()))
+	defer dev.Close()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := pc.LocalAddr().(*net.UDPAddr).Port
+	pc.Close()
+
+	if err := dev.IpcSet(fmt.Sprintf("private_key=%s\nlisten_port=%d\npublic_key=%s\nallowed_ip=10.9.0.2/32\n",
+		hex.EncodeToString(serverPriv), port, hex.EncodeToString(clientPub))); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := tnet.ListenTCP(&net.TCPAddr{Port: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				io.Copy(c, c)
+			}()
+		}
+	}()
+
+	d := NewDialer(dialer.LoggerOption(xlogger.Nop()))
+	if err := d.Init(xmd.NewMetadata(map[string]any{
+		"privateKey": base64.StdEncoding.EncodeToString(clientPriv),
+		"publicKey":  base64.StdEncoding.EncodeToString(serverPub),
+		"address":    "10.9.0.2/32",
+		"allowedIPs": "10.9.0.0/24",
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	var nd net.Dialer
+	cc, err := d.Dial(context.Background(), fmt.Sprintf("127.0.0.1:%d", port),
+		dialer.NetDialerDialOption(dialFunc(nd.DialContext)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c := wg_connector.NewConnector(connector.LoggerOption(xlogger.Nop()))
+	if err := c.Init(nil); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := c.Connect(ctx, cc, "tcp", "10.9.0.1:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+
+	msg := []byte("hello, wireguard")
+	if _, err := rc.Write(msg); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, len(msg))
+	rc.SetReadDeadline(time.Now().Add(10 * time.Second))
+	if _, err := io.ReadFull(rc, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != string(msg) {
+		t.Errorf("got %q, want %q", buf, msg)
+	}
+}
+
+func TestParseMetadataLists(t *testing.T) {
+	priv, pub := genKey(t)
+
+	d := &wgDialer{}
+	if err := d.parseMetadata(xmd.NewMetadata(map[string]any{
+		"privateKey": base64.StdEncoding.EncodeToString(priv),
+		"publicKey":  base64.StdEncoding.EncodeToString(pub),
+		"address":    []any{"10.9.0.2/32", "fd00::2/128"},
+		"allowedIPs": []any{"10.0.0.0/8", "192.168.0.0/16"},
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(d.md.addrs) != 2 || d.md.addrs[1] != netip.MustParseAddr("fd00::2") {
+		t.Errorf("unexpected addresses %v", d.md.addrs)
+	}
+	if len(d.md.allowedIPs) != 2 || d.md.allowedIPs[1] != netip.MustParsePrefix("192.168.0.0/16") {
+		t.Errorf("unexpected allowed IPs %v", d.md.allowedIPs)
+	}
+}
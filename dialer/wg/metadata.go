@@ -1,23 +1,87 @@
 package wg
 
 import (
+	"errors"
+	"net/netip"
 	"time"
 
-	md "github.com/go-gost/core/metadata"
+	mdata "github.com/go-gost/core/metadata"
+	wg_util "github.com/go-gost/x/internal/util/wg"
+	mdutil "github.com/go-gost/x/metadata/util"
 )
 
 const (
-	dialTimeout = "dialTimeout"
-)
-
-const (
-	defaultDialTimeout = 5 * time.Second
+	defaultAllowedIPs = "0.0.0.0/0,::/0"
 )
 
 type metadata struct {
-	dialTimeout time.Duration
+	privateKey   string
+	publicKey    string
+	presharedKey string
+	addrs        []netip.Addr
+	dns          []netip.Addr
+	allowedIPs   []netip.Prefix
+	mtu          int
+	keepalive    time.Duration
 }
 
-func (d *wgDialer) parseMetadata(md md.Metadata) (err error) {
+func (d *wgDialer) parseMetadata(md mdata.Metadata) (err error) {
+	const (
+		privateKey   = "privateKey"
+		publicKey    = "publicKey"
+		presharedKey = "presharedKey"
+		address      = "address"
+		dns          = "dns"
+		allowedIPs   = "allowedIPs"
+		mtu          = "mtu"
+		keepalive    = "keepalive"
+	)
+
+	if d.md.privateKey, err = wg_util.ParseKey(mdutil.GetString(md, privateKey)); err != nil {
+		return errors.New("wg: invalid private key")
+	}
+	if d.md.publicKey, err = wg_util.ParseKey(mdutil.GetString(md, publicKey, "peer.publicKey")); err != nil {
+		return errors.New("wg: invalid peer public key")
+	}
+	if v := mdutil.GetString(md, presharedKey); v != "" {
+		if d.md.presharedKey, err = wg_util.ParseKey(v); err != nil {
+			return errors.New("wg: invalid preshared key")
+		}
+	}
+
+	// the list value, or the comma separated string value.
+	prefixes, err := wg_util.ParsePrefixes(append(mdutil.GetStrings(md, address), mdutil.GetString(md, address))...)
+	if err != nil {
+		return err
+	}
+	if len(prefixes) == 0 {
+		return errors.New("wg: address is required")
+	}
+	for _, prefix := range prefixes {
+		d.md.addrs = append(d.md.addrs, prefix.Addr())
+	}
+
+	prefixes, err = wg_util.ParsePrefixes(append(mdutil.GetStrings(md, dns), mdutil.GetString(md, dns))...)
+	if err != nil {
+		return err
+	}
+	for _, prefix := range prefixes {
+		d.md.dns = append(d.md.dns, prefix.Addr())
+	}
+
+	ips := append(mdutil.GetStrings(md, allowedIPs, "peer.allowedIPs"), mdutil.GetString(md, allowedIPs, "peer.allowedIPs"))
+	if d.md.allowedIPs, err = wg_util.ParsePrefixes(ips...); err != nil {
+		return err
+	}
+	if len(d.md.allowedIPs) == 0 {
+		d.md.allowedIPs, _ = wg_util.ParsePrefixes(defaultAllowedIPs)
+	}
+
+	d.md.mtu = mdutil.GetInt(md, mtu)
+	if d.md.mtu <= 0 {
+		d.md.mtu = wg_util.DefaultMTU
+	}
+	d.md.keepalive = mdutil.GetDuration(md, keepalive, "peer.keepalive")
+
 	return
 }
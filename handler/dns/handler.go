@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"strings"
 	"time"
@@ -42,6 +43,7 @@ func init() {
 
 type dnsHandler struct {
 	hop        hop.Hop
+	routes     []*dnsRoute
 	exchangers map[string]exchanger.Exchanger
 	cache      *resolver_util.Cache
 	hostMapper hosts.HostMapper
@@ -92,12 +94,7 @@ func (h *dnsHandler) Init(md md.Metadata) (err error) {
 		if addr == "" {
 			continue
 		}
-		ex, err := exchanger.NewExchanger(
-			addr,
-			exchanger.RouterOption(h.options.Router),
-			exchanger.TimeoutOption(h.md.timeout),
-			exchanger.LoggerOption(log),
-		)
+		ex, err := h.newExchanger(addr, log)
 		if err != nil {
 			log.Warnf("parse %s: %v", addr, err)
 			continue
@@ -106,12 +103,7 @@ func (h *dnsHandler) Init(md md.Metadata) (err error) {
 	}
 
 	if len(h.exchangers) == 0 {
-		ex, err := exchanger.NewExchanger(
-			defaultNameserver,
-			exchanger.RouterOption(h.options.Router),
-			exchanger.TimeoutOption(h.md.timeout),
-			exchanger.LoggerOption(log),
-		)
+		ex, err := h.newExchanger(defaultNameserver, log)
 		log.Warnf("resolver not found, use default %s", defaultNameserver)
 		if err != nil {
 			return err
@@ -119,6 +111,15 @@ func (h *dnsHandler) Init(md md.Metadata) (err error) {
 		h.exchangers["default"] = ex
 	}
 
+	for i, rule := range h.md.routes {
+		route, err := h.parseRoute(i, rule, log)
+		if err != nil {
+			log.Warnf("route %s: %v", rule, err)
+			continue
+		}
+		h.routes = append(h.routes, route)
+	}
+
 	for _, ro := range h.options.Recorders {
 		if ro.Record == xrecorder.RecorderServiceHandler {
 			h.recorder = ro
@@ -129,6 +130,13 @@ func (h *dnsHandler) Init(md md.Metadata) (err error) {
 	return
 }
 
+func (h *dnsHandler) Close() error {
+	if closer, ok := h.md.zone.(io.Closer); ok {
+		closer.Close()
+	}
+	return nil
+}
+
 // Forward implements handler.Forwarder.
 func (h *dnsHandler) Forward(hop hop.Hop) {
 	h.hop = hop
@@ -263,14 +271,37 @@ func (h *dnsHandler) request(ctx context.Context, msg []byte, ro *xrecorder.Hand
 	if h.options.Bypass != nil && mq.Question[0].Qclass == dns.ClassINET {
 		if h.options.Bypass.Contains(context.Background(), "udp", strings.Trim(mq.Question[0].Name, "."), bypass.WithService(h.options.Service)) {
 			log.Debug("bypass: ", mq.Question[0].Name)
+			ro.DNS.Reason = "bypass"
 			mr = (&dns.Msg{}).SetReply(&mq)
 			b := bufpool.Get(h.md.bufferSize)
 			return mr.PackBuffer(b)
 		}
 	}
 
+	if h.md.block != nil && mq.Question[0].Qclass == dns.ClassINET {
+		name := strings.Trim(mq.Question[0].Name, ".")
+		if h.md.block.Contains(ctx, "udp", name, bypass.WithService(h.options.Service)) {
+			log.Debug("block: ", mq.Question[0].Name)
+			ro.DNS.Blocked = true
+			ro.DNS.Reason = fmt.Sprintf("block: %s", name)
+			mr = h.blockReply(&mq)
+			b := bufpool.Get(h.md.bufferSize)
+			return mr.PackBuffer(b)
+		}
+	}
+
+	if h.md.zone != nil {
+		if mr = h.md.zone.Lookup(ctx, &mq); mr != nil {
+			log.Debugf("hit zone: %s", mq.Question[0].String())
+			ro.DNS.Reason = "zone"
+			b := bufpool.Get(h.md.bufferSize)
+			return mr.PackBuffer(b)
+		}
+	}
+
 	mr = h.lookupHosts(ctx, &mq, log)
 	if mr != nil {
+		ro.DNS.Reason = "hosts"
 		b := bufpool.Get(h.md.bufferSize)
 		return mr.PackBuffer(b)
 	}
@@ -283,6 +314,7 @@ func (h *dnsHandler) request(ctx context.Context, msg []byte, ro *xrecorder.Hand
 			mr.Id = mq.Id
 			if int32(ttl.Seconds()) > 0 {
 				ro.DNS.Cached = true
+				mr = h.filterReply(ctx, &mq, mr, ro, log)
 
 				log.Debugf("message %d (cached): %s", mq.Id, mq.Question[0].String())
 				b := bufpool.Get(h.md.bufferSize)
@@ -291,13 +323,14 @@ func (h *dnsHandler) request(ctx context.Context, msg []byte, ro *xrecorder.Hand
 		}
 	}
 
-	ex := h.selectExchanger(ctx, strings.Trim(mq.Question[0].Name, "."))
+	ex := h.selectExchanger(ctx, strings.Trim(mq.Question[0].Name, "."), log)
 	if ex == nil {
 		return nil, fmt.Errorf("exchange not found for %s", mq.Question[0].Name)
 	}
 	ro.Host = ex.String()
 
 	if mr != nil && h.md.async {
+		mr = h.filterReply(ctx, &mq, mr, ro, log)
 		b := bufpool.Get(h.md.bufferSize)
 		reply, err := mr.PackBuffer(b)
 		if err != nil {
@@ -318,6 +351,7 @@ func (h *dnsHandler) request(ctx context.Context, msg []byte, ro *xrecorder.Hand
 	if err != nil {
 		return nil, err
 	}
+	mr = h.filterReply(ctx, &mq, mr, ro, log)
 
 	b := bufpool.Get(h.md.bufferSize)
 	return mr.PackBuffer(b)
@@ -399,11 +433,29 @@ func (h *dnsHandler) lookupHosts(ctx context.Context, r *dns.Msg, log logger.Log
 	return
 }
 
-func (h *dnsHandler) selectExchanger(ctx context.Context, addr string) exchanger.Exchanger {
-	if h.hop == nil {
+func (h *dnsHandler) newExchanger(addr string, log logger.Logger) (exchanger.Exchanger, error) {
+	return exchanger.NewExchanger(
+		addr,
+		exchanger.RouterOption(h.options.Router),
+		exchanger.TimeoutOption(h.md.timeout),
+		exchanger.LoggerOption(log),
+	)
+}
+
+func (h *dnsHandler) selectExchanger(ctx context.Context, addr string, log logger.Logger) exchanger.Exchanger {
+	hp := h.hop
+	for i, route := range h.routes {
+		if route.Match(addr) {
+			log.Debugf("route %d: %s", i, addr)
+			hp = route.hop
+			break
+		}
+	}
+
+	if hp == nil {
 		return nil
 	}
-	node := h.hop.Select(ctx, hop.AddrSelectOption(addr))
+	node := hp.Select(ctx, hop.AddrSelectOption(addr))
 	if node == nil {
 		return nil
 	}
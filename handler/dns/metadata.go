@@ -5,8 +5,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-gost/core/bypass"
+	"github.com/go-gost/core/logger"
 	mdata "github.com/go-gost/core/metadata"
+	xbypass "github.com/go-gost/x/bypass"
+	"github.com/go-gost/x/internal/loader"
 	mdutil "github.com/go-gost/x/metadata/util"
+	"github.com/go-gost/x/registry"
+	"github.com/go-gost/x/resolver/zone"
 )
 
 const (
@@ -23,6 +29,15 @@ type metadata struct {
 	dns        []string
 	bufferSize int
 	async      bool
+	// local authoritative zones
+	zone zone.Zone
+	// per-domain nameservers, in the form of "DOMAIN[,DOMAIN...] NAMESERVER[,NAMESERVER...]"
+	routes []string
+	// block matches the queried names and the answers to be blocked
+	block         bypass.Bypass
+	blockResponse string
+	// blockAnswer enables the block list checking for the answers (IP and CNAME target) of the upstream.
+	blockAnswer bool
 }
 
 func (h *dnsHandler) parseMetadata(md mdata.Metadata) (err error) {
@@ -34,6 +49,12 @@ func (h *dnsHandler) parseMetadata(md mdata.Metadata) (err error) {
 		dns         = "dns"
 		bufferSize  = "bufferSize"
 		async       = "async"
+
+		routes        = "dns.routes"
+		block         = "block"
+		blockMatchers = "block.matchers"
+		blockResponse = "block.response"
+		blockAnswer   = "block.answer"
 	)
 
 	h.md.readTimeout = mdutil.GetDuration(md, readTimeout)
@@ -60,5 +81,84 @@ func (h *dnsHandler) parseMetadata(md mdata.Metadata) (err error) {
 	}
 	h.md.async = mdutil.GetBool(md, async)
 
+	h.md.zone = h.parseZone(md)
+
+	h.md.routes = mdutil.GetStrings(md, routes)
+
+	h.md.block = registry.BypassRegistry().Get(mdutil.GetString(md, block))
+	if h.md.block == nil {
+		if matchers := mdutil.GetStrings(md, blockMatchers); len(matchers) > 0 {
+			h.md.block = xbypass.NewBypass(
+				xbypass.MatchersOption(matchers),
+				xbypass.LoggerOption(logger.Default().WithFields(map[string]any{
+					"kind":   "bypass",
+					"bypass": "@block",
+				})),
+			)
+		}
+	}
+	h.md.blockResponse = strings.ToLower(mdutil.GetString(md, blockResponse))
+	h.md.blockAnswer = mdutil.GetBool(md, blockAnswer)
+
 	return
 }
+
+func (h *dnsHandler) parseZone(md mdata.Metadata) zone.Zone {
+	const (
+		zoneRecords       = "zone.records"
+		zoneFile          = "zone.file"
+		zoneRedisAddr     = "zone.redis.addr"
+		zoneRedisDB       = "zone.redis.db"
+		zoneRedisUsername = "zone.redis.username"
+		zoneRedisPassword = "zone.redis.password"
+		zoneRedisKey      = "zone.redis.key"
+		zoneRedisType     = "zone.redis.type"
+		zoneHTTPURL       = "zone.http.url"
+		zoneHTTPTimeout   = "zone.http.timeout"
+		zoneReload        = "zone.reload"
+	)
+
+	records := mdutil.GetStrings(md, zoneRecords)
+	file := mdutil.GetString(md, zoneFile)
+	redisAddr := mdutil.GetString(md, zoneRedisAddr)
+	httpURL := mdutil.GetString(md, zoneHTTPURL)
+	if len(records) == 0 && file == "" && redisAddr == "" && httpURL == "" {
+		return nil
+	}
+
+	opts := []zone.Option{
+		zone.RecordsOption(records),
+		zone.ReloadPeriodOption(mdutil.GetDuration(md, zoneReload)),
+		zone.LoggerOption(logger.Default().WithFields(map[string]any{
+			"kind":    "zone",
+			"service": h.options.Service,
+		})),
+	}
+	if file != "" {
+		opts = append(opts, zone.FileLoaderOption(loader.FileLoader(file)))
+	}
+	if redisAddr != "" {
+		redisOpts := []loader.RedisLoaderOption{
+			loader.DBRedisLoaderOption(mdutil.GetInt(md, zoneRedisDB)),
+			loader.UsernameRedisLoaderOption(mdutil.GetString(md, zoneRedisUsername)),
+			loader.PasswordRedisLoaderOption(mdutil.GetString(md, zoneRedisPassword)),
+			loader.KeyRedisLoaderOption(mdutil.GetString(md, zoneRedisKey)),
+		}
+		switch mdutil.GetString(md, zoneRedisType) {
+		case "set": // redis set
+			opts = append(opts, zone.RedisLoaderOption(loader.RedisSetLoader(redisAddr, redisOpts...)))
+		case "string": // redis string
+			opts = append(opts, zone.RedisLoaderOption(loader.RedisStringLoader(redisAddr, redisOpts...)))
+		default: // redis list
+			opts = append(opts, zone.RedisLoaderOption(loader.RedisListLoader(redisAddr, redisOpts...)))
+		}
+	}
+	if httpURL != "" {
+		opts = append(opts, zone.HTTPLoaderOption(loader.HTTPLoader(
+			httpURL,
+			loader.TimeoutHTTPLoaderOption(mdutil.GetDuration(md, zoneHTTPTimeout)),
+		)))
+	}
+
+	return zone.NewZone(opts...)
+}
@@ -0,0 +1,152 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/go-gost/core/bypass"
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/hop"
+	"github.com/go-gost/core/logger"
+	xhop "github.com/go-gost/x/hop"
+	"github.com/go-gost/x/internal/matcher"
+	xrecorder "github.com/go-gost/x/recorder"
+	"github.com/miekg/dns"
+)
+
+const (
+	// blockTTL is the TTL of the answers in the block response.
+	blockTTL = 60
+)
+
+// dnsRoute forwards the queries of the matched domains to the dedicated nameservers.
+type dnsRoute struct {
+	domainMatcher   matcher.Matcher
+	wildcardMatcher matcher.Matcher
+	hop             hop.Hop
+}
+
+func (r *dnsRoute) Match(name string) bool {
+	return r.domainMatcher.Match(name) || r.wildcardMatcher.Match(name)
+}
+
+// parseRoute parses the route rule in the form of "DOMAIN[,DOMAIN...] NAMESERVER[,NAMESERVER...]",
+// the domain can be a plain domain (example.com), a domain with dot prefix (.example.com)
+// which matches the domain and its subdomains, or a wildcard pattern (*.example.com).
+func (h *dnsHandler) parseRoute(index int, rule string, log logger.Logger) (*dnsRoute, error) {
+	ss := strings.Fields(rule)
+	if len(ss) != 2 {
+		return nil, errors.New("invalid route")
+	}
+
+	var domains, wildcards []string
+	for _, s := range strings.Split(ss[0], ",") {
+		s = strings.TrimSuffix(strings.TrimSpace(s), ".")
+		if strings.Trim(s, ".") == "" {
+			continue
+		}
+		if strings.ContainsAny(s, "*?") {
+			wildcards = append(wildcards, s)
+			continue
+		}
+		domains = append(domains, s)
+	}
+	if len(domains) == 0 && len(wildcards) == 0 {
+		return nil, errors.New("domain not specified")
+	}
+
+	var nodes []*chain.Node
+	for _, addr := range strings.Split(ss[1], ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		ex, err := h.newExchanger(addr, log)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %v", addr, err)
+		}
+		name := fmt.Sprintf("route-%d-%d", index, len(nodes))
+		h.exchangers[name] = ex
+		nodes = append(nodes, chain.NewNode(name, addr))
+	}
+	if len(nodes) == 0 {
+		return nil, errors.New("nameserver not specified")
+	}
+
+	return &dnsRoute{
+		domainMatcher:   matcher.DomainMatcher(domains),
+		wildcardMatcher: matcher.WildcardMatcher(wildcards),
+		hop: xhop.NewHop(
+			xhop.NodeOption(nodes...),
+			xhop.LoggerOption(log),
+		),
+	}, nil
+}
+
+// blockReply creates the reply for the blocked query according to the block.response option:
+// nxdomain (default), zero (0.0.0.0 or :: for A/AAAA queries), nodata or refused.
+func (h *dnsHandler) blockReply(mq *dns.Msg) *dns.Msg {
+	mr := (&dns.Msg{}).SetReply(mq)
+
+	q := mq.Question[0]
+	hdr := dns.RR_Header{
+		Name:   q.Name,
+		Rrtype: q.Qtype,
+		Class:  dns.ClassINET,
+		Ttl:    blockTTL,
+	}
+
+	switch h.md.blockResponse {
+	case "zero", "0.0.0.0":
+		switch q.Qtype {
+		case dns.TypeA:
+			mr.Answer = append(mr.Answer, &dns.A{Hdr: hdr, A: net.IPv4zero})
+		case dns.TypeAAAA:
+			mr.Answer = append(mr.Answer, &dns.AAAA{Hdr: hdr, AAAA: net.IPv6zero})
+		}
+	case "nodata":
+	case "refused":
+		mr.Rcode = dns.RcodeRefused
+	default:
+		mr.Rcode = dns.RcodeNameError
+	}
+
+	return mr
+}
+
+// filterReply replaces the reply by the block response
+// if any of the answers (IP address or CNAME target) is in the block list.
+func (h *dnsHandler) filterReply(ctx context.Context, mq *dns.Msg, mr *dns.Msg, ro *xrecorder.HandlerRecorderObject, log logger.Logger) *dns.Msg {
+	if h.md.block == nil || !h.md.blockAnswer || mr == nil {
+		return mr
+	}
+
+	for _, rr := range mr.Answer {
+		var v string
+		switch rr := rr.(type) {
+		case *dns.A:
+			v = rr.A.String()
+		case *dns.AAAA:
+			v = rr.AAAA.String()
+		case *dns.CNAME:
+			v = strings.Trim(rr.Target, ".")
+		default:
+			continue
+		}
+
+		if h.md.block.Contains(ctx, "udp", v, bypass.WithService(h.options.Service)) {
+			log.Debugf("block answer: %s -> %s", mq.Question[0].Name, v)
+			ro.DNS.Blocked = true
+			ro.DNS.Reason = fmt.Sprintf("block answer: %s", v)
+
+			reply := h.blockReply(mq)
+			reply.Id = mr.Id
+			return reply
+		}
+	}
+
+	return mr
+}
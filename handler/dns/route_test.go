@@ -0,0 +1,199 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/hop"
+	xbypass "github.com/go-gost/x/bypass"
+	xhop "github.com/go-gost/x/hop"
+	resolver_util "github.com/go-gost/x/internal/util/resolver"
+	xlogger "github.com/go-gost/x/logger"
+	xrecorder "github.com/go-gost/x/recorder"
+	"github.com/go-gost/x/resolver/exchanger"
+	"github.com/miekg/dns"
+)
+
+// testExchanger answers the A queries with the address ip.
+type testExchanger struct {
+	name string
+	ip   net.IP
+	n    int
+}
+
+func (ex *testExchanger) Exchange(ctx context.Context, msg []byte) ([]byte, error) {
+	ex.n++
+
+	mq := dns.Msg{}
+	if err := mq.Unpack(msg); err != nil {
+		return nil, err
+	}
+	mr := (&dns.Msg{}).SetReply(&mq)
+	mr.Answer = append(mr.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: mq.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+		A:   ex.ip,
+	})
+	return mr.Pack()
+}
+
+func (ex *testExchanger) String() string {
+	return ex.name
+}
+
+// newTestHandler creates the handler with the default nameserver and the route rules,
+// all the nameservers are replaced by the test exchangers named by the nameserver address.
+func newTestHandler(t *testing.T, routes ...string) *dnsHandler {
+	h := &dnsHandler{
+		exchangers: make(map[string]exchanger.Exchanger),
+		cache:      resolver_util.NewCache().WithLogger(xlogger.Nop()),
+	}
+	h.md.bufferSize = defaultBufferSize
+
+	h.hop = xhop.NewHop(xhop.NodeOption(chain.NewNode("target-0", "udp://127.0.0.1:53")))
+	h.exchangers["target-0"] = &testExchanger{name: "udp://127.0.0.1:53", ip: net.IPv4(10, 0, 0, 1)}
+
+	for i, rule := range routes {
+		route, err := h.parseRoute(i, rule, xlogger.Nop())
+		if err != nil {
+			t.Fatalf("route %s: %v", rule, err)
+		}
+		h.routes = append(h.routes, route)
+	}
+	for _, route := range h.routes {
+		for _, node := range route.hop.(hop.NodeList).Nodes() {
+			h.exchangers[node.Name] = &testExchanger{name: node.Addr, ip: net.IPv4(10, 0, 1, 1)}
+		}
+	}
+
+	return h
+}
+
+func query(t *testing.T, h *dnsHandler, name string, qtype uint16) (*dns.Msg, *xrecorder.HandlerRecorderObject) {
+	mq := (&dns.Msg{}).SetQuestion(dns.Fqdn(name), qtype)
+	b, err := mq.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ro := &xrecorder.HandlerRecorderObject{}
+	reply, err := h.request(context.Background(), b, ro, xlogger.Nop())
+	if err != nil {
+		t.Fatalf("query %s: %v", name, err)
+	}
+
+	mr := &dns.Msg{}
+	if err := mr.Unpack(reply); err != nil {
+		t.Fatal(err)
+	}
+	return mr, ro
+}
+
+func TestRoute(t *testing.T) {
+	h := newTestHandler(t,
+		"example.com,*.example.org udp://1.1.1.1:53",
+		".example.net udp://8.8.8.8:53,udp://8.8.4.4:53",
+	)
+
+	tests := []struct {
+		name string
+		host string
+	}{
+		{name: "example.com", host: "udp://1.1.1.1:53"},
+		{name: "www.example.com", host: "udp://127.0.0.1:53"},
+		{name: "www.example.org", host: "udp://1.1.1.1:53"},
+		{name: "example.net", host: "udp://8.8.8.8:53"},
+		{name: "a.b.example.net", host: "udp://8.8.8.8:53"},
+		// the queries not matched by any route fall back to the default nameserver.
+		{name: "example.io", host: "udp://127.0.0.1:53"},
+		{name: "example.org", host: "udp://127.0.0.1:53"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, ro := query(t, h, tt.name, dns.TypeA)
+			if ro.Host != tt.host {
+				t.Errorf("exchanged with %s, want %s", ro.Host, tt.host)
+			}
+			if mr.Rcode != dns.RcodeSuccess || len(mr.Answer) != 1 {
+				t.Errorf("unexpected reply: %v", mr)
+			}
+		})
+	}
+}
+
+func TestBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		answer   bool
+		host     string
+		qtype    uint16
+		rcode    int
+		answers  []string
+		blocked  bool
+		reason   string
+	}{
+		{name: "nxdomain", host: "ads.example.com", qtype: dns.TypeA,
+			rcode: dns.RcodeNameError, blocked: true, reason: "block: ads.example.com"},
+		{name: "zero", response: "zero", host: "ads.example.com", qtype: dns.TypeA,
+			answers: []string{"0.0.0.0"}, blocked: true, reason: "block: ads.example.com"},
+		{name: "zero aaaa", response: "zero", host: "ads.example.com", qtype: dns.TypeAAAA,
+			answers: []string{"::"}, blocked: true, reason: "block: ads.example.com"},
+		{name: "nodata", response: "nodata", host: "ads.example.com", qtype: dns.TypeA,
+			blocked: true, reason: "block: ads.example.com"},
+		{name: "refused", response: "refused", host: "ads.example.com", qtype: dns.TypeA,
+			rcode: dns.RcodeRefused, blocked: true, reason: "block: ads.example.com"},
+		// the answer of the upstream is in the block list.
+		{name: "filtered", answer: true, host: "www.example.com", qtype: dns.TypeA,
+			rcode: dns.RcodeNameError, blocked: true, reason: "block answer: 10.0.0.1"},
+		{name: "filtered zero", response: "zero", answer: true, host: "www.example.com", qtype: dns.TypeA,
+			answers: []string{"0.0.0.0"}, blocked: true, reason: "block answer: 10.0.0.1"},
+		// the answers are not checked if block.answer is disabled.
+		{name: "not filtered", host: "www.example.com", qtype: dns.TypeA,
+			answers: []string{"10.0.0.1"}},
+	}
+	block := xbypass.NewBypass(xbypass.MatchersOption([]string{"ads.example.com", "10.0.0.1"}))
+	// the matchers are loaded in the background.
+	for i := 0; !block.Contains(context.Background(), "udp", "ads.example.com"); i++ {
+		if i >= 100 {
+			t.Fatal("block list is not loaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			h.md.block = block
+			h.md.blockResponse = tt.response
+			h.md.blockAnswer = tt.answer
+
+			mr, ro := query(t, h, tt.host, tt.qtype)
+			if mr.Rcode != tt.rcode {
+				t.Errorf("rcode %s, want %s", dns.RcodeToString[mr.Rcode], dns.RcodeToString[tt.rcode])
+			}
+			var answers []string
+			for _, rr := range mr.Answer {
+				switch rr := rr.(type) {
+				case *dns.A:
+					answers = append(answers, rr.A.String())
+				case *dns.AAAA:
+					answers = append(answers, rr.AAAA.String())
+				}
+			}
+			if len(answers) != len(tt.answers) || (len(answers) > 0 && answers[0] != tt.answers[0]) {
+				t.Errorf("answers %v, want %v", answers, tt.answers)
+			}
+			if ro.DNS.Blocked != tt.blocked || ro.DNS.Reason != tt.reason {
+				t.Errorf("recorder blocked %v reason %q, want %v %q", ro.DNS.Blocked, ro.DNS.Reason, tt.blocked, tt.reason)
+			}
+
+			// the blocked queries are not sent to the upstream.
+			if ex := h.exchangers["target-0"].(*testExchanger); tt.host == "ads.example.com" && ex.n > 0 {
+				t.Errorf("blocked query is exchanged")
+			}
+		})
+	}
+}
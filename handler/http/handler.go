@@ -559,19 +559,11 @@ func (h *httpHandler) proxyRoundTrip(ctx context.Context, rw io.ReadWriteCloser,
 		return
 	}
 
+	capture := sniffing.NewHTTPCapture(h.recorder.Recorder, h.recorder.Options, req)
 	var reqBody *xhttp.Body
-	if opts := h.recorder.Options; opts != nil && opts.HTTPBody {
-		if req.Body != nil {
-			bodySize := opts.MaxBodySize
-			if bodySize <= 0 {
-				bodySize = sniffing.DefaultBodySize
-			}
-			if bodySize > sniffing.MaxBodySize {
-				bodySize = sniffing.MaxBodySize
-			}
-			reqBody = xhttp.NewBody(req.Body, bodySize)
-			req.Body = reqBody
-		}
+	if bodySize := capture.RequestBodySize(); bodySize > 0 {
+		reqBody = xhttp.NewBody(req.Body, bodySize)
+		req.Body = reqBody
 	}
 
 	ctx = ictx.ContextWithRecorderObject(ctx, ro)
@@ -614,14 +606,7 @@ func (h *httpHandler) proxyRoundTrip(ctx context.Context, rw io.ReadWriteCloser,
 	}
 
 	var respBody *xhttp.Body
-	if opts := h.recorder.Options; opts != nil && opts.HTTPBody {
-		bodySize := opts.MaxBodySize
-		if bodySize <= 0 {
-			bodySize = sniffing.DefaultBodySize
-		}
-		if bodySize > sniffing.MaxBodySize {
-			bodySize = sniffing.MaxBodySize
-		}
+	if bodySize := capture.ResponseBodySize(resp); bodySize > 0 {
 		respBody = xhttp.NewBody(resp.Body, bodySize)
 		resp.Body = respBody
 	}
@@ -842,6 +827,11 @@ func (h *httpHandler) basicProxyAuth(proxyAuth string) (username, password strin
 		return
 	}
 
+	// the bearer token is passed to the authenticator as the password.
+	if token, found := strings.CutPrefix(proxyAuth, "Bearer "); found {
+		return "", strings.TrimSpace(token), true
+	}
+
 	if !strings.HasPrefix(proxyAuth, "Basic ") {
 		return
 	}
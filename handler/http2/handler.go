@@ -364,6 +364,11 @@ func (h *http2Handler) basicProxyAuth(proxyAuth string) (username, password stri
 		return
 	}
 
+	// the bearer token is passed to the authenticator as the password.
+	if token, found := strings.CutPrefix(proxyAuth, "Bearer "); found {
+		return "", strings.TrimSpace(token), true
+	}
+
 	if !strings.HasPrefix(proxyAuth, "Basic ") {
 		return
 	}
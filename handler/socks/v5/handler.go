@@ -6,6 +6,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/go-gost/core/auth"
 	"github.com/go-gost/core/handler"
 	"github.com/go-gost/core/limiter"
 	"github.com/go-gost/core/limiter/traffic"
@@ -14,6 +15,7 @@ import (
 	"github.com/go-gost/core/observer/stats"
 	"github.com/go-gost/core/recorder"
 	"github.com/go-gost/gosocks5"
+	xauth "github.com/go-gost/x/auth"
 	xctx "github.com/go-gost/x/ctx"
 	"github.com/go-gost/x/internal/util/socks"
 	stats_util "github.com/go-gost/x/internal/util/stats"
@@ -36,7 +38,7 @@ func init() {
 }
 
 type socks5Handler struct {
-	selector gosocks5.Selector
+	selector *serverSelector
 	md       metadata
 	options  handler.Options
 	stats    *stats_util.HandlerStats
@@ -155,7 +157,15 @@ func (h *socks5Handler) Handle(ctx context.Context, conn net.Conn, opts ...handl
 
 	conn.SetReadDeadline(time.Now().Add(h.md.readTimeout))
 
-	sc := gosocks5.ServerConn(conn, h.selector)
+	selector := h.selector
+	if v, ok := h.options.Auther.(xauth.CertAuthenticator); ok && xctx.PeerCertificateFromContext(ctx) != nil {
+		// the client is authenticated by the TLS client certificate.
+		if id, ok := v.AuthenticateCert(ctx, auth.WithService(h.options.Service)); ok {
+			selector = selector.withClientID(id)
+		}
+	}
+
+	sc := gosocks5.ServerConn(conn, selector)
 	req, err := gosocks5.ReadRequest(sc)
 	if err != nil {
 		log.Error(err)
@@ -19,6 +19,17 @@ type serverSelector struct {
 	TLSConfig     *tls.Config
 	logger        logger.Logger
 	noTLS         bool
+	// clientID is the ID of the client authenticated before the method negotiation.
+	clientID string
+}
+
+// withClientID returns a copy of the selector for the client that has already been authenticated,
+// no further authentication is required.
+func (s *serverSelector) withClientID(clientID string) *serverSelector {
+	ss := *s
+	ss.Authenticator = nil
+	ss.clientID = clientID
+	return &ss
 }
 
 func (selector *serverSelector) Methods() []uint8 {
@@ -68,7 +79,7 @@ func (s *serverSelector) OnSelected(method uint8, conn net.Conn) (string, net.Co
 		}
 		s.logger.Trace(req)
 
-		var id string
+		id := s.clientID
 		if s.Authenticator != nil {
 			var ok bool
 			ctx := xctx.ContextWithSrcAddr(context.Background(), conn.RemoteAddr())
@@ -98,5 +109,5 @@ func (s *serverSelector) OnSelected(method uint8, conn net.Conn) (string, net.Co
 	default:
 		return "", nil, gosocks5.ErrBadFormat
 	}
-	return "", conn, nil
+	return s.clientID, conn, nil
 }
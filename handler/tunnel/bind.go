@@ -11,6 +11,7 @@ import (
 	"github.com/go-gost/core/observer/stats"
 	"github.com/go-gost/core/sd"
 	"github.com/go-gost/relay"
+	"github.com/go-gost/x/conntrack"
 	"github.com/go-gost/x/internal/util/mux"
 	"github.com/google/uuid"
 )
@@ -59,9 +60,12 @@ func (h *tunnelHandler) handleBind(ctx context.Context, conn net.Conn, network,
 	)
 	resp.WriteTo(conn)
 
-	// Upgrade connection to multiplex session.
-	session, err := mux.ClientSession(conn, h.md.muxCfg)
+	// Upgrade connection to multiplex session,
+	// the connection stays tracked as long as the session is alive.
+	dc := conntrack.Detach(ctx, conn)
+	session, err := mux.ClientSession(dc, h.md.muxCfg)
 	if err != nil {
+		dc.Close()
 		return
 	}
 
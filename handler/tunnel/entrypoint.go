@@ -327,19 +327,11 @@ func (ep *entrypoint) httpRoundTrip(ctx context.Context, rw io.ReadWriteCloser,
 	}
 	ro.HTTP.StatusCode = res.StatusCode
 
+	capture := sniffing.NewHTTPCapture(ep.recorder.Recorder, ep.recorder.Options, req)
 	var reqBody *xhttp.Body
-	if opts := ep.recorder.Options; opts != nil && opts.HTTPBody {
-		if req.Body != nil {
-			bodySize := opts.MaxBodySize
-			if bodySize <= 0 {
-				bodySize = sniffing.DefaultBodySize
-			}
-			if bodySize > sniffing.MaxBodySize {
-				bodySize = sniffing.MaxBodySize
-			}
-			reqBody = xhttp.NewBody(req.Body, bodySize)
-			req.Body = reqBody
-		}
+	if bodySize := capture.RequestBodySize(); bodySize > 0 {
+		reqBody = xhttp.NewBody(req.Body, bodySize)
+		req.Body = reqBody
 	}
 
 	if clientIP := xhttp.GetClientIP(req); clientIP != nil {
@@ -381,14 +373,7 @@ func (ep *entrypoint) httpRoundTrip(ctx context.Context, rw io.ReadWriteCloser,
 	}
 
 	var respBody *xhttp.Body
-	if opts := ep.recorder.Options; opts != nil && opts.HTTPBody {
-		bodySize := opts.MaxBodySize
-		if bodySize <= 0 {
-			bodySize = sniffing.DefaultBodySize
-		}
-		if bodySize > sniffing.MaxBodySize {
-			bodySize = sniffing.MaxBodySize
-		}
+	if bodySize := capture.ResponseBodySize(resp); bodySize > 0 {
 		respBody = xhttp.NewBody(resp.Body, bodySize)
 		resp.Body = respBody
 	}
@@ -0,0 +1,297 @@
+package wg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/bypass"
+	"github.com/go-gost/core/common/bufpool"
+	"github.com/go-gost/core/handler"
+	"github.com/go-gost/core/limiter"
+	"github.com/go-gost/core/limiter/traffic"
+	md "github.com/go-gost/core/metadata"
+	"github.com/go-gost/core/observer"
+	"github.com/go-gost/core/observer/stats"
+	"github.com/go-gost/core/recorder"
+	xbypass "github.com/go-gost/x/bypass"
+	xctx "github.com/go-gost/x/ctx"
+	ictx "github.com/go-gost/x/internal/ctx"
+	xnet "github.com/go-gost/x/internal/net"
+	stats_util "github.com/go-gost/x/internal/util/stats"
+	rate_limiter "github.com/go-gost/x/limiter/rate"
+	cache_limiter "github.com/go-gost/x/limiter/traffic/cache"
+	traffic_wrapper "github.com/go-gost/x/limiter/traffic/wrapper"
+	xstats "github.com/go-gost/x/observer/stats"
+	stats_wrapper "github.com/go-gost/x/observer/stats/wrapper"
+	xrecorder "github.com/go-gost/x/recorder"
+	"github.com/go-gost/x/registry"
+)
+
+func init() {
+	registry.HandlerRegistry().Register("wg", NewHandler)
+}
+
+// wgHandler forwards the flows accepted by the wg listener to their original destinations,
+// the client ID is the public key of the peer.
+type wgHandler struct {
+	md       metadata
+	options  handler.Options
+	stats    *stats_util.HandlerStats
+	limiter  traffic.TrafficLimiter
+	cancel   context.CancelFunc
+	recorder recorder.RecorderObject
+}
+
+func NewHandler(opts ...handler.Option) handler.Handler {
+	options := handler.Options{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &wgHandler{
+		options: options,
+	}
+}
+
+func (h *wgHandler) Init(md md.Metadata) (err error) {
+	if err = h.parseMetadata(md); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	if h.options.Observer != nil {
+		h.stats = stats_util.NewHandlerStats(h.options.Service, h.md.observerResetTraffic)
+		go h.observeStats(ctx)
+	}
+
+	if h.options.Limiter != nil {
+		h.limiter = cache_limiter.NewCachedTrafficLimiter(h.options.Limiter,
+			cache_limiter.RefreshIntervalOption(h.md.limiterRefreshInterval),
+			cache_limiter.CleanupIntervalOption(h.md.limiterCleanupInterval),
+			cache_limiter.ScopeOption(limiter.ScopeClient),
+		)
+	}
+
+	for _, ro := range h.options.Recorders {
+		if ro.Record == xrecorder.RecorderServiceHandler {
+			h.recorder = ro
+			break
+		}
+	}
+
+	return
+}
+
+func (h *wgHandler) Handle(ctx context.Context, conn net.Conn, opts ...handler.HandleOption) (err error) {
+	defer conn.Close()
+
+	start := time.Now()
+
+	dstAddr := conn.LocalAddr()
+	clientID := string(xctx.ClientIDFromContext(ctx))
+
+	ro := &xrecorder.HandlerRecorderObject{
+		Network:    dstAddr.Network(),
+		Service:    h.options.Service,
+		RemoteAddr: conn.RemoteAddr().String(),
+		LocalAddr:  conn.LocalAddr().String(),
+		Host:       dstAddr.String(),
+		ClientID:   clientID,
+		SID:        xctx.SidFromContext(ctx).String(),
+		Time:       start,
+	}
+
+	if srcAddr := xctx.SrcAddrFromContext(ctx); srcAddr != nil {
+		ro.ClientAddr = srcAddr.String()
+	}
+
+	log := h.options.Logger.WithFields(map[string]any{
+		"network":  ro.Network,
+		"remote":   conn.RemoteAddr().String(),
+		"local":    conn.LocalAddr().String(),
+		"client":   ro.ClientAddr,
+		"clientID": clientID,
+		"sid":      ro.SID,
+	})
+
+	log.Infof("%s <> %s", conn.RemoteAddr(), dstAddr)
+
+	pStats := xstats.Stats{}
+	conn = stats_wrapper.WrapConn(conn, &pStats)
+
+	defer func() {
+		if err != nil {
+			ro.Err = err.Error()
+		}
+		ro.Duration = time.Since(start)
+		ro.InputBytes = pStats.Get(stats.KindInputBytes)
+		ro.OutputBytes = pStats.Get(stats.KindOutputBytes)
+		if err := ro.Record(ctx, h.recorder.Recorder); err != nil {
+			log.Errorf("record: %v", err)
+		}
+
+		log.WithFields(map[string]any{
+			"duration":    time.Since(start),
+			"inputBytes":  ro.InputBytes,
+			"outputBytes": ro.OutputBytes,
+		}).Infof("%s >< %s", conn.RemoteAddr(), dstAddr)
+	}()
+
+	if clientID == "" {
+		err = errors.New("wg: unknown peer")
+		log.Error(err)
+		return
+	}
+
+	if !h.checkRateLimit(clientID) {
+		return rate_limiter.ErrRateLimit
+	}
+
+	log = log.WithFields(map[string]any{
+		"dst":  dstAddr.String(),
+		"host": dstAddr.String(),
+	})
+	log.Debugf("%s >> %s", conn.RemoteAddr(), dstAddr)
+
+	{
+		rw := traffic_wrapper.WrapReadWriter(
+			h.limiter,
+			conn,
+			clientID,
+			limiter.ServiceOption(h.options.Service),
+			limiter.ScopeOption(limiter.ScopeClient),
+			limiter.NetworkOption(dstAddr.Network()),
+			limiter.AddrOption(dstAddr.String()),
+			limiter.ClientOption(clientID),
+			limiter.SrcOption(conn.RemoteAddr().String()),
+		)
+		if h.options.Observer != nil {
+			pstats := h.stats.Stats(clientID)
+			pstats.Add(stats.KindTotalConns, 1)
+			pstats.Add(stats.KindCurrentConns, 1)
+			defer pstats.Add(stats.KindCurrentConns, -1)
+			rw = stats_wrapper.WrapReadWriter(rw, pstats)
+		}
+
+		conn = xnet.NewReadWriteConn(rw, rw, conn)
+	}
+
+	if h.options.Bypass != nil &&
+		h.options.Bypass.Contains(ctx, dstAddr.Network(), dstAddr.String(), bypass.WithService(h.options.Service)) {
+		log.Debug("bypass: ", dstAddr)
+		return xbypass.ErrBypass
+	}
+
+	var buf bytes.Buffer
+	cc, err := h.options.Router.Dial(ictx.ContextWithBuffer(ctx, &buf), dstAddr.Network(), dstAddr.String())
+	ro.Route = buf.String()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer cc.Close()
+
+	log = log.WithFields(map[string]any{"src": cc.LocalAddr().String(), "dst": cc.RemoteAddr().String()})
+	ro.SrcAddr = cc.LocalAddr().String()
+	ro.DstAddr = cc.RemoteAddr().String()
+
+	t := time.Now()
+	log.Infof("%s <-> %s", conn.RemoteAddr(), dstAddr)
+	if dstAddr.Network() == "udp" {
+		h.pipePacketData(conn, cc)
+	} else {
+		xnet.Pipe(ctx, conn, cc)
+	}
+	log.WithFields(map[string]any{
+		"duration": time.Since(t),
+	}).Infof("%s >-< %s", conn.RemoteAddr(), dstAddr)
+
+	return nil
+}
+
+// Close implements io.Closer interface.
+func (h *wgHandler) Close() error {
+	if h.cancel != nil {
+		h.cancel()
+	}
+	return nil
+}
+
+// pipePacketData relays the datagrams until the flow is idle for the UDP timeout.
+func (h *wgHandler) pipePacketData(conn1, conn2 net.Conn) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	copyPacketData := func(dst, src net.Conn) {
+		defer wg.Done()
+
+		buf := bufpool.Get(h.md.udpBufferSize)
+		defer bufpool.Put(buf)
+
+		for {
+			src.SetReadDeadline(time.Now().Add(h.md.udpTimeout))
+			n, err := src.Read(buf)
+			if err != nil || n == 0 {
+				// wake up the other direction.
+				dst.SetReadDeadline(time.Now())
+				return
+			}
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return
+			}
+			dst.SetReadDeadline(time.Now().Add(h.md.udpTimeout))
+		}
+	}
+
+	go copyPacketData(conn1, conn2)
+	go copyPacketData(conn2, conn1)
+	wg.Wait()
+}
+
+func (h *wgHandler) checkRateLimit(clientID string) bool {
+	if h.options.RateLimiter == nil {
+		return true
+	}
+	if limiter := h.options.RateLimiter.Limiter(clientID); limiter != nil {
+		return limiter.Allow(1)
+	}
+
+	return true
+}
+
+func (h *wgHandler) observeStats(ctx context.Context) {
+	if h.options.Observer == nil {
+		return
+	}
+
+	var events []observer.Event
+
+	ticker := time.NewTicker(h.md.observerPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if len(events) > 0 {
+				if err := h.options.Observer.Observe(ctx, events); err == nil {
+					events = nil
+				}
+				break
+			}
+
+			evs := h.stats.Events()
+			if err := h.options.Observer.Observe(ctx, evs); err != nil {
+				events = evs
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
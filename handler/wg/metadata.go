@@ -0,0 +1,49 @@
+package wg
+
+import (
+	"time"
+
+	mdata "github.com/go-gost/core/metadata"
+	mdutil "github.com/go-gost/x/metadata/util"
+)
+
+const (
+	defaultUDPTimeout    = 30 * time.Second
+	defaultUDPBufferSize = 4096
+)
+
+type metadata struct {
+	udpTimeout    time.Duration
+	udpBufferSize int
+
+	observerPeriod       time.Duration
+	observerResetTraffic bool
+
+	limiterRefreshInterval time.Duration
+	limiterCleanupInterval time.Duration
+}
+
+func (h *wgHandler) parseMetadata(md mdata.Metadata) (err error) {
+	h.md.udpTimeout = mdutil.GetDuration(md, "udpTimeout")
+	if h.md.udpTimeout <= 0 {
+		h.md.udpTimeout = defaultUDPTimeout
+	}
+	h.md.udpBufferSize = mdutil.GetInt(md, "udp.bufferSize", "udpBufferSize")
+	if h.md.udpBufferSize <= 0 {
+		h.md.udpBufferSize = defaultUDPBufferSize
+	}
+
+	h.md.observerPeriod = mdutil.GetDuration(md, "observePeriod", "observer.period", "observer.observePeriod")
+	if h.md.observerPeriod == 0 {
+		h.md.observerPeriod = 5 * time.Second
+	}
+	if h.md.observerPeriod < time.Second {
+		h.md.observerPeriod = time.Second
+	}
+	h.md.observerResetTraffic = mdutil.GetBool(md, "observer.resetTraffic")
+
+	h.md.limiterRefreshInterval = mdutil.GetDuration(md, "limiter.refreshInterval")
+	h.md.limiterCleanupInterval = mdutil.GetDuration(md, "limiter.cleanupInterval")
+
+	return
+}
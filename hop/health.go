@@ -0,0 +1,430 @@
+package hop
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/metrics"
+	net_dialer "github.com/go-gost/x/internal/net/dialer"
+	xmetrics "github.com/go-gost/x/metrics"
+)
+
+const (
+	HealthCheckTCP   = "tcp"
+	HealthCheckTLS   = "tls"
+	HealthCheckHTTP  = "http"
+	HealthCheckChain = "chain"
+)
+
+const (
+	defaultHealthCheckInterval = 30 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+	defaultHealthCheckURL      = "http://www.gstatic.com/generate_204"
+)
+
+var (
+	healthStatus sync.Map
+)
+
+// HealthCheck is the active health check settings of a hop.
+type HealthCheck struct {
+	// Type is the probe type, one of tcp, tls, http and chain.
+	//	tcp: TCP connect to the node address.
+	//	tls: TLS handshake with the node address.
+	//	http: HTTP GET request to URL through the node.
+	//	chain: the node is reached through Chain, then same as http,
+	//	or only the node handshake if URL is empty.
+	Type     string
+	Interval time.Duration
+	Timeout  time.Duration
+	// Rise is the number of consecutive successful probes for an unhealthy node to be considered healthy.
+	Rise int
+	// Fall is the number of consecutive failed probes for a healthy node to be considered unhealthy.
+	Fall  int
+	URL   string
+	Chain chain.Chainer
+}
+
+// NodeHealth is the health status of a node.
+type NodeHealth struct {
+	Node      string    `json:"node"`
+	Addr      string    `json:"addr"`
+	Healthy   bool      `json:"healthy"`
+	Successes int       `json:"successes"`
+	Failures  int       `json:"failures"`
+	Latency   int64     `json:"latency"`
+	LastCheck time.Time `json:"lastCheck"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// HealthStatus returns the health status of the nodes in hop,
+// the bool value reports whether the health check is enabled for the hop.
+func HealthStatus(hop string) ([]NodeHealth, bool) {
+	v, ok := healthStatus.Load(hop)
+	if !ok {
+		return nil, false
+	}
+	return v.(*chainHop).healthStatus(), true
+}
+
+type healthChecker struct {
+	hc     *HealthCheck
+	status map[string]*NodeHealth
+	mu     sync.RWMutex
+}
+
+func newHealthChecker(hc *HealthCheck) *healthChecker {
+	if hc.Interval <= 0 {
+		hc.Interval = defaultHealthCheckInterval
+	}
+	if hc.Timeout <= 0 {
+		hc.Timeout = defaultHealthCheckTimeout
+	}
+	if hc.Rise <= 0 {
+		hc.Rise = 1
+	}
+	if hc.Fall <= 0 {
+		hc.Fall = 1
+	}
+	if hc.URL == "" && hc.Type == HealthCheckHTTP {
+		hc.URL = defaultHealthCheckURL
+	}
+
+	return &healthChecker{
+		hc:     hc,
+		status: make(map[string]*NodeHealth),
+	}
+}
+
+// isHealthy reports whether the node is healthy,
+// a node that has not been checked yet is considered healthy.
+func (c *healthChecker) isHealthy(node *chain.Node) bool {
+	if c == nil {
+		return true
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if st := c.status[healthKey(node)]; st != nil {
+		return st.Healthy
+	}
+	return true
+}
+
+func (p *chainHop) healthStatus() []NodeHealth {
+	c := p.health
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var list []NodeHealth
+	for _, node := range p.Nodes() {
+		if node == nil {
+			continue
+		}
+		st := NodeHealth{
+			Node:    node.Name,
+			Addr:    node.Addr,
+			Healthy: true,
+		}
+		if v := c.status[healthKey(node)]; v != nil {
+			st = *v
+		}
+		list = append(list, st)
+	}
+	return list
+}
+
+func (p *chainHop) runHealthCheck(ctx context.Context) {
+	ticker := time.NewTicker(p.health.hc.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.checkNodes(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *chainHop) checkNodes(ctx context.Context) {
+	nodes := p.Nodes()
+
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(node *chain.Node) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := p.probe(ctx, node)
+			p.updateHealth(node, time.Since(start), err)
+		}(node)
+	}
+	wg.Wait()
+
+	// remove the status of the nodes that no longer exist.
+	keys := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		if node != nil {
+			keys[healthKey(node)] = struct{}{}
+		}
+	}
+
+	c := p.health
+	c.mu.Lock()
+	for k := range c.status {
+		if _, ok := keys[k]; !ok {
+			delete(c.status, k)
+		}
+	}
+	c.mu.Unlock()
+}
+
+func (p *chainHop) updateHealth(node *chain.Node, d time.Duration, err error) {
+	c := p.health
+
+	c.mu.Lock()
+	key := healthKey(node)
+	st := c.status[key]
+	if st == nil {
+		st = &NodeHealth{
+			Node:    node.Name,
+			Addr:    node.Addr,
+			Healthy: true,
+		}
+		c.status[key] = st
+	}
+
+	healthy := st.Healthy
+	st.LastCheck = time.Now()
+	if err == nil {
+		st.Successes++
+		st.Failures = 0
+		st.Latency = d.Milliseconds()
+		st.LastError = ""
+		if !st.Healthy && st.Successes >= c.hc.Rise {
+			st.Healthy = true
+		}
+	} else {
+		st.Failures++
+		st.Successes = 0
+		st.LastError = err.Error()
+		if st.Healthy && st.Failures >= c.hc.Fall {
+			st.Healthy = false
+		}
+	}
+	changed := healthy != st.Healthy
+	healthy = st.Healthy
+	c.mu.Unlock()
+
+	marker := node.Marker()
+	if healthy {
+		if changed && marker != nil {
+			marker.Reset()
+		}
+	} else if marker != nil {
+		// keep the node marked as failed while it is unhealthy.
+		marker.Mark()
+	}
+
+	if changed {
+		if healthy {
+			p.logger.Infof("node %s(%s) is healthy", node.Name, node.Addr)
+		} else {
+			p.logger.Warnf("node %s(%s) is unhealthy: %v", node.Name, node.Addr, err)
+		}
+	} else if err != nil {
+		p.logger.Debugf("health check node %s(%s): %v", node.Name, node.Addr, err)
+	}
+
+	if v := xmetrics.GetGauge(xmetrics.MetricNodeHealthGauge,
+		metrics.Labels{"hop": p.options.name, "node": node.Name}); v != nil {
+		if healthy {
+			v.Set(1)
+		} else {
+			v.Set(0)
+		}
+	}
+	if err == nil {
+		if v := xmetrics.GetObserver(xmetrics.MetricNodeHealthCheckDurationObserver,
+			metrics.Labels{"hop": p.options.name, "node": node.Name}); v != nil {
+			v.Observe(d.Seconds())
+		}
+	}
+}
+
+func (p *chainHop) probe(ctx context.Context, node *chain.Node) error {
+	hc := p.health.hc
+
+	ctx, cancel := context.WithTimeout(ctx, hc.Timeout)
+	defer cancel()
+
+	switch hc.Type {
+	case HealthCheckTLS:
+		return probeTLS(ctx, p.probeDialer(node), node)
+	case HealthCheckHTTP:
+		return probeHTTP(ctx, nil, node, hc.URL)
+	case HealthCheckChain:
+		return probeHTTP(ctx, hc.Chain, node, hc.URL)
+	default:
+		return probeTCP(ctx, p.probeDialer(node), node)
+	}
+}
+
+// probeDialer returns the dialer with the interface, netns and socket options of the node transport,
+// so the probes take the same path as the connections to the node.
+func (p *chainHop) probeDialer(node *chain.Node) *net_dialer.Dialer {
+	d := &net_dialer.Dialer{
+		Log: p.logger,
+	}
+	if tr := node.Options().Transport; tr != nil {
+		if opts := tr.Options(); opts != nil {
+			d.Interface = opts.IfceName
+			d.Netns = opts.Netns
+			if opts.SockOpts != nil {
+				d.Mark = opts.SockOpts.Mark
+			}
+		}
+	}
+	return d
+}
+
+func probeTCP(ctx context.Context, d *net_dialer.Dialer, node *chain.Node) error {
+	conn, err := d.Dial(ctx, "tcp", node.Addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func probeTLS(ctx context.Context, d *net_dialer.Dialer, node *chain.Node) error {
+	cfg := &tls.Config{
+		InsecureSkipVerify: true,
+	}
+	if opts := node.Options().TLS; opts != nil {
+		cfg.ServerName = opts.ServerName
+		cfg.InsecureSkipVerify = !opts.Secure
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName, _, _ = net.SplitHostPort(node.Addr)
+	}
+
+	conn, err := d.Dial(ctx, "tcp", node.Addr)
+	if err != nil {
+		return err
+	}
+	tc := tls.Client(conn, cfg)
+	defer tc.Close()
+
+	return tc.HandshakeContext(ctx)
+}
+
+// probeHTTP sends an HTTP GET request to rawURL through the node.
+// If c is not nil, the node is reached through the chain c.
+func probeHTTP(ctx context.Context, c chain.Chainer, node *chain.Node, rawURL string) error {
+	tr := node.Options().Transport
+	if tr == nil {
+		return errors.New("node transport not available")
+	}
+
+	var cc net.Conn
+	var err error
+	if c != nil {
+		route := c.Route(ctx, "tcp", node.Addr)
+		if route == nil {
+			return errors.New("chain route not available")
+		}
+		cc, err = route.Dial(ctx, "tcp", node.Addr)
+	} else {
+		cc, err = tr.Dial(ctx, node.Addr)
+	}
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		cc.SetDeadline(deadline)
+	}
+
+	conn, err := tr.Handshake(ctx, cc)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if rawURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	conn, err = tr.Connect(ctx, conn, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if u.Scheme == "https" {
+		tc := tls.Client(conn, &tls.Config{
+			ServerName: u.Hostname(),
+		})
+		if err := tc.HandshakeContext(ctx); err != nil {
+			return err
+		}
+		conn = tc
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Connection", "close")
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func healthKey(node *chain.Node) string {
+	return node.Name + "@" + node.Addr
+}
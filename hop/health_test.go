@@ -0,0 +1,169 @@
+package hop
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/connector"
+	"github.com/go-gost/core/dialer"
+	"github.com/go-gost/core/logger"
+	xchain "github.com/go-gost/x/chain"
+	forward_connector "github.com/go-gost/x/connector/forward"
+	tcp_dialer "github.com/go-gost/x/dialer/tcp"
+	xlogger "github.com/go-gost/x/logger"
+)
+
+func newTestHop(hc *HealthCheck, nodes ...*chain.Node) *chainHop {
+	return &chainHop{
+		nodes:  nodes,
+		health: newHealthChecker(hc),
+		logger: xlogger.Nop(),
+	}
+}
+
+func TestUpdateHealth(t *testing.T) {
+	node := chain.NewNode("node-0", "127.0.0.1:1080")
+	p := newTestHop(&HealthCheck{Rise: 2, Fall: 2}, node)
+
+	errProbe := errors.New("probe failed")
+	steps := []struct {
+		err     error
+		healthy bool
+		marks   int64
+	}{
+		// the first failure is tolerated by fall.
+		{err: errProbe, healthy: true, marks: 0},
+		{err: errProbe, healthy: false, marks: 1},
+		// the node is kept marked while it is unhealthy.
+		{err: errProbe, healthy: false, marks: 2},
+		{err: nil, healthy: false, marks: 3},
+		// the marker is reset when the node becomes healthy.
+		{err: nil, healthy: true, marks: 0},
+		{err: nil, healthy: true, marks: 0},
+		{err: errProbe, healthy: true, marks: 0},
+	}
+	for i, step := range steps {
+		p.updateHealth(node, time.Millisecond, step.err)
+
+		if healthy := p.health.isHealthy(node); healthy != step.healthy {
+			t.Errorf("step %d: healthy %v, want %v", i, healthy, step.healthy)
+		}
+		if n := node.Marker().Count(); n != step.marks {
+			t.Errorf("step %d: marker count %d, want %d", i, n, step.marks)
+		}
+	}
+
+	st := p.health.status[healthKey(node)]
+	if st.Failures != 1 || st.Successes != 0 || st.LastError != errProbe.Error() {
+		t.Errorf("status %+v", st)
+	}
+}
+
+func TestCheckNodes(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	// a closed port for the failed probes.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	up := chain.NewNode("up", ln.Addr().String())
+	down := chain.NewNode("down", closed.Addr().String())
+	p := newTestHop(&HealthCheck{Type: HealthCheckTCP, Timeout: time.Second}, up, down)
+
+	p.checkNodes(context.Background())
+	if !p.health.isHealthy(up) || p.health.isHealthy(down) {
+		t.Errorf("up: %v, down: %v", p.health.isHealthy(up), p.health.isHealthy(down))
+	}
+	if n := len(p.health.status); n != 2 {
+		t.Fatalf("%d nodes checked, want 2", n)
+	}
+
+	// the status of the removed node is pruned.
+	p.mu.Lock()
+	p.nodes = []*chain.Node{up}
+	p.mu.Unlock()
+
+	p.checkNodes(context.Background())
+	if _, ok := p.health.status[healthKey(down)]; ok || len(p.health.status) != 1 {
+		t.Errorf("status of the removed node is not pruned: %v", p.health.status)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	// the transport dials with the default logger.
+	logger.SetDefault(xlogger.Nop())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	// the node forwards the connection to the server, so the request goes to the server directly.
+	newNode := func(addr string) *chain.Node {
+		tr := xchain.NewTransport(
+			tcp_dialer.NewDialer(dialer.LoggerOption(xlogger.Nop())),
+			forward_connector.NewConnector(connector.LoggerOption(xlogger.Nop())),
+		)
+		return chain.NewNode("node-0", addr, chain.TransportNodeOption(tr))
+	}
+
+	addr := srv.Listener.Addr().String()
+	tests := []struct {
+		name string
+		hc   *HealthCheck
+		addr string
+		ok   bool
+	}{
+		{name: "tcp", hc: &HealthCheck{Type: HealthCheckTCP}, addr: addr, ok: true},
+		{name: "tcp closed", hc: &HealthCheck{Type: HealthCheckTCP}, addr: closed.Addr().String()},
+		{name: "http", hc: &HealthCheck{Type: HealthCheckHTTP, URL: srv.URL + "/ok"}, addr: addr, ok: true},
+		{name: "http status", hc: &HealthCheck{Type: HealthCheckHTTP, URL: srv.URL + "/fail"}, addr: addr},
+		{name: "http closed", hc: &HealthCheck{Type: HealthCheckHTTP, URL: srv.URL + "/ok"}, addr: closed.Addr().String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.hc.Timeout = time.Second
+			p := newTestHop(tt.hc)
+
+			err := p.probe(context.Background(), newNode(tt.addr))
+			if tt.ok && err != nil {
+				t.Errorf("probe failed: %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Error("probe succeeded, want error")
+			}
+		})
+	}
+}
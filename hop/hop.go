@@ -20,6 +20,7 @@ import (
 	node_parser "github.com/go-gost/x/config/parsing/node"
 	"github.com/go-gost/x/internal/loader"
 	xlogger "github.com/go-gost/x/logger"
+	xrouting "github.com/go-gost/x/routing"
 )
 
 type options struct {
@@ -31,6 +32,7 @@ type options struct {
 	redisLoader loader.Loader
 	httpLoader  loader.Loader
 	period      time.Duration
+	healthCheck *HealthCheck
 	logger      logger.Logger
 }
 
@@ -82,6 +84,14 @@ func HTTPLoaderOption(httpLoader loader.Loader) Option {
 		opts.httpLoader = httpLoader
 	}
 }
+
+// HealthCheckOption enables the active health check for the nodes.
+func HealthCheckOption(hc *HealthCheck) Option {
+	return func(opts *options) {
+		opts.healthCheck = hc
+	}
+}
+
 func LoggerOption(logger logger.Logger) Option {
 	return func(opts *options) {
 		opts.logger = logger
@@ -91,6 +101,7 @@ func LoggerOption(logger logger.Logger) Option {
 type chainHop struct {
 	nodes      []*chain.Node
 	options    options
+	health     *healthChecker
 	logger     logger.Logger
 	mu         sync.RWMutex
 	cancelFunc context.CancelFunc
@@ -118,9 +129,24 @@ func NewHop(opts ...Option) hop.Hop {
 
 	go p.periodReload(ctx)
 
+	if options.healthCheck != nil {
+		p.health = newHealthChecker(options.healthCheck)
+		if options.name != "" {
+			healthStatus.Store(options.name, p)
+		}
+		go p.runHealthCheck(ctx)
+	}
+
 	return p
 }
 
+func (p *chainHop) Name() string {
+	if p == nil {
+		return ""
+	}
+	return p.options.name
+}
+
 func (p *chainHop) Nodes() []*chain.Node {
 	if p == nil {
 		return nil
@@ -165,7 +191,13 @@ func (p *chainHop) Select(ctx context.Context, opts ...hop.SelectOption) *chain.
 				Query:    options.Query,
 				Header:   options.Header,
 			}
-			if !matcher.Match(&req) {
+			var matched bool
+			if m, ok := matcher.(xrouting.Matcher); ok {
+				matched = m.MatchRequest(xrouting.NewRequest(ctx, &req, options.Addr))
+			} else {
+				matched = matcher.Match(&req)
+			}
+			if !matched {
 				continue
 			}
 			log.Debugf("node %s match request %s %s, priority %d", node.Name, req.Protocol, req.Host, node.Options().Priority)
@@ -180,6 +212,21 @@ func (p *chainHop) Select(ctx context.Context, opts ...hop.SelectOption) *chain.
 	if len(nodes) == 0 {
 		return nil
 	}
+
+	if p.health != nil {
+		var healthy []*chain.Node
+		for _, node := range nodes {
+			if p.health.isHealthy(node) {
+				healthy = append(healthy, node)
+			}
+		}
+		// fail open if all the nodes are unhealthy.
+		if len(healthy) > 0 {
+			nodes = healthy
+		} else {
+			log.Warnf("all nodes are unhealthy")
+		}
+	}
 	if len(nodes) == 1 {
 		return nodes[0]
 	}
@@ -302,6 +349,10 @@ func (p *chainHop) reload(ctx context.Context) (err error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if n := len(p.options.nodes); len(p.nodes) > n {
+		// the matchers of the nodes replaced by the reloading hold the GeoIP databases.
+		closeMatchers(p.nodes[n:])
+	}
 	p.nodes = nodes
 
 	return
@@ -365,11 +416,30 @@ func (p *chainHop) parseNode(r io.Reader) ([]*chain.Node, error) {
 
 func (p *chainHop) Close() error {
 	p.cancelFunc()
+	if p.health != nil && p.options.name != "" {
+		healthStatus.CompareAndDelete(p.options.name, p)
+	}
 	if p.options.fileLoader != nil {
 		p.options.fileLoader.Close()
 	}
 	if p.options.redisLoader != nil {
 		p.options.redisLoader.Close()
 	}
+
+	p.mu.Lock()
+	closeMatchers(p.nodes)
+	p.mu.Unlock()
+
 	return nil
 }
+
+func closeMatchers(nodes []*chain.Node) {
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		if closer, ok := node.Options().Matcher.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/go-gost/core/common/bufpool"
+	"github.com/go-gost/core/observer/stats"
+	"github.com/go-gost/x/conntrack"
 	xio "github.com/go-gost/x/internal/io"
 )
 
@@ -21,15 +23,21 @@ func Pipe(ctx context.Context, rw1, rw2 io.ReadWriteCloser) error {
 
 	ch := make(chan error, 2)
 
+	// the traffic of the tracked client connection
+	var st stats.Stats
+	if tc := conntrack.ConnFromContext(ctx); tc != nil {
+		st = tc
+	}
+
 	go func() {
 		defer wg.Done()
-		if err := pipeBuffer(rw1, rw2, bufferSize/2); err != nil {
+		if err := pipeBuffer(rw1, rw2, bufferSize/2, st, stats.KindOutputBytes); err != nil {
 			ch <- err
 		}
 	}()
 	go func() {
 		defer wg.Done()
-		if err := pipeBuffer(rw2, rw1, bufferSize/2); err != nil {
+		if err := pipeBuffer(rw2, rw1, bufferSize/2, st, stats.KindInputBytes); err != nil {
 			ch <- err
 		}
 	}()
@@ -55,11 +63,15 @@ func Pipe(ctx context.Context, rw1, rw2 io.ReadWriteCloser) error {
 	return nil
 }
 
-func pipeBuffer(dst io.ReadWriteCloser, src io.ReadWriteCloser, bufferSize int) error {
+func pipeBuffer(dst io.ReadWriteCloser, src io.ReadWriteCloser, bufferSize int, st stats.Stats, kind stats.Kind) error {
 	buf := bufpool.Get(bufferSize)
 	defer bufpool.Put(buf)
 
-	_, err := io.CopyBuffer(dst, src, buf)
+	var r io.Reader = src
+	if st != nil {
+		r = &statsReader{Reader: src, stats: st, kind: kind}
+	}
+	_, err := io.CopyBuffer(dst, r, buf)
 
 	// Do the upload/download side TCP half-close.
 	if cr, ok := src.(xio.CloseRead); ok {
@@ -79,3 +91,15 @@ func pipeBuffer(dst io.ReadWriteCloser, src io.ReadWriteCloser, bufferSize int)
 
 	return err
 }
+
+type statsReader struct {
+	io.Reader
+	stats stats.Stats
+	kind  stats.Kind
+}
+
+func (r *statsReader) Read(b []byte) (n int, err error) {
+	n, err = r.Reader.Read(b)
+	r.stats.Add(r.kind, int64(n))
+	return
+}
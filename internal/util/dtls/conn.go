@@ -2,9 +2,11 @@ package dtls
 
 import (
 	"bytes"
+	"context"
 	"net"
 
 	"github.com/go-gost/core/common/bufpool"
+	xctx "github.com/go-gost/x/ctx"
 )
 
 type dtlsConn struct {
@@ -71,3 +73,10 @@ func (c *dtlsConn) Write(p []byte) (n int, err error) {
 	}
 	return
 }
+
+func (c *dtlsConn) Context() context.Context {
+	if cc, ok := c.Conn.(xctx.Context); ok {
+		return cc.Context()
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package dtls
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+
+	xctx "github.com/go-gost/x/ctx"
+	"github.com/pion/dtls/v2"
+)
+
+type listener struct {
+	net.Listener
+}
+
+// NewListener wraps the DTLS listener ln which verifies the client certificates,
+// the verified certificate of the client is available through the context of the accepted connection.
+func NewListener(ln net.Listener) net.Listener {
+	return &listener{
+		Listener: ln,
+	}
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	dc, ok := c.(*dtls.Conn)
+	if !ok {
+		return c, nil
+	}
+	// the handshake is completed when the connection is accepted.
+	certs := dc.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return c, nil
+	}
+	cert, err := x509.ParseCertificate(certs[0])
+	if err != nil {
+		return c, nil
+	}
+
+	return &certConn{
+		Conn: c,
+		ctx: xctx.ContextWithPeerCertificate(context.Background(), func() *x509.Certificate {
+			return cert
+		}),
+	}, nil
+}
+
+type certConn struct {
+	net.Conn
+	ctx context.Context
+}
+
+func (c *certConn) Context() context.Context {
+	return c.ctx
+}
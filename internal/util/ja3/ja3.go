@@ -0,0 +1,61 @@
+package ja3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// IsGREASE reports whether v is a GREASE value (RFC 8701).
+// The GREASE values are randomly chosen by the clients, so they are excluded from the fingerprint.
+func IsGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+// FilterGREASE returns the values of vs excluding the GREASE values.
+func FilterGREASE(vs []uint16) []uint16 {
+	var l []uint16
+	for _, v := range vs {
+		if !IsGREASE(v) {
+			l = append(l, v)
+		}
+	}
+	return l
+}
+
+// String returns the JA3 fingerprint string without the GREASE values.
+// Format: SSLVersion,Ciphers,Extensions,EllipticCurves,EllipticCurvePointFormats
+func (d *JA3Data) String() string {
+	var sb strings.Builder
+	sb.WriteString(strconv.Itoa(int(d.Version)))
+	sb.WriteByte(',')
+	writeValues(&sb, FilterGREASE(d.CipherSuites))
+	sb.WriteByte(',')
+	writeValues(&sb, FilterGREASE(d.Extensions))
+	sb.WriteByte(',')
+	writeValues(&sb, FilterGREASE(d.SupportedGroups))
+	sb.WriteByte(',')
+	writeValues(&sb, d.EllipticCurvePoint)
+	return sb.String()
+}
+
+// Hash returns the JA3 hash of the data.
+func (d *JA3Data) Hash() string {
+	return Hash(d.String())
+}
+
+// Hash returns the JA3 hash of the fingerprint string, the MD5 hash in hex.
+func Hash(fingerprint string) string {
+	sum := md5.Sum([]byte(fingerprint))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeValues[T uint8 | uint16](sb *strings.Builder, vs []T) {
+	for i, v := range vs {
+		if i > 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteString(strconv.Itoa(int(v)))
+	}
+}
@@ -0,0 +1,74 @@
+package sniffing
+
+import (
+	"net/http"
+
+	"github.com/go-gost/core/recorder"
+	xrecorder "github.com/go-gost/x/recorder"
+)
+
+// HTTPCapture decides the body capture of an HTTP request and its response.
+// The capture policy of the recorder takes precedence over the http.body option of the recorder object.
+type HTTPCapture struct {
+	policy  *xrecorder.HTTPCapturePolicy
+	opts    *recorder.Options
+	req     *http.Request
+	enabled bool
+}
+
+func NewHTTPCapture(r recorder.Recorder, opts *recorder.Options, req *http.Request) *HTTPCapture {
+	c := &HTTPCapture{
+		policy: xrecorder.HTTPCapturePolicyOf(r),
+		opts:   opts,
+		req:    req,
+	}
+	if c.policy != nil {
+		c.enabled = c.policy.Sample()
+	} else {
+		c.enabled = opts != nil && opts.HTTPBody
+	}
+	return c
+}
+
+// RequestBodySize returns the maximum size of the request body to record, 0 means no recording.
+func (c *HTTPCapture) RequestBodySize() int {
+	if c.req.Body == nil {
+		return 0
+	}
+	return c.bodySize(c.req.Header)
+}
+
+// ResponseBodySize returns the maximum size of the response body to record, 0 means no recording.
+func (c *HTTPCapture) ResponseBodySize(resp *http.Response) int {
+	return c.bodySize(resp.Header)
+}
+
+func (c *HTTPCapture) bodySize(header http.Header) int {
+	if !c.enabled {
+		return 0
+	}
+
+	host := c.req.Host
+	if host == "" && c.req.URL != nil {
+		host = c.req.URL.Host
+	}
+	var path string
+	if c.req.URL != nil {
+		path = c.req.URL.Path
+	}
+
+	size, ok := c.policy.BodySize(host, path, header)
+	if !ok {
+		return 0
+	}
+	if size <= 0 && c.opts != nil {
+		size = c.opts.MaxBodySize
+	}
+	if size <= 0 {
+		size = DefaultBodySize
+	}
+	if size > MaxBodySize {
+		size = MaxBodySize
+	}
+	return size
+}
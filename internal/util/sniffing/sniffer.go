@@ -338,19 +338,11 @@ func (h *Sniffer) httpRoundTrip(ctx context.Context, rw, cc io.ReadWriteCloser,
 		}
 	}
 
+	capture := NewHTTPCapture(h.Recorder, h.RecorderOptions, req)
 	var reqBody *xhttp.Body
-	if opts := h.RecorderOptions; opts != nil && opts.HTTPBody {
-		if req.Body != nil {
-			bodySize := opts.MaxBodySize
-			if bodySize <= 0 {
-				bodySize = DefaultBodySize
-			}
-			if bodySize > MaxBodySize {
-				bodySize = MaxBodySize
-			}
-			reqBody = xhttp.NewBody(req.Body, bodySize)
-			req.Body = reqBody
-		}
+	if bodySize := capture.RequestBodySize(); bodySize > 0 {
+		reqBody = xhttp.NewBody(req.Body, bodySize)
+		req.Body = reqBody
 	}
 
 	err = req.Write(cc)
@@ -408,14 +400,7 @@ func (h *Sniffer) httpRoundTrip(ctx context.Context, rw, cc io.ReadWriteCloser,
 	}
 
 	var respBody *xhttp.Body
-	if opts := h.RecorderOptions; opts != nil && opts.HTTPBody {
-		bodySize := opts.MaxBodySize
-		if bodySize <= 0 {
-			bodySize = DefaultBodySize
-		}
-		if bodySize > MaxBodySize {
-			bodySize = MaxBodySize
-		}
+	if bodySize := capture.ResponseBodySize(resp); bodySize > 0 {
 		respBody = xhttp.NewBody(resp.Body, bodySize)
 		resp.Body = respBody
 	}
@@ -611,6 +596,10 @@ func (h *Sniffer) HandleTLS(ctx context.Context, network string, conn net.Conn,
 		ro.TLS.Proto = clientHello.SupportedProtos[0]
 	}
 
+	if info := ParseTLSInfo(buf.Bytes()); info != nil {
+		ctx = xctx.ContextWithTLSInfo(ctx, info)
+	}
+
 	// ctx = xctx.ContextWithClientAddr(ctx, xctx.ClientAddr(ro.RemoteAddr))
 
 	host := clientHello.ServerName
@@ -909,20 +898,11 @@ func (h *h2Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Trailer:       r.Trailer,
 	}
 
+	capture := NewHTTPCapture(h.recorder, h.recorderOptions, req)
 	var reqBody *xhttp.Body
-	if opts := h.recorderOptions; opts != nil && opts.HTTPBody {
-		if req.Body != nil {
-			bodySize := opts.MaxBodySize
-			if bodySize <= 0 {
-				bodySize = DefaultBodySize
-			}
-			if bodySize > MaxBodySize {
-				bodySize = MaxBodySize
-			}
-
-			reqBody = xhttp.NewBody(req.Body, bodySize)
-			req.Body = reqBody
-		}
+	if bodySize := capture.RequestBodySize(); bodySize > 0 {
+		reqBody = xhttp.NewBody(req.Body, bodySize)
+		req.Body = reqBody
 	}
 
 	resp, err := h.transport.RoundTrip(req.WithContext(r.Context()))
@@ -950,14 +930,7 @@ func (h *h2Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(resp.StatusCode)
 
 	var respBody *xhttp.Body
-	if opts := h.recorderOptions; opts != nil && opts.HTTPBody {
-		bodySize := opts.MaxBodySize
-		if bodySize <= 0 {
-			bodySize = DefaultBodySize
-		}
-		if bodySize > MaxBodySize {
-			bodySize = MaxBodySize
-		}
+	if bodySize := capture.ResponseBodySize(resp); bodySize > 0 {
 		respBody = xhttp.NewBody(resp.Body, bodySize)
 		resp.Body = respBody
 	}
@@ -0,0 +1,75 @@
+package sniffing
+
+import (
+	"bytes"
+
+	dissector "github.com/go-gost/tls-dissector"
+	xctx "github.com/go-gost/x/ctx"
+	"github.com/go-gost/x/internal/util/ja3"
+	"github.com/go-gost/x/internal/util/ja4"
+)
+
+// ParseTLSInfo extracts the server name, ALPN and the JA3/JA4 fingerprints
+// from the raw TLS record b containing the ClientHello message.
+func ParseTLSInfo(b []byte) *xctx.TLSInfo {
+	record, err := dissector.ReadRecord(bytes.NewReader(b))
+	if err != nil || record.Type != dissector.Handshake {
+		return nil
+	}
+	msg := &dissector.ClientHelloMsg{}
+	if err := msg.Decode(record.Opaque); err != nil {
+		return nil
+	}
+
+	info := &xctx.TLSInfo{}
+
+	var exts, groups, versions []uint16
+	var formats []uint8
+	for _, ext := range msg.Extensions {
+		if ja3.IsGREASE(ext.Type()) {
+			continue
+		}
+		exts = append(exts, ext.Type())
+
+		switch v := ext.(type) {
+		case *dissector.ServerNameExtension:
+			info.ServerName = v.Name
+		case *dissector.ALPNExtension:
+			info.ALPN = v.Protos
+		case *dissector.SupportedGroupsExtension:
+			groups = ja3.FilterGREASE(v.Groups)
+		case *dissector.ECPointFormatsExtension:
+			formats = v.Formats
+		case *dissector.SupportedVersionsExtension:
+			versions = ja3.FilterGREASE(v.Versions)
+		}
+	}
+	ciphers := ja3.FilterGREASE(msg.CipherSuites)
+
+	info.JA3 = (&ja3.JA3Data{
+		Version:            uint16(msg.Version),
+		CipherSuites:       ciphers,
+		Extensions:         exts,
+		SupportedGroups:    groups,
+		EllipticCurvePoint: formats,
+	}).String()
+
+	version := uint16(msg.Version)
+	for _, v := range versions {
+		if v > version {
+			version = v
+		}
+	}
+	if fp, _ := ja4.GenerateJA4(&ja4.JA4Data{
+		TLSVersion:      version,
+		ServerName:      info.ServerName,
+		CipherSuites:    ciphers,
+		Extensions:      exts,
+		ALPNProtocols:   info.ALPN,
+		SupportedGroups: groups,
+	}); fp != nil {
+		info.JA4 = fp.String()
+	}
+
+	return info
+}
@@ -0,0 +1,44 @@
+package sniffing
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestParseTLSInfo(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	go func() {
+		tls.Client(c1, &tls.Config{
+			ServerName: "www.example.com",
+			NextProtos: []string{"h2", "http/1.1"},
+		}).Handshake()
+	}()
+
+	buf := make([]byte, 16*1024)
+	n, err := c2.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := ParseTLSInfo(buf[:n])
+	if info == nil {
+		t.Fatal("parse ClientHello failed")
+	}
+	if info.ServerName != "www.example.com" {
+		t.Errorf("server name: got %q", info.ServerName)
+	}
+	if len(info.ALPN) != 2 || info.ALPN[0] != "h2" {
+		t.Errorf("alpn: got %v", info.ALPN)
+	}
+	if parts := strings.Split(info.JA3, ","); len(parts) != 5 || parts[0] != "771" {
+		t.Errorf("ja3: got %q", info.JA3)
+	}
+	if !strings.HasPrefix(info.JA4, "t13d") {
+		t.Errorf("ja4: got %q", info.JA4)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/go-gost/core/auth"
+	xctx "github.com/go-gost/x/ctx"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -27,7 +28,8 @@ func PasswordCallback(service string, au auth.Authenticator) PasswordCallbackFun
 		return nil
 	}
 	return func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
-		if _, ok := au.Authenticate(context.Background(), conn.User(), string(password), auth.WithService(service)); ok {
+		ctx := xctx.ContextWithSrcAddr(context.Background(), conn.RemoteAddr())
+		if _, ok := au.Authenticate(ctx, conn.User(), string(password), auth.WithService(service)); ok {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("password rejected for %s", conn.User())
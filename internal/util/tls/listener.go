@@ -3,6 +3,7 @@ package tls
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"net"
 
 	"github.com/go-gost/x/ctx"
@@ -26,17 +27,53 @@ func (l *listener) Accept() (net.Conn, error) {
 		return nil, err
 	}
 	return &tlsConn{
-		Conn: tls.Server(c, l.config),
+		Conn:       tls.Server(c, l.config),
+		clientAuth: l.config != nil && l.config.ClientAuth != tls.NoClientCert,
 	}, nil
 }
 
 type tlsConn struct {
 	*tls.Conn
+	clientAuth bool
 }
 
 func (c *tlsConn) Context() context.Context {
+	var v context.Context
 	if sc, ok := c.NetConn().(ctx.Context); ok {
-		return sc.Context()
+		v = sc.Context()
 	}
-	return nil
+	if !c.clientAuth {
+		return v
+	}
+
+	if v == nil {
+		v = context.Background()
+	}
+	return ctx.ContextWithPeerCertificate(v, func() *x509.Certificate {
+		// the handshake is normally completed by the first read of the handler.
+		if err := c.Handshake(); err != nil {
+			return nil
+		}
+		state := c.ConnectionState()
+		return PeerCertificate(&state)
+	})
+}
+
+// PeerCertificate returns the verified leaf certificate of the peer, nil if not available.
+func PeerCertificate(state *tls.ConnectionState) *x509.Certificate {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+// ContextWithPeerCertificate adds the verified certificate of the peer in state to ctx.
+func ContextWithPeerCertificate(v context.Context, state *tls.ConnectionState) context.Context {
+	cert := PeerCertificate(state)
+	if cert == nil {
+		return v
+	}
+	return ctx.ContextWithPeerCertificate(v, func() *x509.Certificate {
+		return cert
+	})
 }
@@ -0,0 +1,251 @@
+package wg
+
+import (
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"golang.zx2c4.com/wireguard/conn"
+)
+
+// DialFunc creates the connection to the peer endpoint.
+type DialFunc func() (net.Conn, error)
+
+// ClientBind is a conn.Bind which exchanges the packets with the single peer endpoint
+// through the connection created by the dial function, so the tunnel can run over any gost dialer.
+type ClientBind struct {
+	dial   DialFunc
+	conn   net.Conn
+	failed bool
+	mu     sync.Mutex
+}
+
+func NewClientBind(dial DialFunc) *ClientBind {
+	return &ClientBind{
+		dial: dial,
+	}
+}
+
+func (b *ClientBind) Open(port uint16) ([]conn.ReceiveFunc, uint16, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.conn != nil {
+		return nil, 0, conn.ErrBindAlreadyOpen
+	}
+
+	c, err := b.dial()
+	if err != nil {
+		b.failed = true
+		return nil, 0, err
+	}
+	b.conn = c
+	b.failed = false
+
+	if addr, ok := c.LocalAddr().(*net.UDPAddr); ok {
+		port = uint16(addr.Port)
+	}
+	ep := endpoint(c.RemoteAddr().String())
+
+	return []conn.ReceiveFunc{
+		func(packets [][]byte, sizes []int, eps []conn.Endpoint) (int, error) {
+			n, err := c.Read(packets[0])
+			if err != nil {
+				b.mu.Lock()
+				defer b.mu.Unlock()
+				if b.conn == c {
+					// the connection is broken, the device can not receive any more packets.
+					b.failed = true
+				}
+				return 0, net.ErrClosed
+			}
+			sizes[0] = n
+			eps[0] = ep
+			return 1, nil
+		},
+	}, port, nil
+}
+
+func (b *ClientBind) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.conn == nil {
+		return nil
+	}
+	err := b.conn.Close()
+	b.conn = nil
+	return err
+}
+
+// Failed reports whether the connection to the peer endpoint is broken.
+func (b *ClientBind) Failed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failed
+}
+
+func (b *ClientBind) SetMark(mark uint32) error {
+	return nil
+}
+
+func (b *ClientBind) Send(bufs [][]byte, ep conn.Endpoint) error {
+	b.mu.Lock()
+	c := b.conn
+	b.mu.Unlock()
+
+	if c == nil {
+		return net.ErrClosed
+	}
+	for _, buf := range bufs {
+		if _, err := c.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseEndpoint accepts any address, the packets are always sent through the connection.
+func (b *ClientBind) ParseEndpoint(s string) (conn.Endpoint, error) {
+	return endpoint(s), nil
+}
+
+func (b *ClientBind) BatchSize() int {
+	return 1
+}
+
+// ServerBind is a conn.Bind which exchanges the packets with the peers through the listening packet conn.
+// The packet conn is owned by the caller, closing the bind only interrupts the pending reads,
+// so the device can reopen it when it is brought up again.
+type ServerBind struct {
+	pc     net.PacketConn
+	opened bool
+	mu     sync.Mutex
+}
+
+func NewServerBind(pc net.PacketConn) *ServerBind {
+	return &ServerBind{
+		pc: pc,
+	}
+}
+
+// Open starts receiving on the packet conn, the port is ignored.
+func (b *ServerBind) Open(port uint16) ([]conn.ReceiveFunc, uint16, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.opened {
+		return nil, 0, conn.ErrBindAlreadyOpen
+	}
+	b.opened = true
+	b.pc.SetReadDeadline(time.Time{})
+
+	if addr, ok := b.pc.LocalAddr().(*net.UDPAddr); ok {
+		port = uint16(addr.Port)
+	}
+
+	return []conn.ReceiveFunc{
+		func(packets [][]byte, sizes []int, eps []conn.Endpoint) (int, error) {
+			n, addr, err := b.pc.ReadFrom(packets[0])
+			if err != nil {
+				if !b.isOpened() {
+					return 0, net.ErrClosed
+				}
+				return 0, err
+			}
+			ap, err := netip.ParseAddrPort(addr.String())
+			if err != nil {
+				return 0, nil
+			}
+			sizes[0] = n
+			eps[0] = &conn.StdNetEndpoint{AddrPort: netip.AddrPortFrom(ap.Addr().Unmap(), ap.Port())}
+			return 1, nil
+		},
+	}, port, nil
+}
+
+func (b *ServerBind) isOpened() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.opened
+}
+
+func (b *ServerBind) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.opened {
+		return nil
+	}
+	b.opened = false
+	return b.pc.SetReadDeadline(time.Now())
+}
+
+func (b *ServerBind) SetMark(mark uint32) error {
+	return nil
+}
+
+func (b *ServerBind) Send(bufs [][]byte, ep conn.Endpoint) error {
+	var ap netip.AddrPort
+	if v, ok := ep.(*conn.StdNetEndpoint); ok {
+		ap = v.AddrPort
+	} else {
+		var err error
+		if ap, err = netip.ParseAddrPort(ep.DstToString()); err != nil {
+			return err
+		}
+	}
+	addr := net.UDPAddrFromAddrPort(ap)
+	for _, buf := range bufs {
+		if _, err := b.pc.WriteTo(buf, addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *ServerBind) ParseEndpoint(s string) (conn.Endpoint, error) {
+	ap, err := netip.ParseAddrPort(s)
+	if err != nil {
+		return nil, err
+	}
+	return &conn.StdNetEndpoint{AddrPort: ap}, nil
+}
+
+func (b *ServerBind) BatchSize() int {
+	return 1
+}
+
+type endpoint string
+
+func (ep endpoint) ClearSrc() {}
+
+func (ep endpoint) SrcToString() string {
+	return ""
+}
+
+func (ep endpoint) DstToString() string {
+	return string(ep)
+}
+
+func (ep endpoint) DstToBytes() []byte {
+	if addr, err := netip.ParseAddrPort(string(ep)); err == nil {
+		b, _ := addr.MarshalBinary()
+		return b
+	}
+	return []byte(ep)
+}
+
+func (ep endpoint) DstIP() netip.Addr {
+	if addr, err := netip.ParseAddrPort(string(ep)); err == nil {
+		return addr.Addr()
+	}
+	return netip.Addr{}
+}
+
+func (ep endpoint) SrcIP() netip.Addr {
+	return netip.Addr{}
+}
@@ -0,0 +1,100 @@
+package wg
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"time"
+
+	"golang.zx2c4.com/wireguard/tun/netstack"
+)
+
+var (
+	ErrTunnelConn = errors.New("wg: tunnel connection does not carry data")
+)
+
+// a dummy WireGuard tunnel conn used by client connector
+type ClientConn struct {
+	tnet       *netstack.Net
+	dns        bool
+	localAddr  net.Addr
+	remoteAddr net.Addr
+}
+
+// NewClientConn creates the tunnel conn, dns indicates whether the DNS servers are configured in the tunnel.
+func NewClientConn(tnet *netstack.Net, dns bool, localAddr, remoteAddr net.Addr) net.Conn {
+	return &ClientConn{
+		tnet:       tnet,
+		dns:        dns,
+		localAddr:  localAddr,
+		remoteAddr: remoteAddr,
+	}
+}
+
+// Dial connects to the address through the tunnel.
+// The host name is resolved by the DNS servers of the tunnel if configured, otherwise by the local resolver.
+func (c *ClientConn) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := netip.ParseAddr(host); err == nil || c.dns {
+		return c.tnet.DialContext(ctx, network, address)
+	}
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		var conn net.Conn
+		conn, err = c.tnet.DialContext(ctx, network, net.JoinHostPort(ip.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// Listen listens on the TCP address of the tunnel.
+func (c *ClientConn) Listen(network, address string) (net.Listener, error) {
+	addr, err := net.ResolveTCPAddr(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return c.tnet.ListenTCP(addr)
+}
+
+func (c *ClientConn) Read(b []byte) (n int, err error) {
+	return 0, ErrTunnelConn
+}
+
+func (c *ClientConn) Write(b []byte) (n int, err error) {
+	return 0, ErrTunnelConn
+}
+
+// Close does nothing, the tunnel is owned by the dialer.
+func (c *ClientConn) Close() error {
+	return nil
+}
+
+func (c *ClientConn) LocalAddr() net.Addr {
+	return c.localAddr
+}
+
+func (c *ClientConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+func (c *ClientConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *ClientConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *ClientConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
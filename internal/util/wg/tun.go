@@ -0,0 +1,129 @@
+package wg
+
+import (
+	"os"
+	"sync"
+	"syscall"
+
+	"golang.zx2c4.com/wireguard/tun"
+	"gvisor.dev/gvisor/pkg/buffer"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/link/channel"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+// StackTUN is a tun.Device which exchanges the packets of the WireGuard device
+// with a userspace network stack through the link endpoint.
+type StackTUN struct {
+	ep           *channel.Endpoint
+	notifyHandle *channel.NotificationHandle
+	events       chan tun.Event
+	incoming     chan *buffer.View
+	closed       chan struct{}
+	closeOnce    sync.Once
+	mtu          int
+}
+
+func NewStackTUN(mtu int) *StackTUN {
+	t := &StackTUN{
+		ep:       channel.New(1024, uint32(mtu), ""),
+		events:   make(chan tun.Event, 1),
+		incoming: make(chan *buffer.View),
+		closed:   make(chan struct{}),
+		mtu:      mtu,
+	}
+	t.notifyHandle = t.ep.AddNotify(t)
+	t.events <- tun.EventUp
+	return t
+}
+
+// LinkEndpoint returns the link endpoint to create the NIC of the network stack.
+func (t *StackTUN) LinkEndpoint() stack.LinkEndpoint {
+	return t.ep
+}
+
+func (t *StackTUN) File() *os.File {
+	return nil
+}
+
+// Read reads the packet sent by the network stack.
+func (t *StackTUN) Read(bufs [][]byte, sizes []int, offset int) (int, error) {
+	select {
+	case view := <-t.incoming:
+		n, err := view.Read(bufs[0][offset:])
+		view.Release()
+		if err != nil {
+			return 0, err
+		}
+		sizes[0] = n
+		return 1, nil
+	case <-t.closed:
+		return 0, os.ErrClosed
+	}
+}
+
+// Write delivers the decrypted packets to the network stack.
+func (t *StackTUN) Write(bufs [][]byte, offset int) (int, error) {
+	for _, buf := range bufs {
+		packet := buf[offset:]
+		if len(packet) == 0 {
+			continue
+		}
+
+		pkb := stack.NewPacketBuffer(stack.PacketBufferOptions{Payload: buffer.MakeWithData(packet)})
+		switch packet[0] >> 4 {
+		case 4:
+			t.ep.InjectInbound(header.IPv4ProtocolNumber, pkb)
+		case 6:
+			t.ep.InjectInbound(header.IPv6ProtocolNumber, pkb)
+		default:
+			pkb.DecRef()
+			return 0, syscall.EAFNOSUPPORT
+		}
+		pkb.DecRef()
+	}
+	return len(bufs), nil
+}
+
+// WriteNotify implements channel.Notification interface.
+func (t *StackTUN) WriteNotify() {
+	pkt := t.ep.Read()
+	if pkt == nil {
+		return
+	}
+
+	view := pkt.ToView()
+	pkt.DecRef()
+
+	select {
+	case t.incoming <- view:
+	case <-t.closed:
+		view.Release()
+	}
+}
+
+func (t *StackTUN) MTU() (int, error) {
+	return t.mtu, nil
+}
+
+func (t *StackTUN) Name() (string, error) {
+	return "gost", nil
+}
+
+func (t *StackTUN) Events() <-chan tun.Event {
+	return t.events
+}
+
+func (t *StackTUN) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+		t.ep.RemoveNotify(t.notifyHandle)
+		t.ep.Close()
+		close(t.events)
+	})
+	return nil
+}
+
+func (t *StackTUN) BatchSize() int {
+	return 1
+}
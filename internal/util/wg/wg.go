@@ -0,0 +1,73 @@
+package wg
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/go-gost/core/logger"
+	"golang.zx2c4.com/wireguard/device"
+)
+
+const (
+	DefaultMTU = 1420
+)
+
+var (
+	ErrInvalidKey = errors.New("wg: invalid key")
+)
+
+// ParseKey parses the base64 (as generated by wg genkey) or hex encoded key,
+// and returns the key in hex encoding used by the configuration protocol.
+func ParseKey(s string) (string, error) {
+	s = strings.TrimSpace(s)
+
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(b) != device.NoisePublicKeySize {
+		if b, err = hex.DecodeString(s); err != nil || len(b) != device.NoisePublicKeySize {
+			return "", ErrInvalidKey
+		}
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ParsePrefixes parses the IP prefixes, a single address is treated as the prefix of the full length.
+// Each item can be a comma separated list.
+func ParsePrefixes(ss ...string) (prefixes []netip.Prefix, err error) {
+	for _, s := range ss {
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+
+			var prefix netip.Prefix
+			if strings.Contains(v, "/") {
+				prefix, err = netip.ParsePrefix(v)
+			} else {
+				var addr netip.Addr
+				if addr, err = netip.ParseAddr(v); err == nil {
+					prefix = netip.PrefixFrom(addr, addr.BitLen())
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("wg: invalid address %s: %w", v, err)
+			}
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return
+}
+
+// NewLogger adapts the logger for the WireGuard device.
+func NewLogger(log logger.Logger) *device.Logger {
+	if log == nil {
+		log = logger.Default()
+	}
+	return &device.Logger{
+		Verbosef: log.Tracef,
+		Errorf:   log.Errorf,
+	}
+}
@@ -22,6 +22,10 @@ import (
 const (
 	ServiceLimitKey = "$"
 	ConnLimitKey    = "$$"
+	// ClientLimitKey is the default limit for each client without a dedicated rule.
+	ClientLimitKey = "$user"
+	// ClientLimitPrefix is the key prefix of the client level limits, e.g. $user:alice.
+	ClientLimitPrefix = "$user:"
 )
 
 const (
@@ -88,8 +92,13 @@ type trafficLimiter struct {
 	connInLimits  *cache.Cache
 	connOutLimits *cache.Cache
 	// service level in/out limits
-	inLimits   *cache.Cache
-	outLimits  *cache.Cache
+	inLimits  *cache.Cache
+	outLimits *cache.Cache
+	// client level in/out limits, keyed by client ID
+	clientInLimits  *cache.Cache
+	clientOutLimits *cache.Cache
+	// dedicated client limits
+	clients    map[string]limitValue
 	options    options
 	logger     logger.Logger
 	mu         sync.RWMutex
@@ -104,14 +113,16 @@ func NewTrafficLimiter(opts ...Option) traffic.TrafficLimiter {
 
 	ctx, cancel := context.WithCancel(context.TODO())
 	lim := &trafficLimiter{
-		cidrGenerators: cidranger.NewPCTrieRanger(),
-		connInLimits:   cache.New(defaultExpiration, cleanupInterval),
-		connOutLimits:  cache.New(defaultExpiration, cleanupInterval),
-		inLimits:       cache.New(defaultExpiration, cleanupInterval),
-		outLimits:      cache.New(defaultExpiration, cleanupInterval),
-		options:        options,
-		cancelFunc:     cancel,
-		logger:         options.logger,
+		cidrGenerators:  cidranger.NewPCTrieRanger(),
+		connInLimits:    cache.New(defaultExpiration, cleanupInterval),
+		connOutLimits:   cache.New(defaultExpiration, cleanupInterval),
+		inLimits:        cache.New(defaultExpiration, cleanupInterval),
+		outLimits:       cache.New(defaultExpiration, cleanupInterval),
+		clientInLimits:  cache.New(defaultExpiration, cleanupInterval),
+		clientOutLimits: cache.New(defaultExpiration, cleanupInterval),
+		options:         options,
+		cancelFunc:      cancel,
+		logger:          options.logger,
 	}
 	if lim.logger == nil {
 		lim.logger = xlogger.Nop()
@@ -124,6 +135,7 @@ func NewTrafficLimiter(opts ...Option) traffic.TrafficLimiter {
 
 // In obtains a traffic input limiter based on key.
 // For connection scope, the key should be client connection address.
+// For client scope, the key should be the client ID.
 func (l *trafficLimiter) In(ctx context.Context, key string, opts ...limiter.Option) traffic.Limiter {
 	var options limiter.Options
 	for _, opt := range opts {
@@ -138,7 +150,11 @@ func (l *trafficLimiter) In(ctx context.Context, key string, opts ...limiter.Opt
 		return nil
 
 	case limiter.ScopeClient:
-		return nil
+		lim := l.clientLimiter(l.clientInLimits, key, func(v limitValue) int { return v.in })
+		if lim != nil && l.logger != nil {
+			l.logger.Debugf("input limit for client %s: %s", key, lim)
+		}
+		return lim
 
 	case limiter.ScopeConn:
 		fallthrough
@@ -203,6 +219,7 @@ func (l *trafficLimiter) In(ctx context.Context, key string, opts ...limiter.Opt
 
 // Out obtains a traffic output limiter based on key.
 // For connection scope, the key should be client connection address.
+// For client scope, the key should be the client ID.
 func (l *trafficLimiter) Out(ctx context.Context, key string, opts ...limiter.Option) traffic.Limiter {
 	var options limiter.Options
 	for _, opt := range opts {
@@ -217,7 +234,11 @@ func (l *trafficLimiter) Out(ctx context.Context, key string, opts ...limiter.Op
 		return nil
 
 	case limiter.ScopeClient:
-		return nil
+		lim := l.clientLimiter(l.clientOutLimits, key, func(v limitValue) int { return v.out })
+		if lim != nil && l.logger != nil {
+			l.logger.Debugf("output limit for client %s: %s", key, lim)
+		}
+		return lim
 
 	case limiter.ScopeConn:
 		fallthrough
@@ -280,6 +301,52 @@ func (l *trafficLimiter) Out(ctx context.Context, key string, opts ...limiter.Op
 	return lim
 }
 
+// clientLimiter returns the limiter shared by all connections of the client.
+func (l *trafficLimiter) clientLimiter(limits *cache.Cache, client string, rate func(limitValue) int) traffic.Limiter {
+	if client == "" {
+		return nil
+	}
+
+	l.mu.RLock()
+	value, ok := l.clients[client]
+	l.mu.RUnlock()
+
+	expiration := cache.NoExpiration
+	if !ok {
+		// default client level limit
+		v, _ := l.generators.Load(ClientLimitKey)
+		gen, _ := v.(*limitGenerator)
+		if gen == nil {
+			return nil
+		}
+		value = limitValue{in: gen.in, out: gen.out}
+		expiration = defaultExpiration
+	}
+
+	n := rate(value)
+	if n <= 0 {
+		return nil
+	}
+
+	if v, ok := limits.Get(client); ok && v != nil {
+		lim := v.(traffic.Limiter)
+		if expiration != cache.NoExpiration {
+			// reset expiration
+			limits.Set(client, lim, expiration)
+		}
+		return lim
+	}
+
+	lim := NewLimiter(n)
+	if err := limits.Add(client, lim, expiration); err != nil {
+		// created by another connection of the same client
+		if v, ok := limits.Get(client); ok && v != nil {
+			return v.(traffic.Limiter)
+		}
+	}
+	return lim
+}
+
 func (l *trafficLimiter) periodReload(ctx context.Context) error {
 	if err := l.reload(ctx); err != nil {
 		l.logger.Warnf("reload: %v", err)
@@ -382,6 +449,30 @@ func (l *trafficLimiter) reload(ctx context.Context) error {
 		delete(values, ConnLimitKey)
 	}
 
+	// client level limiters
+	{
+		value := values[ClientLimitKey]
+		l.generators.Store(ClientLimitKey, newLimitGenerator(value.in, value.out))
+		delete(values, ClientLimitKey)
+
+		clients := make(map[string]limitValue)
+		for key, value := range values {
+			if client, ok := strings.CutPrefix(key, ClientLimitPrefix); ok {
+				if client != "" {
+					clients[client] = value
+				}
+				delete(values, key)
+			}
+		}
+
+		l.mu.Lock()
+		l.clients = clients
+		l.mu.Unlock()
+
+		l.reloadClientLimits(l.clientInLimits, clients, value.in, func(v limitValue) int { return v.in })
+		l.reloadClientLimits(l.clientOutLimits, clients, value.out, func(v limitValue) int { return v.out })
+	}
+
 	cidrGenerators := cidranger.NewPCTrieRanger()
 	// IP/CIDR level limiters
 	{
@@ -476,6 +567,30 @@ func (l *trafficLimiter) reload(ctx context.Context) error {
 	return nil
 }
 
+// reloadClientLimits updates the cached client limiters in place,
+// so the connections of a client keep sharing the same limiter.
+func (l *trafficLimiter) reloadClientLimits(limits *cache.Cache, clients map[string]limitValue, def int, rate func(limitValue) int) {
+	for client, item := range limits.Items() {
+		lim, _ := item.Object.(traffic.Limiter)
+
+		n := def
+		expiration := defaultExpiration
+		if v, ok := clients[client]; ok {
+			n = rate(v)
+			expiration = cache.NoExpiration
+		}
+		if lim == nil || n <= 0 {
+			limits.Delete(client)
+			continue
+		}
+
+		if lim.Limit() != n {
+			lim.Set(n)
+		}
+		limits.Set(client, lim, expiration)
+	}
+}
+
 func (l *trafficLimiter) load(ctx context.Context) (values map[string]limitValue, err error) {
 	values = make(map[string]limitValue)
 
@@ -0,0 +1,133 @@
+package traffic
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/limiter"
+	"github.com/go-gost/core/limiter/traffic"
+)
+
+// testLoader lists the limits which can be changed between the reloads.
+type testLoader struct {
+	limits []string
+	mu     sync.Mutex
+}
+
+func (p *testLoader) set(limits ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limits = limits
+}
+
+func (p *testLoader) Load(ctx context.Context) (io.Reader, error) {
+	return nil, io.EOF
+}
+
+func (p *testLoader) List(ctx context.Context) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.limits, nil
+}
+
+func (p *testLoader) Close() error {
+	return nil
+}
+
+func limit(lim traffic.Limiter) int {
+	if lim == nil {
+		return 0
+	}
+	return lim.Limit()
+}
+
+func TestClientLimits(t *testing.T) {
+	const MB = 1 << 20
+
+	ld := &testLoader{}
+	ld.set(
+		"$user 1MB 2MB",
+		"$user:alice 10MB 20MB",
+	)
+	l := NewTrafficLimiter(FileLoaderOption(ld)).(*trafficLimiter)
+	defer l.Close()
+
+	ctx := context.Background()
+	client := limiter.ScopeOption(limiter.ScopeClient)
+
+	// the limits are loaded in the background.
+	for i := 0; l.In(ctx, "bob", client) == nil; i++ {
+		if i >= 100 {
+			t.Fatal("limits are not loaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	tests := []struct {
+		client string
+		in     int
+		out    int
+	}{
+		{client: "bob", in: 1 * MB, out: 2 * MB},
+		// the dedicated limits override the default client limits.
+		{client: "alice", in: 10 * MB, out: 20 * MB},
+	}
+	for _, tt := range tests {
+		if in, out := limit(l.In(ctx, tt.client, client)), limit(l.Out(ctx, tt.client, client)); in != tt.in || out != tt.out {
+			t.Errorf("%s: limits %d/%d, want %d/%d", tt.client, in, out, tt.in, tt.out)
+		}
+	}
+
+	aliceIn := l.In(ctx, "alice", client)
+	bobIn := l.In(ctx, "bob", client)
+	if l.In(ctx, "bob", client) != bobIn {
+		t.Error("limiter is not shared by the connections of the client")
+	}
+
+	// the reload replaces the dedicated limits.
+	ld.set(
+		"$user 1MB 2MB",
+		"$user:alice 5MB",
+	)
+	if err := l.reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tests = []struct {
+		client string
+		in     int
+		out    int
+	}{
+		{client: "bob", in: 1 * MB, out: 2 * MB},
+		// the unlimited output of the dedicated limits is not limited by the default one.
+		{client: "alice", in: 5 * MB, out: 0},
+	}
+	for _, tt := range tests {
+		if in, out := limit(l.In(ctx, tt.client, client)), limit(l.Out(ctx, tt.client, client)); in != tt.in || out != tt.out {
+			t.Errorf("reload: %s: limits %d/%d, want %d/%d", tt.client, in, out, tt.in, tt.out)
+		}
+	}
+	// the limiters are updated in place for the established connections.
+	if aliceIn.Limit() != 5*MB || l.In(ctx, "alice", client) != aliceIn {
+		t.Errorf("limiter of alice is not updated in place")
+	}
+
+	// alice falls back to the default client limits when the dedicated ones are removed.
+	ld.set(
+		"$user 3MB 4MB",
+	)
+	if err := l.reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []string{"alice", "bob"} {
+		if in, out := limit(l.In(ctx, c, client)), limit(l.Out(ctx, c, client)); in != 3*MB || out != 4*MB {
+			t.Errorf("reload: %s: limits %d/%d, want %d/%d", c, in, out, 3*MB, 4*MB)
+		}
+	}
+	if bobIn.Limit() != 3*MB {
+		t.Errorf("limiter of bob is not updated in place")
+	}
+}
@@ -23,6 +23,8 @@ import (
 	stats "github.com/go-gost/x/observer/stats/wrapper"
 	"github.com/go-gost/x/registry"
 	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
 )
 
 func init() {
@@ -92,7 +94,7 @@ func (l *dnsListener) Init(md md.Metadata) (err error) {
 			},
 		}
 
-	case "tls":
+	case "tls", "dot":
 		l.addr, err = net.ResolveTCPAddr("tcp", l.options.Addr)
 		if err != nil {
 			return
@@ -130,7 +132,7 @@ func (l *dnsListener) Init(md md.Metadata) (err error) {
 			},
 		}
 
-	case "https":
+	case "https", "doh":
 		l.addr, err = net.ResolveTCPAddr("tcp", l.options.Addr)
 		if err != nil {
 			return
@@ -152,7 +154,12 @@ func (l *dnsListener) Init(md md.Metadata) (err error) {
 		if err != nil {
 			return
 		}
-		ln = tls.NewListener(ln, l.options.TLSConfig)
+		// HTTP/2 is negotiated via ALPN, HTTP/1.1 is the fallback.
+		tlsCfg := l.options.TLSConfig.Clone()
+		if len(tlsCfg.NextProtos) == 0 {
+			tlsCfg.NextProtos = []string{"h2", "http/1.1"}
+		}
+		ln = tls.NewListener(ln, tlsCfg)
 
 		ln = limiter_wrapper.WrapListener(l.options.Service, ln, l.options.TrafficLimiter)
 
@@ -167,6 +174,37 @@ func (l *dnsListener) Init(md md.Metadata) (err error) {
 			},
 		}
 
+	case "h3", "doh3":
+		var ln *quic.EarlyListener
+		ln, err = l.listenQUIC(http3.ConfigureTLSConfig(l.options.TLSConfig.Clone()))
+		if err != nil {
+			return
+		}
+
+		l.server = &doh3Server{
+			ln: ln,
+			server: &http3.Server{
+				Handler: l,
+			},
+		}
+
+	case "quic", "doq":
+		tlsCfg := l.options.TLSConfig.Clone()
+		tlsCfg.NextProtos = []string{"doq"}
+
+		var ln *quic.EarlyListener
+		ln, err = l.listenQUIC(tlsCfg)
+		if err != nil {
+			return
+		}
+
+		l.server = &doqServer{
+			ln:          ln,
+			handler:     l.serve,
+			readTimeout: l.md.readTimeout,
+			logger:      l.logger,
+		}
+
 	default:
 		l.addr, err = net.ResolveUDPAddr("udp", l.options.Addr)
 		if err != nil {
@@ -229,6 +267,51 @@ func (l *dnsListener) Init(md md.Metadata) (err error) {
 	return
 }
 
+func (l *dnsListener) listenQUIC(tlsCfg *tls.Config) (*quic.EarlyListener, error) {
+	addr, err := net.ResolveUDPAddr("udp", l.options.Addr)
+	if err != nil {
+		return nil, err
+	}
+	l.addr = addr
+
+	network := "udp"
+	if xnet.IsIPv4(l.options.Addr) {
+		network = "udp4"
+	}
+
+	var pc net.PacketConn
+	pc, err = net.ListenUDP(network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	pc = limiter_wrapper.WrapPacketConn(
+		pc,
+		l.options.TrafficLimiter,
+		traffic_limiter.ServiceLimitKey,
+		limiter.ScopeOption(limiter.ScopeService),
+		limiter.ServiceOption(l.options.Service),
+		limiter.NetworkOption(network),
+	)
+
+	config := &quic.Config{
+		KeepAlivePeriod:      l.md.keepAlivePeriod,
+		HandshakeIdleTimeout: l.md.handshakeTimeout,
+		MaxIdleTimeout:       l.md.maxIdleTimeout,
+		Versions: []quic.Version{
+			quic.Version1,
+		},
+		MaxIncomingStreams: int64(l.md.maxStreams),
+	}
+
+	ln, err := quic.ListenEarly(pc, tlsCfg, config)
+	if err != nil {
+		pc.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
 func (l *dnsListener) Accept() (conn net.Conn, err error) {
 	var ok bool
 	select {
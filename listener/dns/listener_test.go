@@ -0,0 +1,192 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/listener"
+	"github.com/go-gost/core/logger"
+	xlogger "github.com/go-gost/x/logger"
+	xmd "github.com/go-gost/x/metadata"
+	"github.com/go-gost/x/resolver/exchanger"
+	"github.com/miekg/dns"
+)
+
+func testTLSConfig(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dns.test"},
+		DNSNames:     []string{"dns.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{raw}, PrivateKey: key}},
+	}
+}
+
+// freeAddr returns a free local address of the network,
+// the listener does not report the port chosen by the system.
+func freeAddr(t *testing.T, network string) string {
+	if network == "udp" {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pc.Close()
+		return pc.LocalAddr().String()
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// serve answers the A queries accepted by the listener with 192.0.2.1.
+func serve(t *testing.T, ln listener.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+
+			b := make([]byte, 1024)
+			n, err := conn.Read(b)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mq := &dns.Msg{}
+			if err := mq.Unpack(b[:n]); err != nil {
+				t.Error(err)
+				return
+			}
+			mr := (&dns.Msg{}).SetReply(mq)
+			rr, _ := dns.NewRR(mq.Question[0].Name + " 60 IN A 192.0.2.1")
+			mr.Answer = append(mr.Answer, rr)
+			reply, _ := mr.Pack()
+			conn.Write(reply)
+		}()
+	}
+}
+
+func TestListener(t *testing.T) {
+	logger.SetDefault(xlogger.Nop())
+
+	tests := []struct {
+		mode    string
+		network string
+		scheme  string
+	}{
+		{mode: "dot", network: "tcp", scheme: "dot"},
+		{mode: "doh", network: "tcp", scheme: "https"},
+		{mode: "h3", network: "udp", scheme: "h3"},
+		{mode: "doq", network: "udp", scheme: "doq"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			addr := freeAddr(t, tt.network)
+
+			ln := NewListener(
+				listener.AddrOption(addr),
+				listener.TLSConfigOption(testTLSConfig(t)),
+				listener.LoggerOption(xlogger.Nop()),
+			)
+			if err := ln.Init(xmd.NewMetadata(map[string]any{
+				"mode": tt.mode,
+			})); err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+			go serve(t, ln)
+
+			rawAddr := tt.scheme + "://" + addr
+			if tt.scheme == "https" || tt.scheme == "h3" {
+				rawAddr += "/dns-query"
+			}
+			ex, err := exchanger.NewExchanger(rawAddr,
+				exchanger.TimeoutOption(3*time.Second),
+				exchanger.LoggerOption(xlogger.Nop()),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 2; i++ {
+				mq := (&dns.Msg{}).SetQuestion("example.com.", dns.TypeA)
+				query, _ := mq.Pack()
+
+				b, err := ex.Exchange(context.Background(), query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				mr := &dns.Msg{}
+				if err := mr.Unpack(b); err != nil {
+					t.Fatal(err)
+				}
+				if len(mr.Answer) != 1 {
+					t.Fatalf("got answers %v", mr.Answer)
+				}
+				if a, ok := mr.Answer[0].(*dns.A); !ok || !a.A.Equal(net.IPv4(192, 0, 2, 1)) {
+					t.Errorf("got answer %v", mr.Answer[0])
+				}
+			}
+
+			// DoH is served over HTTP/2.
+			if tt.mode == "doh" {
+				testHTTP2(t, addr)
+			}
+		})
+	}
+}
+
+func testHTTP2(t *testing.T, addr string) {
+	client := &http.Client{
+		Timeout: 3 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			ForceAttemptHTTP2: true,
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	query, _ := (&dns.Msg{}).SetQuestion("example.com.", dns.TypeA).Pack()
+	resp, err := client.Post("https://"+addr+"/dns-query", "application/dns-message", bytes.NewReader(query))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("got protocol %s, want HTTP/2", resp.Proto)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got status %s", resp.Status)
+	}
+}
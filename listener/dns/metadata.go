@@ -18,6 +18,12 @@ type metadata struct {
 	writeTimeout   time.Duration
 	backlog        int
 	mptcp          bool
+
+	// QUIC config options for the h3 and doq modes
+	keepAlivePeriod  time.Duration
+	maxIdleTimeout   time.Duration
+	handshakeTimeout time.Duration
+	maxStreams       int
 }
 
 func (l *dnsListener) parseMetadata(md mdata.Metadata) (err error) {
@@ -27,6 +33,12 @@ func (l *dnsListener) parseMetadata(md mdata.Metadata) (err error) {
 		readBufferSize = "readBufferSize"
 		readTimeout    = "readTimeout"
 		writeTimeout   = "writeTimeout"
+
+		keepAlive        = "keepAlive"
+		keepAlivePeriod  = "ttl"
+		handshakeTimeout = "handshakeTimeout"
+		maxIdleTimeout   = "maxIdleTimeout"
+		maxStreams       = "maxStreams"
 	)
 
 	l.md.mode = mdutil.GetString(md, mode)
@@ -40,5 +52,15 @@ func (l *dnsListener) parseMetadata(md mdata.Metadata) (err error) {
 	}
 	l.md.mptcp = mdutil.GetBool(md, "mptcp")
 
+	if mdutil.GetBool(md, keepAlive) {
+		l.md.keepAlivePeriod = mdutil.GetDuration(md, keepAlivePeriod)
+		if l.md.keepAlivePeriod <= 0 {
+			l.md.keepAlivePeriod = 10 * time.Second
+		}
+	}
+	l.md.handshakeTimeout = mdutil.GetDuration(md, handshakeTimeout)
+	l.md.maxIdleTimeout = mdutil.GetDuration(md, maxIdleTimeout)
+	l.md.maxStreams = mdutil.GetInt(md, maxStreams)
+
 	return
 }
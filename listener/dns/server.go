@@ -3,6 +3,7 @@ package dns
 import (
 	"context"
 	"crypto/tls"
+	"encoding/binary"
 	"errors"
 	"io"
 	"net"
@@ -10,8 +11,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-gost/core/logger"
 	xnet "github.com/go-gost/x/internal/net"
 	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
 )
 
 type Server interface {
@@ -59,6 +63,92 @@ func (s *dohServer) Shutdown() error {
 	return s.server.Shutdown(context.Background())
 }
 
+// doh3Server serves DNS over HTTP/3 (RFC 8484 over QUIC).
+type doh3Server struct {
+	ln     *quic.EarlyListener
+	server *http3.Server
+}
+
+func (s *doh3Server) Serve() error {
+	err := s.server.ServeListener(s.ln)
+	if errors.Is(err, http.ErrServerClosed) || errors.Is(err, quic.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *doh3Server) Shutdown() error {
+	return s.server.Close()
+}
+
+// doqServer serves DNS over dedicated QUIC connections (RFC 9250).
+// Each query is carried on its own bidirectional stream, prefixed with a 2-octet length field.
+type doqServer struct {
+	ln          *quic.EarlyListener
+	handler     func(w ResponseWriter, msg []byte) error
+	readTimeout time.Duration
+	logger      logger.Logger
+}
+
+func (s *doqServer) Serve() error {
+	for {
+		conn, err := s.ln.Accept(context.Background())
+		if err != nil {
+			if errors.Is(err, quic.ErrServerClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *doqServer) serveConn(conn *quic.Conn) {
+	// DOQ_NO_ERROR
+	defer conn.CloseWithError(0, "")
+
+	for {
+		stream, err := conn.AcceptStream(conn.Context())
+		if err != nil {
+			return
+		}
+		go s.serveStream(conn, stream)
+	}
+}
+
+func (s *doqServer) serveStream(conn *quic.Conn, stream *quic.Stream) {
+	defer stream.Close()
+
+	if s.readTimeout > 0 {
+		stream.SetReadDeadline(time.Now().Add(s.readTimeout))
+	}
+
+	var b [2]byte
+	if _, err := io.ReadFull(stream, b[:]); err != nil {
+		s.logger.Debugf("doq %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(b[:]))
+	if _, err := io.ReadFull(stream, msg); err != nil {
+		s.logger.Debugf("doq %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+
+	w := &doqResponseWriter{
+		stream: stream,
+		raddr:  conn.RemoteAddr(),
+	}
+	if err := s.handler(w, msg); err != nil {
+		s.logger.Error(err)
+		// DOQ_INTERNAL_ERROR
+		stream.CancelWrite(1)
+	}
+}
+
+func (s *doqServer) Shutdown() error {
+	return s.ln.Close()
+}
+
 type ResponseWriter interface {
 	io.Writer
 	RemoteAddr() net.Addr
@@ -73,6 +163,28 @@ func (w *dohResponseWriter) RemoteAddr() net.Addr {
 	return w.raddr
 }
 
+type doqResponseWriter struct {
+	stream *quic.Stream
+	raddr  net.Addr
+}
+
+func (w *doqResponseWriter) Write(b []byte) (int, error) {
+	if len(b) > dns.MaxMsgSize {
+		return 0, dns.ErrBuf
+	}
+	buf := make([]byte, 2+len(b))
+	binary.BigEndian.PutUint16(buf, uint16(len(b)))
+	copy(buf[2:], b)
+	if _, err := w.stream.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *doqResponseWriter) RemoteAddr() net.Addr {
+	return w.raddr
+}
+
 type serverConn struct {
 	r      io.Reader
 	w      ResponseWriter
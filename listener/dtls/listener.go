@@ -79,6 +79,9 @@ func (l *dtlsListener) Init(md md.Metadata) (err error) {
 	if err != nil {
 		return
 	}
+	if tlsCfg.ClientAuth >= tls.VerifyClientCertIfGiven {
+		ln = xdtls.NewListener(ln)
+	}
 	ln = proxyproto.WrapListener(l.options.ProxyProtocol, ln, 10*time.Second)
 	ln = metrics.WrapListener(l.options.Service, ln)
 	ln = stats.WrapListener(ln, l.options.Stats)
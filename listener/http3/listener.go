@@ -12,6 +12,7 @@ import (
 	ictx "github.com/go-gost/x/internal/ctx"
 	xnet "github.com/go-gost/x/internal/net"
 	xhttp "github.com/go-gost/x/internal/net/http"
+	xtls "github.com/go-gost/x/internal/util/tls"
 	mdx "github.com/go-gost/x/metadata"
 	"github.com/go-gost/x/registry"
 	"github.com/quic-go/quic-go"
@@ -134,7 +135,7 @@ func (l *http3Listener) handleFunc(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	ctx := r.Context()
+	ctx := xtls.ContextWithPeerCertificate(r.Context(), r.TLS)
 	if clientIP := xhttp.GetClientIP(r); clientIP != nil {
 		ctx = xctx.ContextWithSrcAddr(ctx, &net.UDPAddr{IP: clientIP})
 	}
@@ -0,0 +1,16 @@
+package wg
+
+import (
+	"context"
+	"net"
+)
+
+// flowConn is a TCP or UDP flow of the peer terminated by the network stack.
+type flowConn struct {
+	net.Conn
+	ctx context.Context
+}
+
+func (c *flowConn) Context() context.Context {
+	return c.ctx
+}
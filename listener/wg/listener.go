@@ -0,0 +1,222 @@
+package wg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+
+	"github.com/go-gost/core/limiter"
+	"github.com/go-gost/core/listener"
+	"github.com/go-gost/core/logger"
+	mdata "github.com/go-gost/core/metadata"
+	admission "github.com/go-gost/x/admission/wrapper"
+	xauth "github.com/go-gost/x/auth"
+	xctx "github.com/go-gost/x/ctx"
+	xnet "github.com/go-gost/x/internal/net"
+	wg_util "github.com/go-gost/x/internal/util/wg"
+	traffic_limiter "github.com/go-gost/x/limiter/traffic"
+	limiter_wrapper "github.com/go-gost/x/limiter/traffic/wrapper"
+	metrics "github.com/go-gost/x/metrics/wrapper"
+	stats "github.com/go-gost/x/observer/stats/wrapper"
+	"github.com/go-gost/x/registry"
+	"github.com/xjasonlyu/tun2socks/v2/core"
+	"github.com/xjasonlyu/tun2socks/v2/core/adapter"
+	"github.com/xjasonlyu/tun2socks/v2/core/option"
+	"golang.zx2c4.com/wireguard/device"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+func init() {
+	registry.ListenerRegistry().Register("wg", NewListener)
+}
+
+// wgListener terminates the WireGuard peers on a userspace network stack,
+// each TCP or UDP flow of the peers is accepted as a connection,
+// whose local address is the original destination.
+type wgListener struct {
+	addr    net.Addr
+	conn    net.PacketConn
+	dev     *device.Device
+	stack   *stack.Stack
+	peers   map[string]*peer
+	mu      sync.RWMutex
+	cqueue  chan net.Conn
+	closed  chan struct{}
+	cancel  context.CancelFunc
+	log     logger.Logger
+	md      metadata
+	options listener.Options
+}
+
+func NewListener(opts ...listener.Option) listener.Listener {
+	options := listener.Options{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &wgListener{
+		log:     options.Logger,
+		options: options,
+	}
+}
+
+func (l *wgListener) Init(md mdata.Metadata) (err error) {
+	if err = l.parseMetadata(md); err != nil {
+		return
+	}
+	if l.options.Auther == nil {
+		return errors.New("wg: auther is required to load the peers")
+	}
+	if _, ok := l.options.Auther.(xauth.Lister); !ok {
+		return errors.New("wg: auther cannot enumerate the peers")
+	}
+
+	network := "udp"
+	if xnet.IsIPv4(l.options.Addr) {
+		network = "udp4"
+	}
+	laddr, err := net.ResolveUDPAddr(network, l.options.Addr)
+	if err != nil {
+		return
+	}
+
+	var conn net.PacketConn
+	conn, err = net.ListenUDP(network, laddr)
+	if err != nil {
+		return
+	}
+	l.addr = conn.LocalAddr()
+
+	conn = metrics.WrapPacketConn(l.options.Service, conn)
+	conn = stats.WrapPacketConn(conn, l.options.Stats)
+	conn = admission.WrapPacketConn(l.options.Admission, conn)
+	conn = limiter_wrapper.WrapPacketConn(
+		conn,
+		l.options.TrafficLimiter,
+		traffic_limiter.ServiceLimitKey,
+		limiter.ScopeOption(limiter.ScopeService),
+		limiter.ServiceOption(l.options.Service),
+		limiter.NetworkOption(conn.LocalAddr().Network()),
+	)
+
+	l.conn = conn
+	l.cqueue = make(chan net.Conn, l.md.backlog)
+	l.closed = make(chan struct{})
+
+	tun := wg_util.NewStackTUN(l.md.mtu)
+	l.dev = device.NewDevice(tun, wg_util.NewServerBind(conn), wg_util.NewLogger(l.log))
+
+	if err = l.dev.IpcSet(fmt.Sprintf("private_key=%s\n", l.md.privateKey)); err != nil {
+		l.dev.Close()
+		conn.Close()
+		return
+	}
+
+	var opts []option.Option
+	if l.md.tcpModerateReceiveBuffer {
+		opts = append(opts, option.WithTCPModerateReceiveBuffer(l.md.tcpModerateReceiveBuffer))
+	}
+	if l.md.tcpSendBufferSize > 0 {
+		opts = append(opts, option.WithTCPSendBufferSize(l.md.tcpSendBufferSize))
+	}
+	if l.md.tcpReceiveBufferSize > 0 {
+		opts = append(opts, option.WithTCPReceiveBufferSize(l.md.tcpReceiveBufferSize))
+	}
+	l.stack, err = core.CreateStack(&core.Config{
+		LinkEndpoint:     tun.LinkEndpoint(),
+		TransportHandler: l,
+		Options:          opts,
+	})
+	if err != nil {
+		l.dev.Close()
+		conn.Close()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	if err = l.reloadPeers(ctx); err != nil {
+		l.log.Warnf("reload peers: %v", err)
+	}
+
+	if err = l.dev.Up(); err != nil {
+		cancel()
+		l.stack.Close()
+		l.dev.Close()
+		conn.Close()
+		return
+	}
+
+	go l.syncPeers(ctx)
+
+	return
+}
+
+// HandleTCP implements adapter.TransportHandler.
+func (l *wgListener) HandleTCP(conn adapter.TCPConn) {
+	l.enqueue(conn)
+}
+
+// HandleUDP implements adapter.TransportHandler.
+func (l *wgListener) HandleUDP(conn adapter.UDPConn) {
+	l.enqueue(conn)
+}
+
+func (l *wgListener) enqueue(conn net.Conn) {
+	srcAddr := conn.RemoteAddr()
+	addr, err := netip.ParseAddrPort(srcAddr.String())
+	if err != nil {
+		conn.Close()
+		return
+	}
+	id := l.peerOf(addr.Addr())
+	if id == "" {
+		l.log.Warnf("no peer found for %s", srcAddr)
+		conn.Close()
+		return
+	}
+
+	ctx := xctx.ContextWithSrcAddr(context.Background(), srcAddr)
+	ctx = xctx.ContextWithDstAddr(ctx, conn.LocalAddr())
+	ctx = xctx.ContextWithClientID(ctx, xctx.ClientID(id))
+
+	select {
+	case l.cqueue <- &flowConn{Conn: conn, ctx: ctx}:
+	case <-l.closed:
+		conn.Close()
+	default:
+		conn.Close()
+		l.log.Warnf("connection queue is full, %s -> %s discarded", srcAddr, conn.LocalAddr())
+	}
+}
+
+func (l *wgListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.cqueue:
+		return conn, nil
+	case <-l.closed:
+	}
+
+	return nil, listener.ErrClosed
+}
+
+func (l *wgListener) Addr() net.Addr {
+	return l.addr
+}
+
+func (l *wgListener) Close() error {
+	select {
+	case <-l.closed:
+		return net.ErrClosed
+	default:
+		close(l.closed)
+	}
+
+	l.cancel()
+	l.stack.Close()
+	l.dev.Close()
+
+	return l.conn.Close()
+}
@@ -0,0 +1,157 @@
+package wg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/auth"
+	"github.com/go-gost/core/connector"
+	"github.com/go-gost/core/dialer"
+	"github.com/go-gost/core/listener"
+	xauth "github.com/go-gost/x/auth"
+	wg_connector "github.com/go-gost/x/connector/wg"
+	xctx "github.com/go-gost/x/ctx"
+	wg_dialer "github.com/go-gost/x/dialer/wg"
+	xlogger "github.com/go-gost/x/logger"
+	xmd "github.com/go-gost/x/metadata"
+	"golang.org/x/crypto/curve25519"
+)
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func (f dialFunc) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	return f(ctx, network, addr)
+}
+
+func genKey(t *testing.T) (private, public string) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := curve25519.X25519(b, curve25519.Basepoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(b), base64.StdEncoding.EncodeToString(pub)
+}
+
+type staticAuther struct{}
+
+func (staticAuther) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
+	return user, true
+}
+
+func TestListenerAuther(t *testing.T) {
+	serverPriv, _ := genKey(t)
+
+	ln := NewListener(
+		listener.AddrOption("127.0.0.1:0"),
+		listener.AutherOption(staticAuther{}),
+		listener.LoggerOption(xlogger.Nop()),
+	)
+	if err := ln.Init(xmd.NewMetadata(map[string]any{
+		"privateKey": serverPriv,
+	})); err == nil {
+		ln.Close()
+		t.Fatal("an auther which cannot enumerate the peers is accepted")
+	}
+}
+
+func TestListener(t *testing.T) {
+	serverPriv, serverPub := genKey(t)
+	clientPriv, clientPub := genKey(t)
+
+	ln := NewListener(
+		listener.AddrOption("127.0.0.1:0"),
+		listener.AutherOption(xauth.NewAuthenticator(
+			xauth.AuthsOption(map[string]string{clientPub: "10.9.0.2/32"}),
+			xauth.LoggerOption(xlogger.Nop()),
+		)),
+		listener.LoggerOption(xlogger.Nop()),
+	)
+	if err := ln.Init(xmd.NewMetadata(map[string]any{
+		"privateKey":         serverPriv,
+		"peers.reloadPeriod": "100ms",
+	})); err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	for i := 0; ln.(*wgListener).peerOf(netip.MustParseAddr("10.9.0.2")) == ""; i++ {
+		if i > 50 {
+			t.Fatal("peers are not loaded")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			ctx := c.(xctx.Context).Context()
+			go func() {
+				defer c.Close()
+				// reply with the client ID and the original destination.
+				c.Write([]byte(string(xctx.ClientIDFromContext(ctx)) + " " + c.LocalAddr().String()))
+				io.Copy(io.Discard, c)
+			}()
+		}
+	}()
+
+	d := wg_dialer.NewDialer(dialer.LoggerOption(xlogger.Nop()))
+	if err := d.Init(xmd.NewMetadata(map[string]any{
+		"privateKey": clientPriv,
+		"publicKey":  serverPub,
+		"address":    "10.9.0.2",
+	})); err != nil {
+		t.Fatal(err)
+	}
+	var nd net.Dialer
+	cc, err := d.Dial(context.Background(), ln.Addr().String(), dialer.NetDialerDialOption(dialFunc(nd.DialContext)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := wg_connector.NewConnector(connector.LoggerOption(xlogger.Nop()))
+	c.Init(nil)
+
+	for _, v := range []struct {
+		network string
+		addr    string
+	}{
+		{"tcp", "198.51.100.1:80"},
+		{"udp", "198.51.100.1:53"},
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		rc, err := c.Connect(ctx, cc, v.network, v.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+
+		if v.network == "udp" {
+			rc.Write([]byte("ping"))
+		}
+
+		want := clientPub + " " + v.addr
+		buf := make([]byte, 1024)
+		rc.SetReadDeadline(time.Now().Add(10 * time.Second))
+		n, err := rc.Read(buf)
+		if err != nil {
+			t.Fatalf("%s: %v", v.network, err)
+		}
+		if string(buf[:n]) != want {
+			t.Errorf("%s: got %q, want %q", v.network, buf[:n], want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package wg
+
+import (
+	"errors"
+	"time"
+
+	mdata "github.com/go-gost/core/metadata"
+	wg_util "github.com/go-gost/x/internal/util/wg"
+	mdutil "github.com/go-gost/x/metadata/util"
+)
+
+const (
+	defaultBacklog      = 128
+	defaultReloadPeriod = 10 * time.Second
+)
+
+type metadata struct {
+	privateKey   string
+	mtu          int
+	backlog      int
+	reloadPeriod time.Duration
+
+	tcpSendBufferSize        int
+	tcpReceiveBufferSize     int
+	tcpModerateReceiveBuffer bool
+}
+
+func (l *wgListener) parseMetadata(md mdata.Metadata) (err error) {
+	const (
+		privateKey   = "privateKey"
+		mtu          = "mtu"
+		backlog      = "backlog"
+		reloadPeriod = "peers.reloadPeriod"
+	)
+
+	if l.md.privateKey, err = wg_util.ParseKey(mdutil.GetString(md, privateKey)); err != nil {
+		return errors.New("wg: invalid private key")
+	}
+
+	l.md.mtu = mdutil.GetInt(md, mtu)
+	if l.md.mtu <= 0 {
+		l.md.mtu = wg_util.DefaultMTU
+	}
+	l.md.backlog = mdutil.GetInt(md, backlog)
+	if l.md.backlog <= 0 {
+		l.md.backlog = defaultBacklog
+	}
+	l.md.reloadPeriod = mdutil.GetDuration(md, reloadPeriod)
+	if l.md.reloadPeriod <= 0 {
+		l.md.reloadPeriod = defaultReloadPeriod
+	}
+
+	l.md.tcpSendBufferSize = mdutil.GetInt(md, "tcpSendBufferSize")
+	l.md.tcpReceiveBufferSize = mdutil.GetInt(md, "tcpReceiveBufferSize")
+	l.md.tcpModerateReceiveBuffer = mdutil.GetBool(md, "tcpModerateReceiveBuffer")
+
+	return
+}
@@ -0,0 +1,125 @@
+package wg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	xauth "github.com/go-gost/x/auth"
+	wg_util "github.com/go-gost/x/internal/util/wg"
+)
+
+type peer struct {
+	// public key in base64 encoding, used as the client ID.
+	id         string
+	key        string
+	allowedIPs []netip.Prefix
+}
+
+// peerOf returns the ID of the peer the tunnel address belongs to.
+// The address is always in the allowed IPs of the peer, as the device drops the packets from the other addresses.
+func (l *wgListener) peerOf(addr netip.Addr) string {
+	addr = addr.Unmap()
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var id string
+	bits := -1
+	for _, p := range l.peers {
+		for _, prefix := range p.allowedIPs {
+			if prefix.Bits() > bits && prefix.Contains(addr) {
+				id, bits = p.id, prefix.Bits()
+			}
+		}
+	}
+	return id
+}
+
+func (l *wgListener) syncPeers(ctx context.Context) {
+	if err := l.reloadPeers(ctx); err != nil {
+		l.log.Warnf("reload peers: %v", err)
+	}
+
+	ticker := time.NewTicker(l.md.reloadPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.reloadPeers(ctx); err != nil {
+				l.log.Warnf("reload peers: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reloadPeers applies the peers of the auther to the device,
+// the user is the public key of the peer and the password is the comma separated allowed IPs.
+func (l *wgListener) reloadPeers(ctx context.Context) error {
+	lister, ok := l.options.Auther.(xauth.Lister)
+	if !ok {
+		return errors.New("auther cannot enumerate the peers")
+	}
+	// the wrapped auther may not be registered yet or cannot list the users,
+	// the current peers are kept.
+	users := lister.List(ctx)
+	if users == nil {
+		return errors.New("auther cannot enumerate the peers")
+	}
+
+	peers := make(map[string]*peer)
+	for user, password := range users {
+		key, err := wg_util.ParseKey(user)
+		if err != nil {
+			l.log.Warnf("peer %s: %v", user, err)
+			continue
+		}
+		allowedIPs, err := wg_util.ParsePrefixes(password)
+		if err != nil || len(allowedIPs) == 0 {
+			l.log.Warnf("peer %s: invalid allowed IPs %q", user, password)
+			continue
+		}
+		peers[key] = &peer{
+			id:         user,
+			key:        key,
+			allowedIPs: allowedIPs,
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var b strings.Builder
+	for key := range l.peers {
+		if _, ok := peers[key]; !ok {
+			fmt.Fprintf(&b, "public_key=%s\nremove=true\n", key)
+		}
+	}
+	for key, p := range peers {
+		if old := l.peers[key]; old != nil && slices.Equal(old.allowedIPs, p.allowedIPs) {
+			continue
+		}
+		fmt.Fprintf(&b, "public_key=%s\nreplace_allowed_ips=true\n", key)
+		for _, prefix := range p.allowedIPs {
+			fmt.Fprintf(&b, "allowed_ip=%s\n", prefix)
+		}
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+
+	if err := l.dev.IpcSet(b.String()); err != nil {
+		return err
+	}
+	l.peers = peers
+	l.log.Debugf("load peers %d", len(peers))
+
+	return nil
+}
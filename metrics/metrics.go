@@ -25,8 +25,26 @@ const (
 	MetricServiceHandlerErrorsCounter metrics.MetricName = "gost_service_handler_errors_total"
 	// Total chain connect errors. Labels: host, chain, node.
 	MetricChainErrorsCounter metrics.MetricName = "gost_chain_errors_total"
+	// Chain node health status, 1 for healthy and 0 for unhealthy. Labels: host, hop, node.
+	MetricNodeHealthGauge metrics.MetricName = "gost_chain_node_healthy"
+	// Chain node health check duration histogram. Labels: host, hop, node.
+	MetricNodeHealthCheckDurationObserver metrics.MetricName = "gost_chain_node_health_check_duration_seconds"
 	// Total recorder records. Labels: host, recorder.
 	MetricRecorderRecordsCounter metrics.MetricName = "gost_recorder_records_total"
+	// Total records dropped by the buffered recorder. Labels: host, recorder.
+	MetricRecorderDropsCounter metrics.MetricName = "gost_recorder_records_dropped_total"
+	// Total authentication failures. Labels: host, auther.
+	MetricAuthFailuresCounter metrics.MetricName = "gost_auth_failures_total"
+	// Total authentication attempts rejected by lockout. Labels: host, auther.
+	MetricAuthRejectsCounter metrics.MetricName = "gost_auth_rejects_total"
+	// Total client IP and username bans. Labels: host, auther.
+	MetricAuthBansCounter metrics.MetricName = "gost_auth_bans_total"
+	// Total input data transfer size of the client in bytes reported by the observer. Labels: host, service, client.
+	MetricClientInputBytesCounter metrics.MetricName = "gost_client_input_bytes_total"
+	// Total output data transfer size of the client in bytes reported by the observer. Labels: host, service, client.
+	MetricClientOutputBytesCounter metrics.MetricName = "gost_client_output_bytes_total"
+	// Number of current connections of the client reported by the observer. Labels: host, service, client.
+	MetricClientConnsGauge metrics.MetricName = "gost_client_connections"
 )
 
 var (
@@ -31,6 +31,18 @@ func NewMetrics() metrics.Metrics {
 					Help: "Current in-flight requests",
 				},
 				[]string{"host", "service", "client"}),
+			MetricNodeHealthGauge: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Name: string(MetricNodeHealthGauge),
+					Help: "Chain node health status",
+				},
+				[]string{"host", "hop", "node"}),
+			MetricClientConnsGauge: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Name: string(MetricClientConnsGauge),
+					Help: "Current number of client connections",
+				},
+				[]string{"host", "service", "client"}),
 		},
 		counters: map[metrics.MetricName]*prometheus.CounterVec{
 			MetricServiceRequestsCounter: prometheus.NewCounterVec(
@@ -69,6 +81,42 @@ func NewMetrics() metrics.Metrics {
 					Help: "Total records written by recorder",
 				},
 				[]string{"host", "recorder"}),
+			MetricRecorderDropsCounter: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: string(MetricRecorderDropsCounter),
+					Help: "Total records dropped by buffered recorder",
+				},
+				[]string{"host", "recorder"}),
+			MetricAuthFailuresCounter: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: string(MetricAuthFailuresCounter),
+					Help: "Total authentication failures",
+				},
+				[]string{"host", "auther"}),
+			MetricAuthRejectsCounter: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: string(MetricAuthRejectsCounter),
+					Help: "Total authentication attempts rejected by lockout",
+				},
+				[]string{"host", "auther"}),
+			MetricAuthBansCounter: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: string(MetricAuthBansCounter),
+					Help: "Total client IP and username bans",
+				},
+				[]string{"host", "auther"}),
+			MetricClientInputBytesCounter: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: string(MetricClientInputBytesCounter),
+					Help: "Total input data transfer size of client in bytes",
+				},
+				[]string{"host", "service", "client"}),
+			MetricClientOutputBytesCounter: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: string(MetricClientOutputBytesCounter),
+					Help: "Total output data transfer size of client in bytes",
+				},
+				[]string{"host", "service", "client"}),
 		},
 		histograms: map[metrics.MetricName]*prometheus.HistogramVec{
 			MetricServiceRequestsDurationObserver: prometheus.NewHistogramVec(
@@ -89,6 +137,15 @@ func NewMetrics() metrics.Metrics {
 					},
 				},
 				[]string{"host", "chain", "node"}),
+			MetricNodeHealthCheckDurationObserver: prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Name: string(MetricNodeHealthCheckDurationObserver),
+					Help: "Distribution of chain node health check latencies",
+					Buckets: []float64{
+						.01, .05, .1, .25, .5, 1, 1.5, 2, 5, 10, 15, 30, 60,
+					},
+				},
+				[]string{"host", "hop", "node"}),
 		},
 	}
 	for k := range m.gauges {
@@ -0,0 +1,65 @@
+package observer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/go-gost/core/observer"
+)
+
+type fileObserver struct {
+	out        io.WriteCloser
+	aggregator *aggregator
+	mu         sync.Mutex
+}
+
+// FileObserver writes the usages of each observe period and the status events to the output as JSON lines.
+func FileObserver(out io.WriteCloser, opts ...Option) observer.Observer {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &fileObserver{
+		out:        out,
+		aggregator: newAggregator(options.resetTraffic),
+	}
+}
+
+func (o *fileObserver) Observe(ctx context.Context, events []observer.Event, opts ...observer.Option) error {
+	usages, statuses, commit := o.aggregator.aggregate(events)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, v := range statuses {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	for _, v := range usages {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	if buf.Len() == 0 {
+		commit()
+		return nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, err := o.out.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	commit()
+
+	return nil
+}
+
+func (o *fileObserver) Close() error {
+	return o.out.Close()
+}
@@ -0,0 +1,53 @@
+package observer
+
+import (
+	"context"
+
+	"github.com/go-gost/core/metrics"
+	"github.com/go-gost/core/observer"
+	xmetrics "github.com/go-gost/x/metrics"
+)
+
+type metricsObserver struct {
+	aggregator *aggregator
+}
+
+// MetricsObserver exports the traffic of the clients as counters and the connections as gauges on the metrics endpoint.
+// The traffic is accumulated since the observer starts, so it keeps increasing when the handlers reset their stats.
+func MetricsObserver(opts ...Option) observer.Observer {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &metricsObserver{
+		aggregator: newAggregator(options.resetTraffic),
+	}
+}
+
+func (o *metricsObserver) Observe(ctx context.Context, events []observer.Event, opts ...observer.Option) error {
+	usages, _, commit := o.aggregator.aggregate(events)
+	defer commit()
+
+	for _, u := range usages {
+		if u.Client == "" {
+			continue
+		}
+
+		labels := metrics.Labels{
+			"service": u.Service,
+			"client":  u.Client,
+		}
+		if v := xmetrics.GetCounter(xmetrics.MetricClientInputBytesCounter, labels); v != nil {
+			v.Add(float64(u.InputBytes))
+		}
+		if v := xmetrics.GetCounter(xmetrics.MetricClientOutputBytesCounter, labels); v != nil {
+			v.Add(float64(u.OutputBytes))
+		}
+		if v := xmetrics.GetGauge(xmetrics.MetricClientConnsGauge, labels); v != nil {
+			v.Set(float64(u.CurrentConns))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package observer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/observer"
+	xstats "github.com/go-gost/x/observer/stats"
+	"github.com/go-gost/x/service"
+)
+
+type options struct {
+	resetTraffic bool
+	db           int
+	username     string
+	password     string
+	key          string
+}
+
+type Option func(opts *options)
+
+// ResetTrafficOption indicates that the traffic in the stats events is the increment since the last observation,
+// it should match the observer.resetTraffic option of the handlers.
+func ResetTrafficOption(b bool) Option {
+	return func(opts *options) {
+		opts.resetTraffic = b
+	}
+}
+
+func DBOption(db int) Option {
+	return func(opts *options) {
+		opts.db = db
+	}
+}
+
+func UsernameOption(username string) Option {
+	return func(opts *options) {
+		opts.username = username
+	}
+}
+
+func PasswordOption(password string) Option {
+	return func(opts *options) {
+		opts.password = password
+	}
+}
+
+func KeyOption(key string) Option {
+	return func(opts *options) {
+		opts.key = key
+	}
+}
+
+// usage is the stats of a service or a client during an observe period.
+type usage struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Kind    string    `json:"kind"`
+	Service string    `json:"service"`
+	Client  string    `json:"client,omitempty"`
+
+	// the new connections, traffic and errors in the period.
+	Conns        uint64 `json:"conns"`
+	CurrentConns uint64 `json:"currentConns"`
+	InputBytes   uint64 `json:"inputBytes"`
+	OutputBytes  uint64 `json:"outputBytes"`
+	Errs         uint64 `json:"errs"`
+
+	// the time since the last observation, zero for the first one.
+	elapsed time.Duration
+}
+
+// status is the service status event.
+type status struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Kind    string    `json:"kind"`
+	Service string    `json:"service"`
+	State   string    `json:"state"`
+	Msg     string    `json:"msg,omitempty"`
+}
+
+type statsKey struct {
+	kind    string
+	service string
+	client  string
+}
+
+type statsValue struct {
+	conns        uint64
+	currentConns uint64
+	inputBytes   uint64
+	outputBytes  uint64
+	errs         uint64
+	time         time.Time
+	// the observation of the service in which the value is reported.
+	seq uint64
+}
+
+const (
+	// staleObservations is the number of the observations of a service
+	// after which the last values of its idle clients not reported in them are dropped.
+	staleObservations = 3
+)
+
+// aggregator converts the stats events of an observation to the usages of the observe period.
+// The handlers report the cumulative stats, the aggregator keeps the last values to get the increments.
+// The stats of a service start over when it is (re)started or closed,
+// so the last values of the service are dropped on these status events.
+// The last values of the clients without connections are dropped after staleObservations observations
+// in which they are not reported, so the churn of the clients does not grow the values.
+// Such a client coming back later is counted from the totals reported by the handler, as a new one.
+type aggregator struct {
+	resetTraffic bool
+	last         map[statsKey]statsValue
+	// the number of the committed observations of the services.
+	seqs map[string]uint64
+	mu   sync.Mutex
+}
+
+func newAggregator(resetTraffic bool) *aggregator {
+	return &aggregator{
+		resetTraffic: resetTraffic,
+		last:         make(map[statsKey]statsValue),
+		seqs:         make(map[string]uint64),
+	}
+}
+
+// aggregate returns the usages and the status of the events,
+// the commit function should be called after the usages are stored,
+// so the events resent by the handlers on failure are not lost.
+func (a *aggregator) aggregate(events []observer.Event) (usages []*usage, statuses []*status, commit func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	last := make(map[statsKey]statsValue)
+	m := make(map[statsKey]*usage)
+	// the services whose last values are dropped.
+	var services []string
+
+	for _, e := range events {
+		switch ev := e.(type) {
+		case service.ServiceEvent:
+			statuses = append(statuses, &status{
+				Time:    now,
+				Type:    string(ev.Type()),
+				Kind:    ev.Kind,
+				Service: ev.Service,
+				State:   string(ev.State),
+				Msg:     ev.Msg,
+			})
+			if ev.State == service.StateRunning || ev.State == service.StateClosed {
+				services = append(services, ev.Service)
+			}
+
+		case xstats.StatsEvent:
+			key := statsKey{kind: ev.Kind, service: ev.Service, client: ev.Client}
+			prev, ok := last[key]
+			if !ok {
+				prev = a.last[key]
+			}
+			cur := statsValue{
+				conns:        ev.TotalConns,
+				currentConns: ev.CurrentConns,
+				inputBytes:   ev.InputBytes,
+				outputBytes:  ev.OutputBytes,
+				errs:         ev.TotalErrs,
+				time:         now,
+			}
+
+			u := m[key]
+			if u == nil {
+				u = &usage{
+					Time:    now,
+					Type:    string(ev.Type()),
+					Kind:    ev.Kind,
+					Service: ev.Service,
+					Client:  ev.Client,
+				}
+				m[key] = u
+				usages = append(usages, u)
+			}
+			u.CurrentConns = ev.CurrentConns
+			if !prev.time.IsZero() && prev.time.Before(now) {
+				u.elapsed = now.Sub(prev.time)
+			}
+			u.Conns += delta(cur.conns, prev.conns)
+			u.Errs += delta(cur.errs, prev.errs)
+			if a.resetTraffic {
+				u.InputBytes += cur.inputBytes
+				u.OutputBytes += cur.outputBytes
+			} else {
+				u.InputBytes += delta(cur.inputBytes, prev.inputBytes)
+				u.OutputBytes += delta(cur.outputBytes, prev.outputBytes)
+			}
+
+			last[key] = cur
+		}
+	}
+
+	commit = func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		for _, s := range services {
+			for k := range a.last {
+				if k.service == s {
+					delete(a.last, k)
+				}
+			}
+			delete(a.seqs, s)
+		}
+
+		observed := make(map[string]bool)
+		for k := range last {
+			if !observed[k.service] {
+				observed[k.service] = true
+				a.seqs[k.service]++
+			}
+		}
+		for k, v := range last {
+			v.seq = a.seqs[k.service]
+			a.last[k] = v
+		}
+
+		for k, v := range a.last {
+			if k.client != "" && v.currentConns == 0 && observed[k.service] &&
+				a.seqs[k.service]-v.seq >= staleObservations {
+				delete(a.last, k)
+			}
+		}
+	}
+	return
+}
+
+// delta returns the increment of a cumulative value, a smaller value means the stats have been reset.
+func delta(cur, prev uint64) uint64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
@@ -0,0 +1,162 @@
+package observer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/go-gost/core/observer"
+	xstats "github.com/go-gost/x/observer/stats"
+	"github.com/go-gost/x/service"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (bufferCloser) Close() error { return nil }
+
+func statsEvent(client string, conns, in, out uint64) observer.Event {
+	return xstats.StatsEvent{
+		Kind:        "handler",
+		Service:     "svc",
+		Client:      client,
+		TotalConns:  conns,
+		InputBytes:  in,
+		OutputBytes: out,
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	a := newAggregator(false)
+
+	usages, _, commit := a.aggregate([]observer.Event{statsEvent("u1", 2, 100, 1000)})
+	if u := usages[0]; u.Conns != 2 || u.InputBytes != 100 || u.OutputBytes != 1000 {
+		t.Fatalf("unexpected usage %+v", u)
+	}
+	commit()
+
+	// not committed, the events are resent.
+	a.aggregate([]observer.Event{statsEvent("u1", 3, 150, 1500)})
+	usages, _, commit = a.aggregate([]observer.Event{statsEvent("u1", 3, 150, 1500)})
+	if u := usages[0]; u.Conns != 1 || u.InputBytes != 50 || u.OutputBytes != 500 {
+		t.Fatalf("unexpected usage %+v", u)
+	}
+	commit()
+
+	// the stats are reset.
+	usages, _, _ = a.aggregate([]observer.Event{statsEvent("u1", 1, 10, 20)})
+	if u := usages[0]; u.Conns != 1 || u.InputBytes != 10 || u.OutputBytes != 20 {
+		t.Fatalf("unexpected usage %+v", u)
+	}
+
+	a = newAggregator(true)
+	for i := 0; i < 2; i++ {
+		usages, _, commit = a.aggregate([]observer.Event{statsEvent("u1", uint64(i+1), 10, 20)})
+		if u := usages[0]; u.Conns != 1 || u.InputBytes != 10 || u.OutputBytes != 20 {
+			t.Fatalf("unexpected usage %+v", u)
+		}
+		commit()
+	}
+}
+
+func TestAggregatorServiceEvent(t *testing.T) {
+	a := newAggregator(false)
+
+	_, _, commit := a.aggregate([]observer.Event{statsEvent("u1", 2, 100, 1000), statsEvent("u2", 1, 10, 10)})
+	commit()
+
+	// the service is restarted, the stats of the new handler start over.
+	_, statuses, commit := a.aggregate([]observer.Event{service.ServiceEvent{
+		Kind:    "service",
+		Service: "svc",
+		State:   service.StateRunning,
+	}})
+	if len(statuses) != 1 {
+		t.Fatalf("got %d statuses, want 1", len(statuses))
+	}
+	commit()
+	if n := len(a.last); n != 0 {
+		t.Fatalf("got %d last values, want 0", n)
+	}
+
+	usages, _, _ := a.aggregate([]observer.Event{statsEvent("u1", 3, 150, 1500)})
+	if u := usages[0]; u.Conns != 3 || u.InputBytes != 150 || u.OutputBytes != 1500 {
+		t.Fatalf("unexpected usage %+v", u)
+	}
+}
+
+func TestAggregatorEviction(t *testing.T) {
+	a := newAggregator(false)
+
+	active := xstats.StatsEvent{Kind: "handler", Service: "svc", Client: "u2", TotalConns: 1, CurrentConns: 1}
+	_, _, commit := a.aggregate([]observer.Event{statsEvent("u1", 2, 100, 1000), active})
+	commit()
+
+	other := xstats.StatsEvent{Kind: "handler", Service: "other", Client: "u1", TotalConns: 1}
+	for i := 0; i < staleObservations; i++ {
+		// the observations of the other services do not count.
+		_, _, commit = a.aggregate([]observer.Event{other})
+		commit()
+	}
+	if _, ok := a.last[statsKey{kind: "handler", service: "svc", client: "u1"}]; !ok {
+		t.Fatal("last value of u1 is dropped by the observations of the other service")
+	}
+
+	for i := 0; i < staleObservations; i++ {
+		if _, ok := a.last[statsKey{kind: "handler", service: "svc", client: "u1"}]; !ok {
+			t.Fatalf("last value of u1 is dropped after %d observations", i)
+		}
+		_, _, commit = a.aggregate([]observer.Event{statsEvent(fmt.Sprintf("c%d", i), 1, 10, 10)})
+		commit()
+	}
+
+	if _, ok := a.last[statsKey{kind: "handler", service: "svc", client: "u1"}]; ok {
+		t.Error("last value of the idle client u1 is not dropped")
+	}
+	// the client with connections is kept.
+	if _, ok := a.last[statsKey{kind: "handler", service: "svc", client: "u2"}]; !ok {
+		t.Error("last value of the active client u2 is dropped")
+	}
+
+	usages, _, _ := a.aggregate([]observer.Event{statsEvent("c2", 2, 15, 15)})
+	if u := usages[0]; u.Conns != 1 || u.InputBytes != 5 || u.OutputBytes != 5 {
+		t.Fatalf("unexpected usage %+v", u)
+	}
+}
+
+func TestFileObserver(t *testing.T) {
+	var out bufferCloser
+	o := FileObserver(&out)
+
+	o.Observe(context.Background(), []observer.Event{
+		service.ServiceEvent{Kind: "service", Service: "svc", State: "ready"},
+		statsEvent("u1", 1, 10, 20),
+		statsEvent("u2", 2, 30, 40),
+	})
+	o.Observe(context.Background(), []observer.Event{
+		statsEvent("u1", 2, 15, 25),
+	})
+
+	var lines []map[string]any
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		m := map[string]any{}
+		if err := dec.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, m)
+	}
+
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4", len(lines))
+	}
+	if lines[0]["type"] != "status" || lines[0]["state"] != "ready" {
+		t.Errorf("unexpected status %v", lines[0])
+	}
+	if v := lines[3]; v["client"] != "u1" || v["conns"] != 1.0 || v["inputBytes"] != 5.0 || v["outputBytes"] != 5.0 {
+		t.Errorf("unexpected usage %v", v)
+	}
+}
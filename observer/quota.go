@@ -0,0 +1,62 @@
+package observer
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/go-gost/core/observer"
+	"github.com/go-gost/x/quota"
+)
+
+type quotaObserver struct {
+	quota      quota.Quota
+	observer   observer.Observer
+	aggregator *aggregator
+}
+
+// QuotaObserver accounts the traffic and connection time of the clients to the quota q,
+// then passes the events to the observer o if it is not nil.
+// The connection time is estimated by the current connections of the client in each observe period.
+func QuotaObserver(q quota.Quota, o observer.Observer, opts ...Option) observer.Observer {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &quotaObserver{
+		quota:      q,
+		observer:   o,
+		aggregator: newAggregator(options.resetTraffic),
+	}
+}
+
+func (o *quotaObserver) Observe(ctx context.Context, events []observer.Event, opts ...observer.Option) error {
+	usages, _, commit := o.aggregator.aggregate(events)
+
+	// the events are resent by the handlers on failure, they are accounted then.
+	if o.observer != nil {
+		if err := o.observer.Observe(ctx, events, opts...); err != nil {
+			return err
+		}
+	}
+
+	for _, u := range usages {
+		if u.Client == "" {
+			continue
+		}
+		o.quota.Add(ctx, u.Client,
+			int64(u.InputBytes+u.OutputBytes),
+			time.Duration(u.CurrentConns)*u.elapsed)
+	}
+	commit()
+
+	return nil
+}
+
+func (o *quotaObserver) Close() error {
+	if closer, ok := o.observer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
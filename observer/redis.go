@@ -0,0 +1,79 @@
+package observer
+
+import (
+	"context"
+
+	"github.com/go-gost/core/observer"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	defaultRedisKey = "gost:observer"
+)
+
+type redisObserver struct {
+	client     *redis.Client
+	key        string
+	aggregator *aggregator
+}
+
+// RedisObserver accumulates the usages of the clients in redis hashes for accounting,
+// the hash <key>:<client> has the fields inputBytes, outputBytes, totalConns and totalErrs,
+// which are increased by HINCRBY in each observe period.
+func RedisObserver(addr string, opts ...Option) observer.Observer {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	key := options.key
+	if key == "" {
+		key = defaultRedisKey
+	}
+
+	return &redisObserver{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Username: options.username,
+			Password: options.password,
+			DB:       options.db,
+		}),
+		key:        key,
+		aggregator: newAggregator(options.resetTraffic),
+	}
+}
+
+func (o *redisObserver) Observe(ctx context.Context, events []observer.Event, opts ...observer.Option) error {
+	usages, _, commit := o.aggregator.aggregate(events)
+
+	var clients []*usage
+	for _, u := range usages {
+		if u.Client == "" ||
+			u.InputBytes == 0 && u.OutputBytes == 0 && u.Conns == 0 && u.Errs == 0 {
+			continue
+		}
+		clients = append(clients, u)
+	}
+
+	if len(clients) > 0 {
+		if _, err := o.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, u := range clients {
+				key := o.key + ":" + u.Client
+				pipe.HIncrBy(ctx, key, "inputBytes", int64(u.InputBytes))
+				pipe.HIncrBy(ctx, key, "outputBytes", int64(u.OutputBytes))
+				pipe.HIncrBy(ctx, key, "totalConns", int64(u.Conns))
+				pipe.HIncrBy(ctx, key, "totalErrs", int64(u.Errs))
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	commit()
+
+	return nil
+}
+
+func (o *redisObserver) Close() error {
+	return o.client.Close()
+}
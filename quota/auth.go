@@ -0,0 +1,82 @@
+package quota
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-gost/core/auth"
+	"github.com/go-gost/x/auth/lockout"
+)
+
+type authenticator struct {
+	auther auth.Authenticator
+	quota  Quota
+}
+
+// WrapAuthenticator wraps the authenticator a to reject the clients which have used up their quota q.
+// The client is identified by the ID returned by a, or the user if the ID is empty.
+func WrapAuthenticator(a auth.Authenticator, q Quota) auth.Authenticator {
+	if a == nil || q == nil {
+		return a
+	}
+	return &authenticator{
+		auther: a,
+		quota:  q,
+	}
+}
+
+func (p *authenticator) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
+	id, ok := p.auther.Authenticate(ctx, user, password, opts...)
+	if !ok {
+		return id, ok
+	}
+
+	client := id
+	if client == "" {
+		client = user
+	}
+	if p.quota.Exceeded(ctx, client) {
+		return "", false
+	}
+	return id, ok
+}
+
+// AuthenticateCert forwards the certificate authentication to the wrapped authenticator,
+// the client which has used up the quota is rejected.
+func (p *authenticator) AuthenticateCert(ctx context.Context, opts ...auth.Option) (string, bool) {
+	v, ok := p.auther.(interface {
+		AuthenticateCert(ctx context.Context, opts ...auth.Option) (string, bool)
+	})
+	if !ok {
+		return "", false
+	}
+	id, ok := v.AuthenticateCert(ctx, opts...)
+	if !ok || p.quota.Exceeded(ctx, id) {
+		return "", false
+	}
+	return id, ok
+}
+
+func (p *authenticator) List(ctx context.Context) map[string]string {
+	if lister, ok := p.auther.(interface {
+		List(ctx context.Context) map[string]string
+	}); ok {
+		return lister.List(ctx)
+	}
+	return nil
+}
+
+// Tracker returns the lockout tracker of the wrapped authenticator, if any.
+func (p *authenticator) Tracker() *lockout.Tracker {
+	if v, ok := p.auther.(interface{ Tracker() *lockout.Tracker }); ok {
+		return v.Tracker()
+	}
+	return nil
+}
+
+func (p *authenticator) Close() error {
+	if closer, ok := p.auther.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
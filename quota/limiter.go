@@ -0,0 +1,123 @@
+package quota
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-gost/core/limiter"
+	"github.com/go-gost/core/limiter/traffic"
+	xtraffic "github.com/go-gost/x/limiter/traffic"
+)
+
+type floorLimiters struct {
+	in  traffic.Limiter
+	out traffic.Limiter
+}
+
+// In implements traffic.TrafficLimiter, the over-quota client is limited to the floor rate.
+func (q *quota) In(ctx context.Context, key string, opts ...limiter.Option) traffic.Limiter {
+	if v := q.floor(ctx, key, opts...); v != nil {
+		return v.in
+	}
+	return nil
+}
+
+// Out implements traffic.TrafficLimiter, the over-quota client is limited to the floor rate.
+func (q *quota) Out(ctx context.Context, key string, opts ...limiter.Option) traffic.Limiter {
+	if v := q.floor(ctx, key, opts...); v != nil {
+		return v.out
+	}
+	return nil
+}
+
+func (q *quota) floor(ctx context.Context, key string, opts ...limiter.Option) *floorLimiters {
+	if q.options.rate <= 0 {
+		return nil
+	}
+
+	var options limiter.Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Scope != limiter.ScopeClient {
+		return nil
+	}
+	client := options.Client
+	if client == "" {
+		client = key
+	}
+
+	if !q.Exceeded(ctx, client) {
+		q.limiters.Delete(client)
+		return nil
+	}
+
+	// the limiters are shared by the connections of the client.
+	v, _ := q.limiters.LoadOrStore(client, &floorLimiters{
+		in:  xtraffic.NewLimiter(q.options.rate),
+		out: xtraffic.NewLimiter(q.options.rate),
+	})
+	return v.(*floorLimiters)
+}
+
+type trafficLimiter struct {
+	limiter traffic.TrafficLimiter
+	quota   traffic.TrafficLimiter
+}
+
+// WrapTrafficLimiter limits the over-quota clients to the floor rate of the quota q,
+// the lower limit of the traffic limiter l is kept.
+func WrapTrafficLimiter(l traffic.TrafficLimiter, q Quota) traffic.TrafficLimiter {
+	ql, ok := q.(traffic.TrafficLimiter)
+	if !ok {
+		return l
+	}
+	return &trafficLimiter{
+		limiter: l,
+		quota:   ql,
+	}
+}
+
+func (p *trafficLimiter) In(ctx context.Context, key string, opts ...limiter.Option) traffic.Limiter {
+	var lim traffic.Limiter
+	if p.limiter != nil {
+		lim = p.limiter.In(ctx, key, opts...)
+	}
+	return p.lower(lim, p.quota.In(ctx, key, opts...), opts...)
+}
+
+func (p *trafficLimiter) Out(ctx context.Context, key string, opts ...limiter.Option) traffic.Limiter {
+	var lim traffic.Limiter
+	if p.limiter != nil {
+		lim = p.limiter.Out(ctx, key, opts...)
+	}
+	return p.lower(lim, p.quota.Out(ctx, key, opts...), opts...)
+}
+
+func (p *trafficLimiter) lower(lim, floor traffic.Limiter, opts ...limiter.Option) traffic.Limiter {
+	if floor == nil {
+		if lim == nil {
+			var options limiter.Options
+			for _, opt := range opts {
+				opt(&options)
+			}
+			if options.Scope == limiter.ScopeClient {
+				// the cached limiters of the handlers are only replaced by a non-nil limiter,
+				// an unlimited one lifts the throttling once the quota is reset.
+				return xtraffic.NewLimiter(0)
+			}
+		}
+		return lim
+	}
+	if lim != nil && lim.Limit() > 0 && lim.Limit() < floor.Limit() {
+		return lim
+	}
+	return floor
+}
+
+func (p *trafficLimiter) Close() error {
+	if closer, ok := p.limiter.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
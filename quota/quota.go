@@ -0,0 +1,468 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+)
+
+const (
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+	PeriodTotal   = "total"
+
+	defaultSyncPeriod = 10 * time.Second
+)
+
+// Quota accounts the usage of the clients against their allowances.
+type Quota interface {
+	// Add accounts the traffic and connection time of the client.
+	Add(ctx context.Context, client string, bytes int64, connTime time.Duration)
+	// Exceeded reports whether the client has used up any of its allowances.
+	Exceeded(ctx context.Context, client string) bool
+	// Usage returns the usage and remaining quota of the client in the current periods.
+	Usage(ctx context.Context, client string) *ClientUsage
+	// Clients returns the clients with usage in the current periods.
+	Clients(ctx context.Context) []string
+}
+
+// Limit is the allowance of a client in a period, zero means unlimited.
+type Limit struct {
+	// daily, monthly or total.
+	Period   string
+	Bytes    int64
+	ConnTime time.Duration
+}
+
+// ClientUsage is the usage of a client.
+type ClientUsage struct {
+	Client   string   `json:"client"`
+	Exceeded bool     `json:"exceeded"`
+	Usages   []*Usage `json:"usages"`
+}
+
+// Usage is the usage of a client in a period.
+type Usage struct {
+	Period string `json:"period"`
+	// the current period, such as 2006-01-02 for daily and 2006-01 for monthly, empty for total.
+	Bucket string `json:"bucket,omitempty"`
+	// the end of the current period, absent for total.
+	Reset *time.Time `json:"reset,omitempty"`
+	// traffic in bytes, and connection time in seconds.
+	Bytes    int64 `json:"bytes"`
+	ConnTime int64 `json:"connTime"`
+	// the allowances, zero means unlimited.
+	MaxBytes    int64 `json:"maxBytes,omitempty"`
+	MaxConnTime int64 `json:"maxConnTime,omitempty"`
+	// the remaining quota, absent if unlimited.
+	RemainingBytes    *int64 `json:"remainingBytes,omitempty"`
+	RemainingConnTime *int64 `json:"remainingConnTime,omitempty"`
+	Exceeded          bool   `json:"exceeded"`
+}
+
+type options struct {
+	limits       []Limit
+	clientLimits map[string][]Limit
+	rate         int
+	syncPeriod   time.Duration
+	store        Store
+	logger       logger.Logger
+}
+
+type Option func(opts *options)
+
+// LimitsOption sets the default allowances of the clients.
+func LimitsOption(limits ...Limit) Option {
+	return func(opts *options) {
+		opts.limits = limits
+	}
+}
+
+// ClientLimitsOption sets the allowances of the specific clients, which override the default ones.
+func ClientLimitsOption(limits map[string][]Limit) Option {
+	return func(opts *options) {
+		opts.clientLimits = limits
+	}
+}
+
+// RateOption sets the floor rate in bytes per second of the over-quota clients,
+// zero means the over-quota clients are not throttled.
+func RateOption(rate int) Option {
+	return func(opts *options) {
+		opts.rate = rate
+	}
+}
+
+// SyncPeriodOption sets the interval to persist the usage to the store and refresh it.
+func SyncPeriodOption(period time.Duration) Option {
+	return func(opts *options) {
+		opts.syncPeriod = period
+	}
+}
+
+func StoreOption(store Store) Option {
+	return func(opts *options) {
+		opts.store = store
+	}
+}
+
+func LoggerOption(logger logger.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+// Counters is the usage of a client in the buckets, the key is the bucket, such as daily:2006-01-02.
+type Counters map[string]*Counter
+
+// Counter is the usage in a bucket.
+type Counter struct {
+	Bytes int64 `json:"bytes"`
+	// connection time in seconds.
+	ConnTime int64 `json:"connTime"`
+}
+
+func (c Counters) add(other Counters) {
+	for k, v := range other {
+		cnt := c[k]
+		if cnt == nil {
+			cnt = &Counter{}
+			c[k] = cnt
+		}
+		cnt.Bytes += v.Bytes
+		cnt.ConnTime += v.ConnTime
+	}
+}
+
+type quota struct {
+	// the usage of the clients, including the pending usage.
+	usage map[string]Counters
+	// the usage which has not been persisted.
+	pending map[string]Counters
+	// the connection time less than a second, which is carried over.
+	fractions map[string]time.Duration
+	periods   []string
+	limiters  sync.Map
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	done      chan struct{}
+	options   options
+}
+
+// NewQuota creates a quota, the usage is kept in memory if no store is set.
+func NewQuota(opts ...Option) Quota {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.syncPeriod <= 0 {
+		options.syncPeriod = defaultSyncPeriod
+	}
+	if options.logger == nil {
+		options.logger = logger.Default()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &quota{
+		usage:     make(map[string]Counters),
+		pending:   make(map[string]Counters),
+		fractions: make(map[string]time.Duration),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		options:   options,
+	}
+
+	m := make(map[string]bool)
+	for _, limits := range append([][]Limit{options.limits}, mapValues(options.clientLimits)...) {
+		for _, limit := range limits {
+			if !m[limit.Period] {
+				m[limit.Period] = true
+				q.periods = append(q.periods, limit.Period)
+			}
+		}
+	}
+
+	if options.store != nil {
+		if err := q.sync(ctx); err != nil {
+			options.logger.Warnf("quota: sync: %v", err)
+		}
+	}
+	go q.periodSync(ctx)
+
+	return q
+}
+
+func (q *quota) Add(ctx context.Context, client string, bytes int64, connTime time.Duration) {
+	if client == "" || bytes <= 0 && connTime <= 0 {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	connTime += q.fractions[client]
+	seconds := int64(connTime / time.Second)
+	q.fractions[client] = connTime % time.Second
+
+	delta := make(Counters)
+	for _, bucket := range q.buckets(time.Now()) {
+		delta[bucket] = &Counter{Bytes: bytes, ConnTime: seconds}
+	}
+
+	for _, m := range []map[string]Counters{q.usage, q.pending} {
+		c := m[client]
+		if c == nil {
+			c = make(Counters)
+			m[client] = c
+		}
+		c.add(delta)
+	}
+}
+
+func (q *quota) Exceeded(ctx context.Context, client string) bool {
+	for _, u := range q.usages(ctx, client, true) {
+		if u.Exceeded {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *quota) Usage(ctx context.Context, client string) *ClientUsage {
+	cu := &ClientUsage{
+		Client: client,
+		Usages: q.usages(ctx, client, false),
+	}
+	for _, u := range cu.Usages {
+		if u.Exceeded {
+			cu.Exceeded = true
+		}
+	}
+	return cu
+}
+
+func (q *quota) Clients(ctx context.Context) (clients []string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for client := range q.usage {
+		clients = append(clients, client)
+	}
+	return
+}
+
+// usages returns the usage of the client in the periods of its limits,
+// the usage fetched from the store is kept if keep is true.
+func (q *quota) usages(ctx context.Context, client string, keep bool) (usages []*Usage) {
+	limits, ok := q.options.clientLimits[client]
+	if !ok {
+		limits = q.options.limits
+	}
+	if len(limits) == 0 {
+		return
+	}
+
+	c := q.load(ctx, client, keep)
+	now := time.Now()
+
+	for _, limit := range limits {
+		bucket, reset := bucketOf(limit.Period, now)
+		u := &Usage{
+			Period:      limit.Period,
+			Bucket:      bucket,
+			MaxBytes:    limit.Bytes,
+			MaxConnTime: int64(limit.ConnTime / time.Second),
+		}
+		if !reset.IsZero() {
+			u.Reset = &reset
+		}
+		if cnt := c[limit.Period+":"+bucket]; cnt != nil {
+			u.Bytes = cnt.Bytes
+			u.ConnTime = cnt.ConnTime
+		}
+		if u.MaxBytes > 0 {
+			v := max(u.MaxBytes-u.Bytes, 0)
+			u.RemainingBytes = &v
+			if v == 0 {
+				u.Exceeded = true
+			}
+		}
+		if u.MaxConnTime > 0 {
+			v := max(u.MaxConnTime-u.ConnTime, 0)
+			u.RemainingConnTime = &v
+			if v == 0 {
+				u.Exceeded = true
+			}
+		}
+		usages = append(usages, u)
+	}
+
+	return
+}
+
+// load returns a copy of the usage of the client,
+// the usage of a client unknown to this node is fetched from the store.
+// If keep is false, the fetched usage is not kept,
+// so the lookups of the clients without usage, such as from the API, do not add them to the usage.
+func (q *quota) load(ctx context.Context, client string, keep bool) Counters {
+	cp := make(Counters)
+
+	q.mu.Lock()
+	c, ok := q.usage[client]
+	if ok {
+		cp.add(c)
+	}
+	q.mu.Unlock()
+
+	if ok || q.options.store == nil {
+		return cp
+	}
+
+	v, err := q.options.store.Load(ctx, client, q.buckets(time.Now()))
+	if err != nil {
+		q.options.logger.Warnf("quota: load %s: %v", client, err)
+	}
+	if !keep {
+		cp.add(v)
+		return cp
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok = q.usage[client]; !ok {
+		if v == nil {
+			v = make(Counters)
+		}
+		q.usage[client] = v
+	}
+	cp.add(q.usage[client])
+	return cp
+}
+
+// buckets returns the current buckets of the periods of the limits.
+func (q *quota) buckets(t time.Time) (buckets []string) {
+	for _, period := range q.periods {
+		bucket, _ := bucketOf(period, t)
+		buckets = append(buckets, period+":"+bucket)
+	}
+	return
+}
+
+func (q *quota) periodSync(ctx context.Context) {
+	defer close(q.done)
+
+	ticker := time.NewTicker(q.options.syncPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := q.sync(ctx); err != nil {
+				q.options.logger.Warnf("quota: sync: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sync persists the pending usage, and refreshes the usage of the clients from the store.
+// The usage of the expired periods is dropped.
+func (q *quota) sync(ctx context.Context) error {
+	buckets := q.buckets(time.Now())
+
+	q.mu.Lock()
+	pending := q.pending
+	q.pending = make(map[string]Counters)
+	clients := make([]string, 0, len(q.usage))
+	for client := range q.usage {
+		clients = append(clients, client)
+	}
+	q.mu.Unlock()
+
+	var usage map[string]Counters
+	var err error
+	if q.options.store != nil {
+		usage, err = q.options.store.Sync(ctx, pending, clients, buckets)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err != nil {
+		for client, c := range pending {
+			if q.pending[client] == nil {
+				q.pending[client] = make(Counters)
+			}
+			q.pending[client].add(c)
+		}
+		return err
+	}
+
+	if usage != nil {
+		// the usage added during the sync is not in the store yet.
+		for client, c := range q.pending {
+			if usage[client] == nil {
+				usage[client] = make(Counters)
+			}
+			usage[client].add(c)
+		}
+		q.usage = usage
+	}
+
+	current := make(map[string]bool)
+	for _, bucket := range buckets {
+		current[bucket] = true
+	}
+	for client, c := range q.usage {
+		for bucket := range c {
+			if !current[bucket] {
+				delete(c, bucket)
+			}
+		}
+		if len(c) == 0 && q.pending[client] == nil {
+			delete(q.usage, client)
+			delete(q.fractions, client)
+		}
+	}
+
+	return nil
+}
+
+func (q *quota) Close() error {
+	q.cancel()
+	<-q.done
+
+	// persist the remaining usage.
+	if q.options.store == nil {
+		return nil
+	}
+	if err := q.sync(context.Background()); err != nil {
+		q.options.logger.Warnf("quota: sync: %v", err)
+	}
+	return q.options.store.Close()
+}
+
+// bucketOf returns the bucket of the period at time t, and the end of the bucket.
+func bucketOf(period string, t time.Time) (string, time.Time) {
+	switch period {
+	case PeriodDaily:
+		y, m, d := t.Date()
+		return t.Format("2006-01-02"), time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+	case PeriodMonthly:
+		y, m, _ := t.Date()
+		return t.Format("2006-01"), time.Date(y, m+1, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return "", time.Time{}
+	}
+}
+
+func mapValues[K comparable, V any](m map[K]V) (values []V) {
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return
+}
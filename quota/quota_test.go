@@ -0,0 +1,120 @@
+package quota
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/auth"
+	"github.com/go-gost/core/limiter"
+	"github.com/go-gost/x/auth/lockout"
+	xlogger "github.com/go-gost/x/logger"
+)
+
+type autherFunc func(user, password string) (string, bool)
+
+func (f autherFunc) Authenticate(ctx context.Context, user, password string, opts ...auth.Option) (string, bool) {
+	return f(user, password)
+}
+
+func TestQuotaFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "quota.json")
+
+	newQuota := func() Quota {
+		return NewQuota(
+			LimitsOption(
+				Limit{Period: PeriodDaily, Bytes: 1000},
+				Limit{Period: PeriodTotal, ConnTime: time.Hour},
+			),
+			ClientLimitsOption(map[string][]Limit{
+				"vip": {{Period: PeriodMonthly, Bytes: 1 << 30}},
+			}),
+			RateOption(1024),
+			StoreOption(FileStore(path)),
+			LoggerOption(xlogger.Nop()),
+		)
+	}
+
+	q := newQuota()
+	q.Add(ctx, "u1", 600, 1500*time.Millisecond)
+	q.Add(ctx, "u1", 0, 600*time.Millisecond)
+	q.Add(ctx, "vip", 2000, 0)
+	if q.Exceeded(ctx, "u1") || q.Exceeded(ctx, "vip") {
+		t.Fatal("unexpected exceeded")
+	}
+	q.(io.Closer).Close()
+
+	// the usage survives the restart.
+	q = newQuota()
+	defer q.(io.Closer).Close()
+
+	u := q.Usage(ctx, "u1")
+	if len(u.Usages) != 2 {
+		t.Fatalf("got %d usages, want 2", len(u.Usages))
+	}
+	if v := u.Usages[0]; v.Bytes != 600 || *v.RemainingBytes != 400 || v.Reset == nil {
+		t.Errorf("unexpected daily usage %+v", v)
+	}
+	if v := u.Usages[1]; v.ConnTime != 2 || *v.RemainingConnTime != 3598 || v.RemainingBytes != nil {
+		t.Errorf("unexpected total usage %+v", v)
+	}
+
+	// the lookups do not add the clients to the usage.
+	if u := q.Usage(ctx, "unknown"); len(u.Usages) != 2 || u.Usages[0].Bytes != 0 || u.Exceeded {
+		t.Errorf("unexpected usage of unknown client %+v", u)
+	}
+	if clients := q.Clients(ctx); slices.Contains(clients, "unknown") {
+		t.Errorf("unknown client is added to the usage: %v", clients)
+	}
+
+	q.Add(ctx, "u1", 400, 0)
+	if !q.Exceeded(ctx, "u1") {
+		t.Fatal("u1 should exceed the daily quota")
+	}
+	if q.Exceeded(ctx, "vip") {
+		t.Fatal("vip should not exceed the monthly quota")
+	}
+
+	// the over-quota client is rejected at authentication.
+	a := WrapAuthenticator(autherFunc(func(user, password string) (string, bool) {
+		return user, true
+	}), q)
+	if _, ok := a.Authenticate(ctx, "u1", ""); ok {
+		t.Error("u1 should be rejected")
+	}
+	if _, ok := a.Authenticate(ctx, "vip", ""); !ok {
+		t.Error("vip should be accepted")
+	}
+
+	// the over-quota client is throttled to the floor rate.
+	l := WrapTrafficLimiter(nil, q)
+	opts := []limiter.Option{limiter.ScopeOption(limiter.ScopeClient)}
+	if lim := l.In(ctx, "u1", append(opts, limiter.ClientOption("u1"))...); lim == nil || lim.Limit() != 1024 {
+		t.Errorf("u1 should be throttled, got %v", lim)
+	}
+	if lim := l.Out(ctx, "vip", append(opts, limiter.ClientOption("vip"))...); lim == nil || lim.Limit() != 0 {
+		t.Errorf("vip should not be throttled, got %v", lim)
+	}
+	if lim := l.In(ctx, "u1", limiter.ScopeOption(limiter.ScopeService)); lim != nil {
+		t.Errorf("unexpected service limiter %v", lim)
+	}
+}
+
+func TestAuthenticatorTracker(t *testing.T) {
+	q := NewQuota(LoggerOption(xlogger.Nop()))
+	defer q.(io.Closer).Close()
+
+	tracker := lockout.NewTracker(lockout.LoggerOption(xlogger.Nop()))
+	a := WrapAuthenticator(lockout.WrapAuthenticator(autherFunc(func(user, password string) (string, bool) {
+		return user, true
+	}), tracker), q)
+
+	v, ok := a.(interface{ Tracker() *lockout.Tracker })
+	if !ok || v.Tracker() != tracker {
+		t.Error("the lockout tracker is not forwarded")
+	}
+}
@@ -0,0 +1,318 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	defaultRedisKey = "gost:quota"
+)
+
+// Store persists the usage of the clients.
+type Store interface {
+	// Load returns the usage of the client in the buckets.
+	Load(ctx context.Context, client string, buckets []string) (Counters, error)
+	// Sync adds the pending usage to the store, and returns the usage of the clients in the buckets.
+	// The usage in the other buckets is removed.
+	Sync(ctx context.Context, pending map[string]Counters, clients []string, buckets []string) (map[string]Counters, error)
+	Close() error
+}
+
+type fileStore struct {
+	path   string
+	usage  map[string]Counters
+	loaded bool
+	mu     sync.Mutex
+}
+
+// FileStore persists the usage of all the clients in a JSON file.
+func FileStore(path string) Store {
+	return &fileStore{
+		path: path,
+	}
+}
+
+func (s *fileStore) Load(ctx context.Context, client string, buckets []string) (Counters, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	c := make(Counters)
+	for _, bucket := range buckets {
+		if v := s.usage[client][bucket]; v != nil {
+			c[bucket] = &Counter{Bytes: v.Bytes, ConnTime: v.ConnTime}
+		}
+	}
+	return c, nil
+}
+
+func (s *fileStore) Sync(ctx context.Context, pending map[string]Counters, clients []string, buckets []string) (map[string]Counters, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	for client, c := range pending {
+		if s.usage[client] == nil {
+			s.usage[client] = make(Counters)
+		}
+		s.usage[client].add(c)
+	}
+
+	current := make(map[string]bool)
+	for _, bucket := range buckets {
+		current[bucket] = true
+	}
+	usage := make(map[string]Counters)
+	for client, c := range s.usage {
+		for bucket := range c {
+			if !current[bucket] {
+				delete(c, bucket)
+			}
+		}
+		if len(c) == 0 {
+			delete(s.usage, client)
+			continue
+		}
+
+		usage[client] = make(Counters)
+		usage[client].add(c)
+	}
+
+	return usage, s.save()
+}
+
+func (s *fileStore) load() error {
+	if s.loaded {
+		return nil
+	}
+
+	s.usage = make(map[string]Counters)
+
+	b, err := os.ReadFile(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &s.usage); err != nil {
+			return err
+		}
+	}
+
+	s.loaded = true
+	return nil
+}
+
+func (s *fileStore) save() error {
+	b, err := json.MarshalIndent(s.usage, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	os.MkdirAll(filepath.Dir(s.path), 0755)
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
+
+func (s *fileStore) Close() error {
+	return nil
+}
+
+type redisStoreOptions struct {
+	db       int
+	username string
+	password string
+	key      string
+}
+
+type RedisStoreOption func(opts *redisStoreOptions)
+
+func DBRedisStoreOption(db int) RedisStoreOption {
+	return func(opts *redisStoreOptions) {
+		opts.db = db
+	}
+}
+
+func UsernameRedisStoreOption(username string) RedisStoreOption {
+	return func(opts *redisStoreOptions) {
+		opts.username = username
+	}
+}
+
+func PasswordRedisStoreOption(password string) RedisStoreOption {
+	return func(opts *redisStoreOptions) {
+		opts.password = password
+	}
+}
+
+func KeyRedisStoreOption(key string) RedisStoreOption {
+	return func(opts *redisStoreOptions) {
+		opts.key = key
+	}
+}
+
+// redisStore keeps the usage of each client in the redis hash <key>:<client>,
+// the fields are <bucket>:bytes and <bucket>:connTime, such as daily:2006-01-02:bytes.
+// The usage is increased by HINCRBY, so the store can be shared by multiple nodes.
+type redisStore struct {
+	client *redis.Client
+	key    string
+}
+
+// RedisStore persists the usage of the clients in redis.
+func RedisStore(addr string, opts ...RedisStoreOption) Store {
+	var options redisStoreOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	key := options.key
+	if key == "" {
+		key = defaultRedisKey
+	}
+
+	return &redisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Username: options.username,
+			Password: options.password,
+			DB:       options.db,
+		}),
+		key: key,
+	}
+}
+
+func (s *redisStore) Load(ctx context.Context, client string, buckets []string) (Counters, error) {
+	usage, err := s.get(ctx, []string{client}, buckets)
+	if err != nil {
+		return nil, err
+	}
+	return usage[client], nil
+}
+
+func (s *redisStore) Sync(ctx context.Context, pending map[string]Counters, clients []string, buckets []string) (map[string]Counters, error) {
+	if len(pending) > 0 {
+		if _, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for client, c := range pending {
+				for bucket, v := range c {
+					if v.Bytes != 0 {
+						pipe.HIncrBy(ctx, s.key+":"+client, bucket+":bytes", v.Bytes)
+					}
+					if v.ConnTime != 0 {
+						pipe.HIncrBy(ctx, s.key+":"+client, bucket+":connTime", v.ConnTime)
+					}
+				}
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	m := make(map[string]bool)
+	for _, client := range clients {
+		m[client] = true
+	}
+	for client := range pending {
+		if !m[client] {
+			clients = append(clients, client)
+		}
+	}
+
+	return s.get(ctx, clients, buckets)
+}
+
+// get returns the usage of the clients in the buckets, the fields of the other buckets are removed.
+func (s *redisStore) get(ctx context.Context, clients []string, buckets []string) (map[string]Counters, error) {
+	usage := make(map[string]Counters)
+	if len(clients) == 0 {
+		return usage, nil
+	}
+
+	cmds := make([]*redis.StringStringMapCmd, len(clients))
+	if _, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, client := range clients {
+			cmds[i] = pipe.HGetAll(ctx, s.key+":"+client)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]bool)
+	for _, bucket := range buckets {
+		current[bucket] = true
+	}
+
+	stale := make(map[string][]string)
+	for i, client := range clients {
+		c := make(Counters)
+		for field, value := range cmds[i].Val() {
+			n := strings.LastIndexByte(field, ':')
+			if n < 0 {
+				continue
+			}
+			bucket := field[:n]
+			if !current[bucket] {
+				stale[client] = append(stale[client], field)
+				continue
+			}
+
+			v, _ := strconv.ParseInt(value, 10, 64)
+			cnt := c[bucket]
+			if cnt == nil {
+				cnt = &Counter{}
+				c[bucket] = cnt
+			}
+			switch field[n+1:] {
+			case "bytes":
+				cnt.Bytes = v
+			case "connTime":
+				cnt.ConnTime = v
+			}
+		}
+		usage[client] = c
+	}
+
+	if len(stale) > 0 {
+		s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for client, fields := range stale {
+				pipe.HDel(ctx, s.key+":"+client, fields...)
+			}
+			return nil
+		})
+	}
+
+	return usage, nil
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,237 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/metrics"
+	"github.com/go-gost/core/recorder"
+	xmetrics "github.com/go-gost/x/metrics"
+)
+
+const (
+	PolicyDrop  = "drop"
+	PolicyBlock = "block"
+)
+
+const (
+	defaultQueueSize     = 1024
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+)
+
+var (
+	ErrRecorderClosed = errors.New("recorder closed")
+)
+
+// BatchRecorder is a recorder which can write multiple records at once.
+type BatchRecorder interface {
+	RecordBatch(ctx context.Context, records [][]byte) error
+}
+
+// recordBatch writes the records to r in batch if possible, or one by one.
+func recordBatch(ctx context.Context, r recorder.Recorder, records [][]byte) error {
+	if br, ok := r.(BatchRecorder); ok {
+		return br.RecordBatch(ctx, records)
+	}
+
+	var errs []error
+	for _, b := range records {
+		if err := r.Record(ctx, b); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type bufferRecorderOptions struct {
+	recorder      string
+	queueSize     int
+	batchSize     int
+	flushInterval time.Duration
+	policy        string
+	log           logger.Logger
+}
+
+type BufferRecorderOption func(opts *bufferRecorderOptions)
+
+func RecorderBufferRecorderOption(recorder string) BufferRecorderOption {
+	return func(opts *bufferRecorderOptions) {
+		opts.recorder = recorder
+	}
+}
+
+// QueueSizeBufferRecorderOption sets the maximum number of the pending records.
+func QueueSizeBufferRecorderOption(size int) BufferRecorderOption {
+	return func(opts *bufferRecorderOptions) {
+		opts.queueSize = size
+	}
+}
+
+// BatchSizeBufferRecorderOption sets the maximum number of records written at once.
+func BatchSizeBufferRecorderOption(size int) BufferRecorderOption {
+	return func(opts *bufferRecorderOptions) {
+		opts.batchSize = size
+	}
+}
+
+// FlushIntervalBufferRecorderOption sets the maximum time a record is kept in the buffer.
+func FlushIntervalBufferRecorderOption(interval time.Duration) BufferRecorderOption {
+	return func(opts *bufferRecorderOptions) {
+		opts.flushInterval = interval
+	}
+}
+
+// PolicyBufferRecorderOption sets the behavior when the queue is full,
+// drop (default) discards the new record, block waits for the free space.
+func PolicyBufferRecorderOption(policy string) BufferRecorderOption {
+	return func(opts *bufferRecorderOptions) {
+		opts.policy = policy
+	}
+}
+
+func LogBufferRecorderOption(log logger.Logger) BufferRecorderOption {
+	return func(opts *bufferRecorderOptions) {
+		opts.log = log
+	}
+}
+
+type bufferRecorder struct {
+	recorder recorder.Recorder
+	queue    chan []byte
+	options  bufferRecorderOptions
+	mu       sync.RWMutex
+	closed   chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// BufferRecorder writes the records to r asynchronously in batches,
+// so that a slow recorder does not block the caller.
+func BufferRecorder(r recorder.Recorder, opts ...BufferRecorderOption) recorder.Recorder {
+	if r == nil {
+		return nil
+	}
+
+	var options bufferRecorderOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.queueSize <= 0 {
+		options.queueSize = defaultQueueSize
+	}
+	if options.batchSize <= 0 {
+		options.batchSize = defaultBatchSize
+	}
+	if options.flushInterval <= 0 {
+		options.flushInterval = defaultFlushInterval
+	}
+	if options.log == nil {
+		options.log = logger.Default()
+	}
+
+	p := &bufferRecorder{
+		recorder: r,
+		queue:    make(chan []byte, options.queueSize),
+		options:  options,
+		closed:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+
+	return p
+}
+
+func (r *bufferRecorder) Record(ctx context.Context, b []byte, opts ...recorder.RecordOption) error {
+	// the caller may reuse the buffer after return.
+	b = append([]byte(nil), b...)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	select {
+	case <-r.closed:
+		return ErrRecorderClosed
+	default:
+	}
+
+	if strings.EqualFold(r.options.policy, PolicyBlock) {
+		select {
+		case r.queue <- b:
+			return nil
+		case <-ctx.Done():
+			r.drop()
+			return ctx.Err()
+		case <-r.closed:
+			return ErrRecorderClosed
+		}
+	}
+
+	select {
+	case r.queue <- b:
+	default:
+		r.drop()
+	}
+	return nil
+}
+
+func (r *bufferRecorder) drop() {
+	xmetrics.GetCounter(xmetrics.MetricRecorderDropsCounter, metrics.Labels{"recorder": r.options.recorder}).Inc()
+}
+
+func (r *bufferRecorder) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.options.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, r.options.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := recordBatch(context.Background(), r.recorder, batch); err != nil {
+			r.options.log.Errorf("recorder %s: %v", r.options.recorder, err)
+		}
+		batch = make([][]byte, 0, r.options.batchSize)
+	}
+
+	for {
+		select {
+		case b, ok := <-r.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, b)
+			if len(batch) >= r.options.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Close flushes the pending records and closes the underlying recorder.
+func (r *bufferRecorder) Close() (err error) {
+	r.once.Do(func() {
+		close(r.closed)
+		// wait for the pending writers.
+		r.mu.Lock()
+		close(r.queue)
+		r.mu.Unlock()
+
+		<-r.done
+
+		if closer, ok := r.recorder.(io.Closer); ok {
+			err = closer.Close()
+		}
+	})
+	return
+}
@@ -0,0 +1,105 @@
+package recorder
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/recorder"
+)
+
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][][]byte
+	closed  bool
+	block   chan struct{}
+}
+
+func (r *batchRecorder) Record(ctx context.Context, b []byte, opts ...recorder.RecordOption) error {
+	return r.RecordBatch(ctx, [][]byte{b})
+}
+
+func (r *batchRecorder) RecordBatch(ctx context.Context, records [][]byte) error {
+	if r.block != nil {
+		<-r.block
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, records)
+	return nil
+}
+
+func (r *batchRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *batchRecorder) count() (n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, b := range r.batches {
+		n += len(b)
+	}
+	return
+}
+
+func TestBufferRecorder(t *testing.T) {
+	br := &batchRecorder{}
+	r := BufferRecorder(br,
+		BatchSizeBufferRecorderOption(2),
+		FlushIntervalBufferRecorderOption(time.Hour),
+	)
+	for _, s := range []string{"a", "b", "c"} {
+		r.Record(context.Background(), []byte(s))
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := br.count(); n != 2 {
+		t.Errorf("%d records written before flush, want 2", n)
+	}
+
+	// the pending record is flushed on close.
+	r.(*bufferRecorder).Close()
+	if len(br.batches) != 2 || string(br.batches[1][0]) != "c" || !br.closed {
+		t.Errorf("unexpected batches %q", br.batches)
+	}
+	if err := r.Record(context.Background(), []byte("d")); err != ErrRecorderClosed {
+		t.Errorf("got error %v, want %v", err, ErrRecorderClosed)
+	}
+}
+
+func TestBufferRecorderPolicy(t *testing.T) {
+	br := &batchRecorder{block: make(chan struct{})}
+	r := BufferRecorder(br,
+		QueueSizeBufferRecorderOption(1),
+		BatchSizeBufferRecorderOption(1),
+	)
+	// the first record is taken by the writer which is blocked, the second one is queued.
+	for _, s := range []string{"a", "b", "c"} {
+		if err := r.Record(context.Background(), []byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	rb := BufferRecorder(&batchRecorder{block: make(chan struct{})},
+		QueueSizeBufferRecorderOption(1),
+		BatchSizeBufferRecorderOption(1),
+		PolicyBufferRecorderOption(PolicyBlock),
+	)
+	for _, s := range []string{"a", "b"} {
+		rb.Record(context.Background(), []byte(s))
+		time.Sleep(10 * time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := rb.Record(ctx, []byte("c")); err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(br.block)
+	r.(*bufferRecorder).Close()
+	if n := br.count(); n != 2 {
+		t.Errorf("%d records written, want 2", n)
+	}
+}
@@ -0,0 +1,430 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/recorder"
+	"github.com/gobwas/glob"
+)
+
+const (
+	redactedValue = "[REDACTED]"
+)
+
+var (
+	DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+)
+
+// HTTPCaptureRule selects the HTTP messages by host, path and content type.
+// The patterns are globs, and the empty pattern matches any value.
+type HTTPCaptureRule struct {
+	Host        string
+	Path        string
+	ContentType string
+	// Skip disables the body capture of the matched messages.
+	Skip bool
+	// MaxBodySize overrides the maximum body size of the policy.
+	MaxBodySize int
+}
+
+type httpCaptureRule struct {
+	host        glob.Glob
+	path        glob.Glob
+	contentType glob.Glob
+	rule        HTTPCaptureRule
+}
+
+func (r *httpCaptureRule) match(host, path, contentType string) bool {
+	return (r.host == nil || r.host.Match(host)) &&
+		(r.path == nil || r.path.Match(path)) &&
+		(r.contentType == nil || r.contentType.Match(contentType))
+}
+
+type httpCapturePolicyOptions struct {
+	rules           []HTTPCaptureRule
+	maxBodySize     int
+	sampleRate      float64
+	redactHeaders   []string
+	redactJSONPaths []string
+	logger          logger.Logger
+}
+
+type HTTPCapturePolicyOption func(opts *httpCapturePolicyOptions)
+
+// RulesHTTPCapturePolicyOption sets the rules, the first matched rule is applied to the message.
+// The bodies of the messages not matched by any rule are captured.
+func RulesHTTPCapturePolicyOption(rules ...HTTPCaptureRule) HTTPCapturePolicyOption {
+	return func(opts *httpCapturePolicyOptions) {
+		opts.rules = rules
+	}
+}
+
+func MaxBodySizeHTTPCapturePolicyOption(size int) HTTPCapturePolicyOption {
+	return func(opts *httpCapturePolicyOptions) {
+		opts.maxBodySize = size
+	}
+}
+
+// SampleRateHTTPCapturePolicyOption sets the fraction of the requests whose bodies are captured,
+// 0 or 1 captures all the requests.
+func SampleRateHTTPCapturePolicyOption(rate float64) HTTPCapturePolicyOption {
+	return func(opts *httpCapturePolicyOptions) {
+		opts.sampleRate = rate
+	}
+}
+
+// RedactHeadersHTTPCapturePolicyOption sets the headers whose values are redacted, DefaultRedactHeaders is used by default.
+func RedactHeadersHTTPCapturePolicyOption(headers ...string) HTTPCapturePolicyOption {
+	return func(opts *httpCapturePolicyOptions) {
+		opts.redactHeaders = headers
+	}
+}
+
+// RedactJSONPathsHTTPCapturePolicyOption sets the JSON fields of the bodies to be redacted.
+// The supported JSONPath syntax is $.a.b, $['a'], $.a[0], $.a[*], $.a.* and $..a.
+func RedactJSONPathsHTTPCapturePolicyOption(paths ...string) HTTPCapturePolicyOption {
+	return func(opts *httpCapturePolicyOptions) {
+		opts.redactJSONPaths = paths
+	}
+}
+
+func LoggerHTTPCapturePolicyOption(logger logger.Logger) HTTPCapturePolicyOption {
+	return func(opts *httpCapturePolicyOptions) {
+		opts.logger = logger
+	}
+}
+
+// HTTPCapturePolicy controls the capture of the HTTP request and response bodies,
+// and the redaction of the recorded headers and JSON bodies.
+type HTTPCapturePolicy struct {
+	rules         []*httpCaptureRule
+	maxBodySize   int
+	sampleRate    float64
+	redactHeaders []string
+	jsonPaths     [][]jsonPathStep
+}
+
+func NewHTTPCapturePolicy(opts ...HTTPCapturePolicyOption) *HTTPCapturePolicy {
+	options := httpCapturePolicyOptions{
+		redactHeaders: DefaultRedactHeaders,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.logger == nil {
+		options.logger = logger.Default()
+	}
+
+	p := &HTTPCapturePolicy{
+		maxBodySize: options.maxBodySize,
+		sampleRate:  options.sampleRate,
+	}
+
+	for _, rule := range options.rules {
+		r := &httpCaptureRule{rule: rule}
+		var err error
+		if r.host, err = compileGlob(rule.Host); err == nil {
+			if r.path, err = compileGlob(rule.Path); err == nil {
+				r.contentType, err = compileGlob(strings.ToLower(rule.ContentType))
+			}
+		}
+		if err != nil {
+			options.logger.Warnf("capture rule %+v: %v", rule, err)
+			continue
+		}
+		p.rules = append(p.rules, r)
+	}
+
+	for _, h := range options.redactHeaders {
+		p.redactHeaders = append(p.redactHeaders, http.CanonicalHeaderKey(h))
+	}
+
+	for _, s := range options.redactJSONPaths {
+		steps, err := parseJSONPath(s)
+		if err != nil {
+			options.logger.Warnf("capture redact %s: %v", s, err)
+			continue
+		}
+		p.jsonPaths = append(p.jsonPaths, steps)
+	}
+
+	return p
+}
+
+func compileGlob(pattern string) (glob.Glob, error) {
+	if pattern == "" || pattern == "*" {
+		return nil, nil
+	}
+	return glob.Compile(pattern)
+}
+
+// Sample reports whether the bodies of a request should be captured according to the sample rate.
+func (p *HTTPCapturePolicy) Sample() bool {
+	if p == nil || p.sampleRate <= 0 || p.sampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < p.sampleRate
+}
+
+// BodySize returns the maximum size of the body to capture for the message,
+// ok is false if the body should not be captured, and size is 0 if the size is not limited by the policy.
+func (p *HTTPCapturePolicy) BodySize(host, path string, header http.Header) (size int, ok bool) {
+	if p == nil {
+		return 0, true
+	}
+
+	contentType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	for _, r := range p.rules {
+		if !r.match(host, path, contentType) {
+			continue
+		}
+		if r.rule.Skip {
+			return 0, false
+		}
+		if r.rule.MaxBodySize > 0 {
+			return r.rule.MaxBodySize, true
+		}
+		break
+	}
+	return p.maxBodySize, true
+}
+
+// Redact returns a copy of o with the sensitive headers and JSON fields redacted,
+// and the truncated bodies marked.
+//
+// The JSON body which can not be parsed, e.g. truncated or compressed, is dropped if the JSON fields are to be redacted.
+func (p *HTTPCapturePolicy) Redact(o *HTTPRecorderObject) *HTTPRecorderObject {
+	if o == nil {
+		return nil
+	}
+
+	v := *o
+	v.Request.Header = p.redactHeader(o.Request.Header)
+	v.Response.Header = p.redactHeader(o.Response.Header)
+	v.Request.Body, v.Request.BodyTruncated = p.redactBody(o.Request.Header, o.Request.Body, o.Request.ContentLength)
+	v.Response.Body, v.Response.BodyTruncated = p.redactBody(o.Response.Header, o.Response.Body, o.Response.ContentLength)
+	return &v
+}
+
+func (p *HTTPCapturePolicy) redactHeader(header http.Header) http.Header {
+	if p == nil || header == nil {
+		return header
+	}
+
+	var h http.Header
+	for _, k := range p.redactHeaders {
+		if len(header[k]) == 0 {
+			continue
+		}
+		if h == nil {
+			h = header.Clone()
+		}
+		values := make([]string, len(header[k]))
+		for i := range values {
+			values[i] = redactedValue
+		}
+		h[k] = values
+	}
+	if h == nil {
+		return header
+	}
+	return h
+}
+
+func (p *HTTPCapturePolicy) redactBody(header http.Header, body []byte, length int64) ([]byte, bool) {
+	truncated := len(body) > 0 && int64(len(body)) < length
+	if p == nil || len(p.jsonPaths) == 0 || len(body) == 0 {
+		return body, truncated
+	}
+
+	contentType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if !strings.Contains(contentType, "json") {
+		return body, truncated
+	}
+
+	var v any
+	if truncated || json.Unmarshal(body, &v) != nil {
+		return nil, truncated
+	}
+	for _, steps := range p.jsonPaths {
+		redactJSON(v, steps)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, truncated
+	}
+	return b, truncated
+}
+
+type jsonPathStep struct {
+	name      string
+	index     int
+	wildcard  bool
+	recursive bool
+}
+
+func parseJSONPath(s string) (steps []jsonPathStep, err error) {
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %s", s)
+	}
+
+	for i := 1; i < len(s); {
+		step := jsonPathStep{index: -1}
+		if strings.HasPrefix(s[i:], "..") {
+			step.recursive = true
+			i++
+		}
+
+		switch s[i] {
+		case '.':
+			i++
+			j := i
+			for j < len(s) && s[j] != '.' && s[j] != '[' {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("invalid JSONPath %s", s)
+			}
+			if name := s[i:j]; name == "*" {
+				step.wildcard = true
+			} else {
+				step.name = name
+			}
+			i = j
+		case '[':
+			j := strings.IndexByte(s[i:], ']')
+			if j < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %s", s)
+			}
+			v := s[i+1 : i+j]
+			switch {
+			case v == "*":
+				step.wildcard = true
+			case len(v) >= 2 && (v[0] == '\'' || v[0] == '"') && v[len(v)-1] == v[0]:
+				step.name = v[1 : len(v)-1]
+			default:
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid JSONPath %s", s)
+				}
+				step.index = n
+			}
+			i += j + 1
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %s", s)
+		}
+		steps = append(steps, step)
+	}
+
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("invalid JSONPath %s", s)
+	}
+	return
+}
+
+// redactJSON replaces the values at the path with the redacted value.
+func redactJSON(v any, steps []jsonPathStep) {
+	if len(steps) == 0 {
+		return
+	}
+
+	step := steps[0]
+	if step.recursive {
+		// match the step at this level and all the descendants.
+		step.recursive = false
+		redactJSON(v, append([]jsonPathStep{step}, steps[1:]...))
+
+		switch t := v.(type) {
+		case map[string]any:
+			for _, c := range t {
+				redactJSON(c, steps)
+			}
+		case []any:
+			for _, c := range t {
+				redactJSON(c, steps)
+			}
+		}
+		return
+	}
+
+	last := len(steps) == 1
+	switch t := v.(type) {
+	case map[string]any:
+		for k, c := range t {
+			if !step.wildcard && (step.name == "" || k != step.name) {
+				continue
+			}
+			if last {
+				t[k] = redactedValue
+			} else {
+				redactJSON(c, steps[1:])
+			}
+		}
+	case []any:
+		for i, c := range t {
+			if !step.wildcard && i != step.index {
+				continue
+			}
+			if last {
+				t[i] = redactedValue
+			} else {
+				redactJSON(c, steps[1:])
+			}
+		}
+	}
+}
+
+type captureRecorder struct {
+	recorder.Recorder
+	policy *HTTPCapturePolicy
+}
+
+// CaptureRecorder attaches the HTTP capture policy to the recorder.
+func CaptureRecorder(r recorder.Recorder, policy *HTTPCapturePolicy) recorder.Recorder {
+	if r == nil || policy == nil {
+		return r
+	}
+	return &captureRecorder{
+		Recorder: r,
+		policy:   policy,
+	}
+}
+
+func (r *captureRecorder) HTTPCapturePolicy() *HTTPCapturePolicy {
+	return r.policy
+}
+
+func (r *captureRecorder) Close() error {
+	if closer, ok := r.Recorder.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// HTTPCapturePolicyOf returns the HTTP capture policy of the recorder, or nil if no policy is attached.
+func HTTPCapturePolicyOf(r recorder.Recorder) *HTTPCapturePolicy {
+	for r != nil {
+		switch t := r.(type) {
+		case interface{ HTTPCapturePolicy() *HTTPCapturePolicy }:
+			return t.HTTPCapturePolicy()
+		case interface{ Unwrap() recorder.Recorder }:
+			r = t.Unwrap()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPCapturePolicyBodySize(t *testing.T) {
+	p := NewHTTPCapturePolicy(
+		RulesHTTPCapturePolicyOption(
+			HTTPCaptureRule{Host: "*.example.com", Path: "/upload/*", Skip: true},
+			HTTPCaptureRule{ContentType: "application/json", MaxBodySize: 4096},
+			HTTPCaptureRule{ContentType: "image/*", Skip: true},
+		),
+		MaxBodySizeHTTPCapturePolicyOption(1024),
+	)
+
+	cases := []struct {
+		host        string
+		path        string
+		contentType string
+		size        int
+		ok          bool
+	}{
+		{"api.example.com:443", "/upload/a", "application/json", 0, false},
+		{"api.example.com", "/v1", "application/json; charset=utf-8", 4096, true},
+		{"example.org", "/logo.png", "image/png", 0, false},
+		{"example.org", "/", "text/html", 1024, true},
+	}
+	for _, c := range cases {
+		size, ok := p.BodySize(c.host, c.path, http.Header{"Content-Type": []string{c.contentType}})
+		if size != c.size || ok != c.ok {
+			t.Errorf("%s%s %s: got (%d, %v), want (%d, %v)", c.host, c.path, c.contentType, size, ok, c.size, c.ok)
+		}
+	}
+}
+
+func TestHTTPCapturePolicyRedact(t *testing.T) {
+	p := NewHTTPCapturePolicy(
+		RedactJSONPathsHTTPCapturePolicyOption("$.password", "$..token", "$.items[*].secret"),
+	)
+
+	o := &HTTPRecorderObject{}
+	o.Request.Header = http.Header{
+		"Authorization": []string{"Basic dXNlcjpwYXNz"},
+		"Content-Type":  []string{"application/json"},
+	}
+	o.Request.Body = []byte(`{"user":"u","password":"p","auth":{"token":"t"},"items":[{"secret":"s","id":1}]}`)
+	o.Request.ContentLength = int64(len(o.Request.Body))
+	o.Response.Header = http.Header{"Content-Type": []string{"application/json"}}
+	o.Response.Body = []byte(`{"token":`)
+	o.Response.ContentLength = 100
+
+	v := p.Redact(o)
+
+	if got := v.Request.Header.Get("Authorization"); got != redactedValue {
+		t.Errorf("authorization header: got %s", got)
+	}
+	if got := o.Request.Header.Get("Authorization"); got == redactedValue {
+		t.Error("original header is modified")
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(v.Request.Body, &body); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(body)
+	want := `{"auth":{"token":"[REDACTED]"},"items":[{"id":1,"secret":"[REDACTED]"}],"password":"[REDACTED]","user":"u"}`
+	if string(b) != want {
+		t.Errorf("request body: got %s, want %s", b, want)
+	}
+	if v.Request.BodyTruncated {
+		t.Error("request body is marked as truncated")
+	}
+
+	if v.Response.Body != nil || !v.Response.BodyTruncated {
+		t.Errorf("truncated response body: got %q, truncated %v", v.Response.Body, v.Response.BodyTruncated)
+	}
+}
+
+func TestHARRecord(t *testing.T) {
+	ro := &HandlerRecorderObject{
+		Service: "svc-0",
+		DstAddr: "192.168.1.1:80",
+		HTTP: &HTTPRecorderObject{
+			Host:       "example.com",
+			Method:     http.MethodPost,
+			Proto:      "HTTP/1.1",
+			URI:        "/login?next=%2F",
+			StatusCode: http.StatusOK,
+		},
+		Duration: 20 * time.Millisecond,
+		Time:     time.Now(),
+	}
+	ro.HTTP.Request.Header = http.Header{"Cookie": []string{"sid=abc"}, "Content-Type": []string{"text/plain"}}
+	ro.HTTP.Request.Body = []byte("hello")
+	ro.HTTP.Request.ContentLength = 10
+	ro.HTTP.Response.Header = http.Header{"Content-Type": []string{"application/octet-stream"}}
+	ro.HTTP.Response.Body = []byte{0xff, 0x00}
+	ro.HTTP.Response.ContentLength = 2
+
+	br := &batchRecorder{}
+	r := CaptureRecorder(EncoderRecorder(br, NewEncoder(FormatHAR)), NewHTTPCapturePolicy())
+	if err := ro.Record(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if br.count() != 1 {
+		t.Fatalf("%d records, want 1", br.count())
+	}
+	data := br.batches[0][0]
+
+	var har harLog
+	if err := json.Unmarshal(data, &har); err != nil {
+		t.Fatal(err)
+	}
+	if har.Log.Version != harVersion || len(har.Log.Entries) != 1 {
+		t.Fatalf("invalid HAR log %s", data)
+	}
+	entry := har.Log.Entries[0]
+	if entry.Request.URL != "http://example.com/login?next=%2F" {
+		t.Errorf("url: got %s", entry.Request.URL)
+	}
+	if len(entry.Request.QueryString) != 1 || entry.Request.QueryString[0].Value != "/" {
+		t.Errorf("query string: got %+v", entry.Request.QueryString)
+	}
+	// the redacted cookies are not reported.
+	if len(entry.Request.Cookies) != 0 {
+		t.Errorf("cookies: got %+v", entry.Request.Cookies)
+	}
+	if pd := entry.Request.PostData; pd == nil || pd.Text != "hello" || pd.Comment != "truncated" {
+		t.Errorf("post data: got %+v", pd)
+	}
+	if c := entry.Response.Content; c.Text != "/wA=" || c.Encoding != "base64" {
+		t.Errorf("content: got %+v", c)
+	}
+	if entry.ServerIPAddress != "192.168.1.1" || entry.Time != 20 {
+		t.Errorf("entry: got %+v", entry)
+	}
+
+	if err := (&HandlerRecorderObject{Service: "svc-0", Time: time.Now()}).Record(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if br.count() != 1 {
+		t.Error("non-HTTP record is not skipped")
+	}
+}
@@ -0,0 +1,339 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-gost/core/recorder"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatLogfmt = "logfmt"
+	FormatCEF    = "cef"
+	FormatLEEF   = "leef"
+	FormatHAR    = "har"
+)
+
+const (
+	defaultVendor  = "GOST"
+	defaultProduct = "gost"
+	defaultVersion = "3"
+)
+
+// Encoder converts a record to the output format.
+// The record is skipped if the encoded data is nil.
+type Encoder interface {
+	Encode(b []byte) ([]byte, error)
+}
+
+type encoderOptions struct {
+	name    string
+	vendor  string
+	product string
+	version string
+}
+
+type EncoderOption func(opts *encoderOptions)
+
+// NameEncoderOption sets the event name of the CEF and LEEF formats, the recorder name is generally used.
+func NameEncoderOption(name string) EncoderOption {
+	return func(opts *encoderOptions) {
+		opts.name = name
+	}
+}
+
+func VendorEncoderOption(vendor string) EncoderOption {
+	return func(opts *encoderOptions) {
+		opts.vendor = vendor
+	}
+}
+
+func ProductEncoderOption(product string) EncoderOption {
+	return func(opts *encoderOptions) {
+		opts.product = product
+	}
+}
+
+func VersionEncoderOption(version string) EncoderOption {
+	return func(opts *encoderOptions) {
+		opts.version = version
+	}
+}
+
+// NewEncoder creates an encoder for the format, nil is returned for the unknown format
+// and the json format which outputs the records as is.
+//
+// The records are expected to be JSON objects, other records are encoded as the msg field.
+// Nested objects are flattened with dotted keys for the logfmt, CEF and LEEF formats.
+func NewEncoder(format string, opts ...EncoderOption) Encoder {
+	options := encoderOptions{
+		name:    "record",
+		vendor:  defaultVendor,
+		product: defaultProduct,
+		version: defaultVersion,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	switch strings.ToLower(format) {
+	case FormatNDJSON:
+		return ndjsonEncoder{}
+	case FormatLogfmt:
+		return logfmtEncoder{}
+	case FormatCEF:
+		return &cefEncoder{options: options}
+	case FormatLEEF:
+		return &leefEncoder{options: options}
+	case FormatHAR:
+		return &harEncoder{options: options}
+	default:
+		return nil
+	}
+}
+
+const (
+	contentTypeJSON   = "application/json"
+	contentTypeNDJSON = "application/x-ndjson"
+	contentTypeText   = "text/plain; charset=utf-8"
+)
+
+// ContentType returns the media type of a record encoded by the encoder,
+// the records are JSON objects if encoder is nil.
+func ContentType(encoder Encoder) string {
+	switch encoder.(type) {
+	case logfmtEncoder, *cefEncoder, *leefEncoder:
+		return contentTypeText
+	default:
+		return contentTypeJSON
+	}
+}
+
+type ndjsonEncoder struct{}
+
+func (ndjsonEncoder) Encode(b []byte) ([]byte, error) {
+	b = bytes.TrimSpace(b)
+	if !json.Valid(b) {
+		return json.Marshal(map[string]string{"msg": string(b)})
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type logfmtEncoder struct{}
+
+func (logfmtEncoder) Encode(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, f := range flatten(b) {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(f.key)
+		buf.WriteByte('=')
+		if f.value == "" || strings.ContainsAny(f.value, " =\"\\") || !strconv.CanBackquote(f.value) {
+			buf.WriteString(strconv.Quote(f.value))
+		} else {
+			buf.WriteString(f.value)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+	leefEscaper         = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+)
+
+type cefEncoder struct {
+	options encoderOptions
+}
+
+// Encode encodes the record in ArcSight Common Event Format:
+// CEF:Version|Device Vendor|Device Product|Device Version|Signature ID|Name|Severity|Extension
+func (e *cefEncoder) Encode(b []byte) ([]byte, error) {
+	fields := flatten(b)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefHeaderEscaper.Replace(e.options.vendor),
+		cefHeaderEscaper.Replace(e.options.product),
+		cefHeaderEscaper.Replace(e.options.version),
+		cefHeaderEscaper.Replace(e.options.name),
+		cefHeaderEscaper.Replace(e.options.name),
+		severity(fields),
+	)
+
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		key := f.key
+		value := f.value
+		if key == "time" {
+			// receipt time in milliseconds since epoch.
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				key = "rt"
+				value = strconv.FormatInt(t.UnixMilli(), 10)
+			}
+		}
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(cefExtensionEscaper.Replace(value))
+	}
+	return buf.Bytes(), nil
+}
+
+type leefEncoder struct {
+	options encoderOptions
+}
+
+// Encode encodes the record in IBM QRadar Log Event Extended Format 1.0:
+// LEEF:Version|Vendor|Product|Version|EventID|Extension
+// The attributes of extension are separated by tab.
+func (e *leefEncoder) Encode(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "LEEF:1.0|%s|%s|%s|%s|",
+		cefHeaderEscaper.Replace(e.options.vendor),
+		cefHeaderEscaper.Replace(e.options.product),
+		cefHeaderEscaper.Replace(e.options.version),
+		cefHeaderEscaper.Replace(e.options.name),
+	)
+
+	fields := flatten(b)
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte('\t')
+		}
+		key := f.key
+		if key == "time" {
+			key = "devTime"
+		}
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(leefEscaper.Replace(f.value))
+	}
+	if n := severity(fields); n > 1 {
+		fmt.Fprintf(&buf, "\tsev=%d", n)
+	}
+	return buf.Bytes(), nil
+}
+
+// severity reports a higher severity for the failed connections.
+func severity(fields []field) int {
+	for _, f := range fields {
+		if f.key == "err" && f.value != "" {
+			return 5
+		}
+	}
+	return 1
+}
+
+type field struct {
+	key   string
+	value string
+}
+
+// flatten converts the JSON object to the sorted key-value list,
+// the keys of the nested objects are joined with dot, arrays are kept in JSON.
+func flatten(b []byte) []field {
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return []field{{key: "msg", value: string(bytes.TrimSpace(b))}}
+	}
+
+	var fields []field
+	flattenObject(&fields, "", m)
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].key < fields[j].key
+	})
+	return fields
+}
+
+func flattenObject(fields *[]field, prefix string, m map[string]any) {
+	for k, v := range m {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+
+		var value string
+		switch t := v.(type) {
+		case nil:
+			continue
+		case map[string]any:
+			flattenObject(fields, k, t)
+			continue
+		case string:
+			value = t
+		case float64:
+			value = strconv.FormatFloat(t, 'f', -1, 64)
+		case bool:
+			value = strconv.FormatBool(t)
+		default:
+			b, _ := json.Marshal(t)
+			value = string(b)
+		}
+		*fields = append(*fields, field{key: k, value: value})
+	}
+}
+
+type encoderRecorder struct {
+	recorder recorder.Recorder
+	encoder  Encoder
+}
+
+// EncoderRecorder encodes the records with encoder before writing them to the recorder.
+func EncoderRecorder(r recorder.Recorder, encoder Encoder) recorder.Recorder {
+	if r == nil || encoder == nil {
+		return r
+	}
+	return &encoderRecorder{
+		recorder: r,
+		encoder:  encoder,
+	}
+}
+
+func (r *encoderRecorder) Record(ctx context.Context, b []byte, opts ...recorder.RecordOption) error {
+	b, err := r.encoder.Encode(b)
+	if err != nil || b == nil {
+		return err
+	}
+	return r.recorder.Record(ctx, b, opts...)
+}
+
+func (r *encoderRecorder) RecordBatch(ctx context.Context, records [][]byte) error {
+	batch := make([][]byte, 0, len(records))
+	for _, b := range records {
+		v, err := r.encoder.Encode(b)
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			batch = append(batch, v)
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return recordBatch(ctx, r.recorder, batch)
+}
+
+func (r *encoderRecorder) Close() error {
+	if closer, ok := r.recorder.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package recorder
+
+import (
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	record := []byte(`{"service":"svc-0","network":"tcp","dst":"example.com:443","http":{"method":"GET","statusCode":200},"err":"a=b|c","duration":1500}`)
+
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: FormatNDJSON,
+			want:   `{"service":"svc-0","network":"tcp","dst":"example.com:443","http":{"method":"GET","statusCode":200},"err":"a=b|c","duration":1500}`,
+		},
+		{
+			format: FormatLogfmt,
+			want:   `dst=example.com:443 duration=1500 err="a=b|c" http.method=GET http.statusCode=200 network=tcp service=svc-0`,
+		},
+		{
+			format: FormatCEF,
+			want:   `CEF:0|GOST|gost|3|rec-0|rec-0|5|dst=example.com:443 duration=1500 err=a\=b|c http.method=GET http.statusCode=200 network=tcp service=svc-0`,
+		},
+		{
+			format: FormatLEEF,
+			want:   "LEEF:1.0|GOST|gost|3|rec-0|dst=example.com:443\tduration=1500\terr=a=b|c\thttp.method=GET\thttp.statusCode=200\tnetwork=tcp\tservice=svc-0\tsev=5",
+		},
+	}
+
+	for _, c := range cases {
+		b, err := NewEncoder(c.format, NameEncoderOption("rec-0")).Encode(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != c.want {
+			t.Errorf("%s: got %s, want %s", c.format, b, c.want)
+		}
+	}
+
+	if b, _ := NewEncoder(FormatLogfmt).Encode([]byte("1.2.3.4 ok")); string(b) != `msg="1.2.3.4 ok"` {
+		t.Errorf("got %s", b)
+	}
+	if NewEncoder(FormatJSON) != nil {
+		t.Error("encoder for json format")
+	}
+}
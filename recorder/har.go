@@ -0,0 +1,241 @@
+package recorder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	harVersion = "1.2"
+)
+
+type harLog struct {
+	Log harLogContent `json:"log"`
+}
+
+type harLogContent struct {
+	Version string      `json:"version"`
+	Creator harCreator  `json:"creator"`
+	Entries []*harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Connection      string      `json:"connection,omitempty"`
+	// custom fields.
+	Service  string `json:"_service,omitempty"`
+	ClientIP string `json:"_clientIP,omitempty"`
+	ClientID string `json:"_clientID,omitempty"`
+	Error    string `json:"_error,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"_encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+type harEncoder struct {
+	options encoderOptions
+}
+
+// Encode encodes the HTTP record as a HAR 1.2 log with one entry, the records of other protocols are skipped.
+// The time of the exchange is reported as the wait timing.
+func (e *harEncoder) Encode(b []byte) ([]byte, error) {
+	var ro HandlerRecorderObject
+	if err := json.Unmarshal(b, &ro); err != nil || ro.HTTP == nil {
+		return nil, nil
+	}
+	o := ro.HTTP
+
+	ms := float64(ro.Duration) / float64(time.Millisecond)
+	entry := &harEntry{
+		StartedDateTime: ro.Time.Format(time.RFC3339Nano),
+		Time:            ms,
+		Request: harRequest{
+			Method:      o.Method,
+			URL:         harURL(o),
+			HTTPVersion: o.Proto,
+			Cookies:     harCookies((&http.Request{Header: o.Request.Header}).Cookies()),
+			Headers:     harHeaders(o.Request.Header),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    o.Request.ContentLength,
+		},
+		Response: harResponse{
+			Status:      o.StatusCode,
+			StatusText:  http.StatusText(o.StatusCode),
+			HTTPVersion: o.Proto,
+			Cookies:     harCookies((&http.Response{Header: o.Response.Header}).Cookies()),
+			Headers:     harHeaders(o.Response.Header),
+			Content: harContent{
+				Size:     o.Response.ContentLength,
+				MimeType: o.Response.Header.Get("Content-Type"),
+			},
+			RedirectURL: o.Response.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    o.Response.ContentLength,
+		},
+		Timings: harTimings{
+			Wait: ms,
+		},
+		Connection: ro.SID,
+		Service:    ro.Service,
+		ClientIP:   ro.ClientIP,
+		ClientID:   ro.ClientID,
+		Error:      ro.Err,
+	}
+	if host, _, _ := net.SplitHostPort(ro.DstAddr); net.ParseIP(host) != nil {
+		entry.ServerIPAddress = host
+	}
+
+	if u, err := url.Parse(entry.Request.URL); err == nil {
+		for k, vs := range u.Query() {
+			for _, v := range vs {
+				entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: k, Value: v})
+			}
+		}
+		sortNameValues(entry.Request.QueryString)
+	}
+
+	if len(o.Request.Body) > 0 {
+		text, encoding := harText(o.Request.Header, o.Request.Body)
+		entry.Request.PostData = &harPostData{
+			MimeType: o.Request.Header.Get("Content-Type"),
+			Text:     text,
+			Encoding: encoding,
+		}
+		if o.Request.BodyTruncated {
+			entry.Request.PostData.Comment = "truncated"
+		}
+	}
+
+	if len(o.Response.Body) > 0 {
+		entry.Response.Content.Text, entry.Response.Content.Encoding = harText(o.Response.Header, o.Response.Body)
+		if o.Response.BodyTruncated {
+			entry.Response.Content.Comment = "truncated"
+		}
+	}
+
+	return json.Marshal(&harLog{
+		Log: harLogContent{
+			Version: harVersion,
+			Creator: harCreator{
+				Name:    e.options.product,
+				Version: e.options.version,
+			},
+			Entries: []*harEntry{entry},
+		},
+	})
+}
+
+func harURL(o *HTTPRecorderObject) string {
+	if strings.HasPrefix(o.URI, "http://") || strings.HasPrefix(o.URI, "https://") {
+		return o.URI
+	}
+	scheme := o.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	return scheme + "://" + o.Host + o.URI
+}
+
+func harHeaders(h http.Header) []harNameValue {
+	list := []harNameValue{}
+	for k, vs := range h {
+		for _, v := range vs {
+			list = append(list, harNameValue{Name: k, Value: v})
+		}
+	}
+	sortNameValues(list)
+	return list
+}
+
+func harCookies(cookies []*http.Cookie) []harNameValue {
+	list := []harNameValue{}
+	for _, c := range cookies {
+		list = append(list, harNameValue{Name: c.Name, Value: c.Value})
+	}
+	return list
+}
+
+// harText returns the body as text, or in base64 encoding for the binary content.
+func harText(h http.Header, body []byte) (string, string) {
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	textual := mediaType == "" || strings.HasPrefix(mediaType, "text/") ||
+		strings.Contains(mediaType, "json") || strings.Contains(mediaType, "xml") ||
+		strings.Contains(mediaType, "javascript") || mediaType == "application/x-www-form-urlencoded"
+	if textual && h.Get("Content-Encoding") == "" && utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func sortNameValues(list []harNameValue) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+}
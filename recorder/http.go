@@ -2,6 +2,7 @@ package recorder
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"net/http"
@@ -14,9 +15,11 @@ import (
 )
 
 type httpRecorderOptions struct {
-	recorder string
-	timeout  time.Duration
-	header   http.Header
+	recorder    string
+	timeout     time.Duration
+	header      http.Header
+	gzip        bool
+	contentType string
 }
 
 type HTTPRecorderOption func(opts *httpRecorderOptions)
@@ -39,11 +42,28 @@ func HeaderHTTPRecorderOption(header http.Header) HTTPRecorderOption {
 	}
 }
 
+// GzipHTTPRecorderOption enables the gzip compression of the request body.
+func GzipHTTPRecorderOption(gzip bool) HTTPRecorderOption {
+	return func(opts *httpRecorderOptions) {
+		opts.gzip = gzip
+	}
+}
+
+// ContentTypeHTTPRecorderOption sets the media type of the records, see ContentType.
+// The default is application/json.
+func ContentTypeHTTPRecorderOption(contentType string) HTTPRecorderOption {
+	return func(opts *httpRecorderOptions) {
+		opts.contentType = contentType
+	}
+}
+
 type httpRecorder struct {
-	recorder   string
-	url        string
-	httpClient *http.Client
-	header     http.Header
+	recorder    string
+	url         string
+	httpClient  *http.Client
+	header      http.Header
+	gzip        bool
+	contentType string
 }
 
 // HTTPRecorder records data to HTTP service.
@@ -59,19 +79,58 @@ func HTTPRecorder(url string, opts ...HTTPRecorderOption) recorder.Recorder {
 	if !strings.HasPrefix(url, "http") {
 		url = "http://" + url
 	}
+	if options.contentType == "" {
+		options.contentType = contentTypeJSON
+	}
 
 	return &httpRecorder{
-		url: url,
+		recorder: options.recorder,
+		url:      url,
 		httpClient: &http.Client{
 			Timeout: options.timeout,
 		},
-		header: options.header,
+		header:      options.header,
+		gzip:        options.gzip,
+		contentType: options.contentType,
 	}
 }
 
 func (r *httpRecorder) Record(ctx context.Context, b []byte, opts ...recorder.RecordOption) error {
 	xmetrics.GetCounter(xmetrics.MetricRecorderRecordsCounter, metrics.Labels{"recorder": r.recorder}).Inc()
 
+	return r.post(ctx, b, r.contentType)
+}
+
+// RecordBatch sends the records in one request, one record per line,
+// the lines of JSON records are sent as NDJSON.
+func (r *httpRecorder) RecordBatch(ctx context.Context, records [][]byte) error {
+	xmetrics.GetCounter(xmetrics.MetricRecorderRecordsCounter, metrics.Labels{"recorder": r.recorder}).Add(float64(len(records)))
+
+	var buf bytes.Buffer
+	for _, b := range records {
+		buf.Write(bytes.TrimRight(b, "\n"))
+		buf.WriteByte('\n')
+	}
+	contentType := r.contentType
+	if contentType == contentTypeJSON {
+		contentType = contentTypeNDJSON
+	}
+	return r.post(ctx, buf.Bytes(), contentType)
+}
+
+func (r *httpRecorder) post(ctx context.Context, b []byte, contentType string) error {
+	if r.gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(b); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		b = buf.Bytes()
+	}
+
 	req, err := http.NewRequest(http.MethodPost, r.url, bytes.NewReader(b))
 	if err != nil {
 		return err
@@ -82,7 +141,10 @@ func (r *httpRecorder) Record(ctx context.Context, b []byte, opts ...recorder.Re
 	}
 
 	if req.Header.Get("Content-Type") == "" {
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Type", contentType)
+	}
+	if r.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
 	}
 
 	resp, err := r.httpClient.Do(req)
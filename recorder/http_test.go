@@ -0,0 +1,46 @@
+package recorder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPRecorderContentType(t *testing.T) {
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		format string
+		record string
+		batch  string
+	}{
+		{"", "application/json", "application/x-ndjson"},
+		{FormatNDJSON, "application/json", "application/x-ndjson"},
+		{FormatHAR, "application/json", "application/x-ndjson"},
+		{FormatLogfmt, "text/plain; charset=utf-8", "text/plain; charset=utf-8"},
+		{FormatCEF, "text/plain; charset=utf-8", "text/plain; charset=utf-8"},
+		{FormatLEEF, "text/plain; charset=utf-8", "text/plain; charset=utf-8"},
+	}
+	for _, c := range cases {
+		r := HTTPRecorder(srv.URL, ContentTypeHTTPRecorderOption(ContentType(NewEncoder(c.format))))
+
+		if err := r.Record(context.Background(), []byte(`{"service":"svc-0"}`)); err != nil {
+			t.Fatal(err)
+		}
+		if contentType != c.record {
+			t.Errorf("%s: got content type %q, want %q", c.format, contentType, c.record)
+		}
+
+		if err := r.(*httpRecorder).RecordBatch(context.Background(), [][]byte{[]byte(`{}`), []byte(`{}`)}); err != nil {
+			t.Fatal(err)
+		}
+		if contentType != c.batch {
+			t.Errorf("%s: got batch content type %q, want %q", c.format, contentType, c.batch)
+		}
+	}
+}
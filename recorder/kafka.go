@@ -0,0 +1,716 @@
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/metrics"
+	"github.com/go-gost/core/recorder"
+	xmetrics "github.com/go-gost/x/metrics"
+)
+
+const (
+	kafkaAPIProduce  = 0
+	kafkaAPIMetadata = 3
+
+	kafkaProduceVersion  = 3
+	kafkaMetadataVersion = 4
+
+	defaultKafkaClientID = "gost"
+	defaultKafkaTimeout  = 10 * time.Second
+)
+
+var (
+	ErrKafkaNoBroker = errors.New("kafka: no available broker")
+
+	castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+type kafkaRecorderOptions struct {
+	recorder  string
+	topic     string
+	key       string
+	clientID  string
+	acks      int
+	timeout   time.Duration
+	tlsConfig *tls.Config
+	encoder   Encoder
+	log       logger.Logger
+}
+
+type KafkaRecorderOption func(opts *kafkaRecorderOptions)
+
+func RecorderKafkaRecorderOption(recorder string) KafkaRecorderOption {
+	return func(opts *kafkaRecorderOptions) {
+		opts.recorder = recorder
+	}
+}
+
+// TopicKafkaRecorderOption sets the topic template, such as gost-{service}.
+func TopicKafkaRecorderOption(topic string) KafkaRecorderOption {
+	return func(opts *kafkaRecorderOptions) {
+		opts.topic = topic
+	}
+}
+
+// KeyKafkaRecorderOption sets the message key template, such as {clientID}.
+// The messages with the same key are sent to the same partition,
+// the messages without key are distributed to the partitions in turn.
+func KeyKafkaRecorderOption(key string) KafkaRecorderOption {
+	return func(opts *kafkaRecorderOptions) {
+		opts.key = key
+	}
+}
+
+func ClientIDKafkaRecorderOption(clientID string) KafkaRecorderOption {
+	return func(opts *kafkaRecorderOptions) {
+		opts.clientID = clientID
+	}
+}
+
+// AcksKafkaRecorderOption sets the required acks, 1 (default) for the leader only, -1 for all the in-sync replicas,
+// 0 for no acknowledgement, the broker does not respond and the records may be lost silently.
+func AcksKafkaRecorderOption(acks int) KafkaRecorderOption {
+	return func(opts *kafkaRecorderOptions) {
+		opts.acks = acks
+	}
+}
+
+func TimeoutKafkaRecorderOption(timeout time.Duration) KafkaRecorderOption {
+	return func(opts *kafkaRecorderOptions) {
+		opts.timeout = timeout
+	}
+}
+
+func TLSConfigKafkaRecorderOption(tlsConfig *tls.Config) KafkaRecorderOption {
+	return func(opts *kafkaRecorderOptions) {
+		opts.tlsConfig = tlsConfig
+	}
+}
+
+// EncoderKafkaRecorderOption sets the encoder of the message value,
+// the templates are always executed with the original record.
+func EncoderKafkaRecorderOption(encoder Encoder) KafkaRecorderOption {
+	return func(opts *kafkaRecorderOptions) {
+		opts.encoder = encoder
+	}
+}
+
+func LogKafkaRecorderOption(log logger.Logger) KafkaRecorderOption {
+	return func(opts *kafkaRecorderOptions) {
+		opts.log = log
+	}
+}
+
+type kafkaMessage struct {
+	topic     string
+	partition int32
+	key       []byte
+	value     []byte
+	time      time.Time
+}
+
+type kafkaRecorder struct {
+	brokers []string
+	options kafkaRecorderOptions
+	topic   recordTemplate
+	key     recordTemplate
+
+	// broker node ID to address.
+	nodes map[int32]string
+	// topic to the leaders of the partitions.
+	leaders map[string][]int32
+	conns   map[string]*kafkaConn
+	next    uint32
+	mu      sync.Mutex
+}
+
+// KafkaRecorder records data to Kafka topic.
+// It speaks the Kafka wire protocol directly (Metadata v4 and Produce v3 with record batch v2),
+// the messages are sent to the partition leaders without compression.
+// The delivery is at-least-once, see RecordBatch.
+func KafkaRecorder(brokers []string, opts ...KafkaRecorderOption) recorder.Recorder {
+	options := kafkaRecorderOptions{
+		acks: 1,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if len(brokers) == 0 || options.topic == "" {
+		return nil
+	}
+	if options.clientID == "" {
+		options.clientID = defaultKafkaClientID
+	}
+	if options.timeout <= 0 {
+		options.timeout = defaultKafkaTimeout
+	}
+	if options.log == nil {
+		options.log = logger.Default()
+	}
+
+	return &kafkaRecorder{
+		brokers: brokers,
+		options: options,
+		topic:   recordTemplate(options.topic),
+		key:     recordTemplate(options.key),
+		nodes:   make(map[int32]string),
+		leaders: make(map[string][]int32),
+		conns:   make(map[string]*kafkaConn),
+	}
+}
+
+func (r *kafkaRecorder) Record(ctx context.Context, b []byte, opts ...recorder.RecordOption) error {
+	return r.RecordBatch(ctx, [][]byte{b})
+}
+
+// RecordBatch produces the records to the partition leaders.
+// On failure the whole batch is sent again once with the refreshed metadata,
+// the records accepted by some of the leaders before the failure are duplicated,
+// so the delivery is at-least-once. With acks 0 the records lost by the brokers are not detected.
+func (r *kafkaRecorder) RecordBatch(ctx context.Context, records [][]byte) error {
+	xmetrics.GetCounter(xmetrics.MetricRecorderRecordsCounter, metrics.Labels{"recorder": r.options.recorder}).Add(float64(len(records)))
+
+	now := time.Now()
+	var msgs []*kafkaMessage
+	for _, b := range records {
+		msg := &kafkaMessage{
+			topic: r.topic.Execute(b),
+			value: b,
+			time:  now,
+		}
+		if key := r.key.Execute(b); key != "" {
+			msg.key = []byte(key)
+		}
+		if r.options.encoder != nil {
+			v, err := r.options.encoder.Encode(b)
+			if err != nil {
+				return err
+			}
+			if v == nil {
+				continue
+			}
+			msg.value = v
+		}
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.produce(msgs)
+	if err != nil {
+		// the cluster may be changed, retry once with the refreshed metadata.
+		r.options.log.Debugf("kafka: %v, retrying", err)
+		r.reset()
+		err = r.produce(msgs)
+	}
+	return err
+}
+
+func (r *kafkaRecorder) produce(msgs []*kafkaMessage) error {
+	// leader address -> topic -> partition -> messages
+	batches := make(map[string]map[string]map[int32][]*kafkaMessage)
+	for _, msg := range msgs {
+		leaders, err := r.partitions(msg.topic)
+		if err != nil {
+			return err
+		}
+
+		if msg.key != nil {
+			msg.partition = int32((uint32(murmur2(msg.key)) & 0x7fffffff) % uint32(len(leaders)))
+		} else {
+			msg.partition = int32(r.next % uint32(len(leaders)))
+			r.next++
+		}
+
+		addr, ok := r.nodes[leaders[msg.partition]]
+		if !ok {
+			return fmt.Errorf("kafka: no leader for %s[%d]", msg.topic, msg.partition)
+		}
+		if batches[addr] == nil {
+			batches[addr] = make(map[string]map[int32][]*kafkaMessage)
+		}
+		if batches[addr][msg.topic] == nil {
+			batches[addr][msg.topic] = make(map[int32][]*kafkaMessage)
+		}
+		batches[addr][msg.topic][msg.partition] = append(batches[addr][msg.topic][msg.partition], msg)
+	}
+
+	for addr, topics := range batches {
+		conn, err := r.conn(addr)
+		if err != nil {
+			return err
+		}
+
+		var w kafkaWriter
+		w.int16(-1) // transactional_id
+		w.int16(int16(r.options.acks))
+		w.int32(int32(r.options.timeout.Milliseconds()))
+		w.int32(int32(len(topics)))
+		for topic, partitions := range topics {
+			w.string(topic)
+			w.int32(int32(len(partitions)))
+			for partition, msgs := range partitions {
+				w.int32(partition)
+				w.bytes(encodeRecordBatch(msgs))
+			}
+		}
+
+		if r.options.acks == 0 {
+			// no response is sent by the broker.
+			if err := conn.send(kafkaAPIProduce, kafkaProduceVersion, w.buf.Bytes()); err != nil {
+				r.closeConn(addr)
+				return err
+			}
+			continue
+		}
+
+		resp, err := conn.roundTrip(kafkaAPIProduce, kafkaProduceVersion, w.buf.Bytes())
+		if err != nil {
+			r.closeConn(addr)
+			return err
+		}
+		if err := parseProduceResponse(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partitions returns the leaders of the topic partitions.
+func (r *kafkaRecorder) partitions(topic string) ([]int32, error) {
+	if leaders := r.leaders[topic]; len(leaders) > 0 {
+		return leaders, nil
+	}
+
+	var err error
+	for _, addr := range r.brokers {
+		var conn *kafkaConn
+		if conn, err = r.conn(addr); err != nil {
+			continue
+		}
+
+		var w kafkaWriter
+		w.int32(1)
+		w.string(topic)
+		w.int8(1) // allow_auto_topic_creation
+
+		var resp []byte
+		if resp, err = conn.roundTrip(kafkaAPIMetadata, kafkaMetadataVersion, w.buf.Bytes()); err != nil {
+			r.closeConn(addr)
+			continue
+		}
+
+		var leaders []int32
+		if leaders, err = r.parseMetadataResponse(resp, topic); err != nil {
+			return nil, err
+		}
+		r.leaders[topic] = leaders
+		return leaders, nil
+	}
+
+	if err == nil {
+		err = ErrKafkaNoBroker
+	}
+	return nil, err
+}
+
+func (r *kafkaRecorder) parseMetadataResponse(b []byte, topic string) (leaders []int32, err error) {
+	rd := &kafkaReader{b: b}
+	rd.int32() // throttle_time_ms
+	for i, n := 0, rd.int32(); i < int(n) && rd.err == nil; i++ {
+		id := rd.int32()
+		host := rd.string()
+		port := rd.int32()
+		rd.string() // rack
+		r.nodes[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	rd.string() // cluster_id
+	rd.int32()  // controller_id
+
+	for i, n := 0, rd.int32(); i < int(n) && rd.err == nil; i++ {
+		code := rd.int16()
+		name := rd.string()
+		rd.int8() // is_internal
+		var list []int32
+		for j, m := 0, rd.int32(); j < int(m) && rd.err == nil; j++ {
+			rd.int16() // error_code
+			partition := rd.int32()
+			leader := rd.int32()
+			rd.int32Array() // replica_nodes
+			rd.int32Array() // isr_nodes
+			if int(partition) >= len(list) {
+				list = append(list, make([]int32, int(partition)+1-len(list))...)
+			}
+			list[partition] = leader
+		}
+		if name != topic {
+			continue
+		}
+		if code != 0 {
+			return nil, kafkaError(code)
+		}
+		leaders = list
+	}
+	if rd.err != nil {
+		return nil, rd.err
+	}
+	if len(leaders) == 0 {
+		return nil, fmt.Errorf("kafka: no partition for topic %s", topic)
+	}
+	return
+}
+
+func parseProduceResponse(b []byte) error {
+	rd := &kafkaReader{b: b}
+	for i, n := 0, rd.int32(); i < int(n) && rd.err == nil; i++ {
+		topic := rd.string()
+		for j, m := 0, rd.int32(); j < int(m) && rd.err == nil; j++ {
+			partition := rd.int32()
+			code := rd.int16()
+			rd.int64() // base_offset
+			rd.int64() // log_append_time_ms
+			if code != 0 && rd.err == nil {
+				return fmt.Errorf("%w: %s[%d]", kafkaError(code), topic, partition)
+			}
+		}
+	}
+	return rd.err
+}
+
+func (r *kafkaRecorder) conn(addr string) (*kafkaConn, error) {
+	if conn := r.conns[addr]; conn != nil {
+		return conn, nil
+	}
+
+	dialer := &net.Dialer{Timeout: r.options.timeout}
+	var conn net.Conn
+	var err error
+	if r.options.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, r.options.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &kafkaConn{
+		conn:     conn,
+		br:       bufio.NewReader(conn),
+		clientID: r.options.clientID,
+		timeout:  r.options.timeout,
+	}
+	r.conns[addr] = c
+	return c, nil
+}
+
+func (r *kafkaRecorder) closeConn(addr string) {
+	if conn := r.conns[addr]; conn != nil {
+		conn.conn.Close()
+		delete(r.conns, addr)
+	}
+}
+
+func (r *kafkaRecorder) reset() {
+	for addr := range r.conns {
+		r.closeConn(addr)
+	}
+	r.leaders = make(map[string][]int32)
+}
+
+func (r *kafkaRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reset()
+	return nil
+}
+
+type kafkaConn struct {
+	conn          net.Conn
+	br            *bufio.Reader
+	clientID      string
+	timeout       time.Duration
+	correlationID int32
+}
+
+// send sends the request without waiting for the response.
+func (c *kafkaConn) send(apiKey, version int16, body []byte) error {
+	c.correlationID++
+
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	defer c.conn.SetWriteDeadline(time.Time{})
+
+	_, err := c.conn.Write(c.request(apiKey, version, body))
+	return err
+}
+
+// roundTrip sends the request and returns the response body after the response header.
+func (c *kafkaConn) roundTrip(apiKey, version int16, body []byte) ([]byte, error) {
+	c.correlationID++
+
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if _, err := c.conn.Write(c.request(apiKey, version, body)); err != nil {
+		return nil, err
+	}
+
+	var size int32
+	if err := binary.Read(c.br, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if size < 4 {
+		return nil, fmt.Errorf("kafka: invalid response size %d", size)
+	}
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(c.br, resp); err != nil {
+		return nil, err
+	}
+	if id := int32(binary.BigEndian.Uint32(resp)); id != c.correlationID {
+		return nil, fmt.Errorf("kafka: correlation ID mismatch, got %d, want %d", id, c.correlationID)
+	}
+	return resp[4:], nil
+}
+
+// request returns the request message with the header.
+func (c *kafkaConn) request(apiKey, version int16, body []byte) []byte {
+	var w kafkaWriter
+	w.int32(0) // size
+	w.int16(apiKey)
+	w.int16(version)
+	w.int32(c.correlationID)
+	w.string(c.clientID)
+	w.buf.Write(body)
+	b := w.buf.Bytes()
+	binary.BigEndian.PutUint32(b, uint32(len(b)-4))
+	return b
+}
+
+// encodeRecordBatch encodes the messages in record batch format v2 (magic 2).
+func encodeRecordBatch(msgs []*kafkaMessage) []byte {
+	base := msgs[0].time.UnixMilli()
+	maxTimestamp := base
+
+	var records kafkaWriter
+	for i, msg := range msgs {
+		ts := msg.time.UnixMilli()
+		if ts > maxTimestamp {
+			maxTimestamp = ts
+		}
+
+		var rec kafkaWriter
+		rec.int8(0) // attributes
+		rec.varint(ts - base)
+		rec.varint(int64(i))
+		if msg.key == nil {
+			rec.varint(-1)
+		} else {
+			rec.varint(int64(len(msg.key)))
+			rec.buf.Write(msg.key)
+		}
+		rec.varint(int64(len(msg.value)))
+		rec.buf.Write(msg.value)
+		rec.varint(0) // headers
+
+		records.varint(int64(rec.buf.Len()))
+		records.buf.Write(rec.buf.Bytes())
+	}
+
+	// the fields covered by CRC.
+	var body kafkaWriter
+	body.int16(0) // attributes: no compression, create time.
+	body.int32(int32(len(msgs) - 1))
+	body.int64(base)
+	body.int64(maxTimestamp)
+	body.int64(-1) // producer_id
+	body.int16(-1) // producer_epoch
+	body.int32(-1) // base_sequence
+	body.int32(int32(len(msgs)))
+	body.buf.Write(records.buf.Bytes())
+
+	var w kafkaWriter
+	w.int64(0) // base_offset
+	w.int32(int32(4 + 1 + 4 + body.buf.Len()))
+	w.int32(-1) // partition_leader_epoch
+	w.int8(2)   // magic
+	w.int32(int32(crc32.Checksum(body.buf.Bytes(), castagnoliTable)))
+	w.buf.Write(body.buf.Bytes())
+	return w.buf.Bytes()
+}
+
+// murmur2 is the hash function used by the default partitioner of the Java client.
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
+
+type kafkaError int16
+
+func (e kafkaError) Error() string {
+	switch e {
+	case 3:
+		return "kafka: unknown topic or partition"
+	case 5:
+		return "kafka: leader not available"
+	case 6:
+		return "kafka: not leader for partition"
+	case 7:
+		return "kafka: request timed out"
+	case 10:
+		return "kafka: message too large"
+	case 19:
+		return "kafka: not enough replicas"
+	case 29:
+		return "kafka: topic authorization failed"
+	default:
+		return fmt.Sprintf("kafka: error code %d", int16(e))
+	}
+}
+
+type kafkaWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *kafkaWriter) int8(v int8) {
+	w.buf.WriteByte(byte(v))
+}
+
+func (w *kafkaWriter) int16(v int16) {
+	w.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+}
+
+func (w *kafkaWriter) int32(v int32) {
+	w.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+}
+
+func (w *kafkaWriter) int64(v int64) {
+	w.buf.Write(binary.BigEndian.AppendUint64(nil, uint64(v)))
+}
+
+// varint writes v in zigzag encoding.
+func (w *kafkaWriter) varint(v int64) {
+	w.buf.Write(binary.AppendVarint(nil, v))
+}
+
+func (w *kafkaWriter) string(s string) {
+	w.int16(int16(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *kafkaWriter) bytes(b []byte) {
+	w.int32(int32(len(b)))
+	w.buf.Write(b)
+}
+
+type kafkaReader struct {
+	b   []byte
+	err error
+}
+
+func (r *kafkaReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.b) < n {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *kafkaReader) int8() int8 {
+	if b := r.next(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (r *kafkaReader) int16() int16 {
+	if b := r.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) int32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) int64() int64 {
+	if b := r.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+// string reads a nullable string, null is returned as empty string.
+func (r *kafkaReader) string() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
+
+func (r *kafkaReader) int32Array() {
+	n := r.int32()
+	if n > 0 {
+		r.next(int(n) * 4)
+	}
+}
@@ -0,0 +1,281 @@
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMurmur2(t *testing.T) {
+	// test vectors of the Java client.
+	cases := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+	for s, want := range cases {
+		if got := murmur2([]byte(s)); got != want {
+			t.Errorf("murmur2(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+type kafkaRecord struct {
+	topic     string
+	partition int32
+	key       string
+	value     string
+}
+
+// kafkaBroker is a stand-in single node cluster, the topics have two partitions.
+type kafkaBroker struct {
+	ln      net.Listener
+	mu      sync.Mutex
+	records []kafkaRecord
+	err     error
+}
+
+func newKafkaBroker(t *testing.T) *kafkaBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &kafkaBroker{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *kafkaBroker) serve(conn net.Conn) {
+	defer conn.Close()
+
+	br := bufio.NewReader(conn)
+	for {
+		var size int32
+		if err := binary.Read(br, binary.BigEndian, &size); err != nil {
+			return
+		}
+		req := make([]byte, size)
+		if _, err := io.ReadFull(br, req); err != nil {
+			return
+		}
+
+		rd := &kafkaReader{b: req}
+		apiKey := rd.int16()
+		rd.int16() // api_version
+		correlationID := rd.int32()
+		rd.string() // client_id
+
+		var w kafkaWriter
+		w.int32(0)
+		w.int32(correlationID)
+		acks := int16(1)
+		switch apiKey {
+		case kafkaAPIMetadata:
+			host, port, _ := net.SplitHostPort(b.ln.Addr().String())
+			nport, _ := strconv.Atoi(port)
+			w.int32(0) // throttle_time_ms
+			w.int32(1)
+			w.int32(0)
+			w.string(host)
+			w.int32(int32(nport))
+			w.int16(-1)
+			w.int16(-1) // cluster_id
+			w.int32(0)  // controller_id
+
+			n := rd.int32()
+			w.int32(n)
+			for i := 0; i < int(n); i++ {
+				w.int16(0)
+				w.string(rd.string())
+				w.int8(0)
+				w.int32(2)
+				for p := 0; p < 2; p++ {
+					w.int16(0)
+					w.int32(int32(p))
+					w.int32(0)
+					w.int32(0)
+					w.int32(0)
+				}
+			}
+		case kafkaAPIProduce:
+			rd.int16() // transactional_id
+			acks = rd.int16()
+			rd.int32() // timeout_ms
+			var resp kafkaWriter
+			n := rd.int32()
+			resp.int32(n)
+			for i := 0; i < int(n); i++ {
+				topic := rd.string()
+				resp.string(topic)
+				m := rd.int32()
+				resp.int32(m)
+				for j := 0; j < int(m); j++ {
+					partition := rd.int32()
+					batch := rd.next(int(rd.int32()))
+					b.decodeRecordBatch(topic, partition, batch)
+					resp.int32(partition)
+					resp.int16(0)
+					resp.int64(0)
+					resp.int64(-1)
+				}
+			}
+			w.buf.Write(resp.buf.Bytes())
+			w.int32(0) // throttle_time_ms
+		}
+
+		if acks == 0 {
+			// no response for the produce request with acks 0.
+			continue
+		}
+		buf := w.buf.Bytes()
+		binary.BigEndian.PutUint32(buf, uint32(len(buf)-4))
+		conn.Write(buf)
+	}
+}
+
+func (b *kafkaBroker) decodeRecordBatch(topic string, partition int32, batch []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rd := &kafkaReader{b: batch}
+	rd.int64() // base_offset
+	if n := rd.int32(); int(n) != len(rd.b) {
+		b.err = io.ErrUnexpectedEOF
+		return
+	}
+	rd.int32() // partition_leader_epoch
+	if magic := rd.int8(); magic != 2 {
+		b.err = io.ErrUnexpectedEOF
+		return
+	}
+	if crc := uint32(rd.int32()); crc != crc32.Checksum(rd.b, crc32.MakeTable(crc32.Castagnoli)) {
+		b.err = io.ErrUnexpectedEOF
+		return
+	}
+	rd.next(2 + 4 + 8 + 8 + 8 + 2 + 4)
+	n := rd.int32()
+
+	r := bytes.NewReader(rd.b)
+	for i := 0; i < int(n); i++ {
+		binary.ReadVarint(r) // length
+		r.ReadByte()         // attributes
+		binary.ReadVarint(r) // timestamp_delta
+		binary.ReadVarint(r) // offset_delta
+		rec := kafkaRecord{topic: topic, partition: partition}
+		if kn, _ := binary.ReadVarint(r); kn >= 0 {
+			key := make([]byte, kn)
+			io.ReadFull(r, key)
+			rec.key = string(key)
+		}
+		vn, _ := binary.ReadVarint(r)
+		value := make([]byte, vn)
+		io.ReadFull(r, value)
+		rec.value = string(value)
+		binary.ReadVarint(r) // headers
+		b.records = append(b.records, rec)
+	}
+}
+
+func TestKafkaRecorder(t *testing.T) {
+	broker := newKafkaBroker(t)
+	defer broker.ln.Close()
+
+	r := KafkaRecorder([]string{broker.ln.Addr().String()},
+		TopicKafkaRecorderOption("gost-{service}"),
+		KeyKafkaRecorderOption("{clientID}"),
+	)
+	defer r.(*kafkaRecorder).Close()
+
+	records := [][]byte{
+		[]byte(`{"service":"svc-0","clientID":"user-0","host":"example.com"}`),
+		[]byte(`{"service":"svc-1","clientID":"user-1","host":"example.com"}`),
+		[]byte(`{"service":"svc-0","host":"example.org"}`),
+	}
+	if err := r.(*kafkaRecorder).RecordBatch(context.Background(), records); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Record(context.Background(), records[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	if broker.err != nil {
+		t.Fatal(broker.err)
+	}
+	if len(broker.records) != 4 {
+		t.Fatalf("%d records received, want 4", len(broker.records))
+	}
+
+	count := make(map[kafkaRecord]int)
+	for _, rec := range broker.records {
+		count[rec]++
+	}
+	want := map[kafkaRecord]int{
+		{topic: "gost-svc-0", partition: (murmur2([]byte("user-0")) & 0x7fffffff) % 2, key: "user-0", value: string(records[0])}: 2,
+		{topic: "gost-svc-1", partition: (murmur2([]byte("user-1")) & 0x7fffffff) % 2, key: "user-1", value: string(records[1])}: 1,
+	}
+	for rec, n := range want {
+		if count[rec] != n {
+			t.Errorf("record %+v received %d times, want %d", rec, count[rec], n)
+		}
+	}
+	for rec := range count {
+		if rec.key == "" && rec.topic != "gost-svc-0" {
+			t.Errorf("unexpected record %+v", rec)
+		}
+	}
+}
+
+func TestKafkaRecorderNoAcks(t *testing.T) {
+	broker := newKafkaBroker(t)
+	defer broker.ln.Close()
+
+	r := KafkaRecorder([]string{broker.ln.Addr().String()},
+		TopicKafkaRecorderOption("gost"),
+		AcksKafkaRecorderOption(0),
+		TimeoutKafkaRecorderOption(time.Second),
+	)
+	defer r.(*kafkaRecorder).Close()
+
+	for i := 0; i < 2; i++ {
+		if err := r.Record(context.Background(), []byte(`{"service":"svc-0"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		broker.mu.Lock()
+		n, err := len(broker.records), broker.err
+		broker.mu.Unlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d records received, want 2", n)
+		}
+	}
+}
@@ -19,12 +19,16 @@ type HTTPRequestRecorderObject struct {
 	ContentLength int64       `json:"contentLength"`
 	Header        http.Header `json:"header"`
 	Body          []byte      `json:"body"`
+	// BodyTruncated marks the body exceeding the maximum capture size.
+	BodyTruncated bool `json:"bodyTruncated,omitempty"`
 }
 
 type HTTPResponseRecorderObject struct {
 	ContentLength int64       `json:"contentLength"`
 	Header        http.Header `json:"header"`
 	Body          []byte      `json:"body"`
+	// BodyTruncated marks the body exceeding the maximum capture size.
+	BodyTruncated bool `json:"bodyTruncated,omitempty"`
 }
 
 type HTTPRecorderObject struct {
@@ -69,6 +73,9 @@ type DNSRecorderObject struct {
 	Question string `json:"question"`
 	Answer   string `json:"answer"`
 	Cached   bool   `json:"cached"`
+	Blocked  bool   `json:"blocked"`
+	// Reason describes why the query is answered locally, e.g. hit by the block list.
+	Reason string `json:"reason,omitempty"`
 }
 
 type HandlerRecorderObject struct {
@@ -103,6 +110,13 @@ func (p *HandlerRecorderObject) Record(ctx context.Context, r recorder.Recorder)
 		return nil
 	}
 
+	if p.HTTP != nil {
+		// redact a copy, the object may be recorded by other recorders.
+		o := *p
+		o.HTTP = HTTPCapturePolicyOf(r).Redact(p.HTTP)
+		p = &o
+	}
+
 	data, err := json.Marshal(p)
 	if err != nil {
 		return err
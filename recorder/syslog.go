@@ -0,0 +1,341 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/metrics"
+	"github.com/go-gost/core/recorder"
+	xmetrics "github.com/go-gost/x/metrics"
+)
+
+const (
+	defaultSyslogFacility = 16 // local0
+	defaultSyslogAppName  = "gost"
+
+	syslogSeverityWarning = 4
+	syslogSeverityInfo    = 6
+)
+
+var syslogFacilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// SyslogFacility returns the facility code of name, the default facility local0 is returned for the unknown name.
+func SyslogFacility(name string) int {
+	if n, ok := syslogFacilities[strings.ToLower(name)]; ok {
+		return n
+	}
+	return defaultSyslogFacility
+}
+
+type syslogRecorderOptions struct {
+	recorder  string
+	network   string
+	facility  int
+	hostname  string
+	appName   string
+	msgID     string
+	timeout   time.Duration
+	tlsConfig *tls.Config
+	encoder   Encoder
+	log       logger.Logger
+}
+
+type SyslogRecorderOption func(opts *syslogRecorderOptions)
+
+func RecorderSyslogRecorderOption(recorder string) SyslogRecorderOption {
+	return func(opts *syslogRecorderOptions) {
+		opts.recorder = recorder
+	}
+}
+
+// NetworkSyslogRecorderOption sets the transport: udp (default), tcp or tls.
+func NetworkSyslogRecorderOption(network string) SyslogRecorderOption {
+	return func(opts *syslogRecorderOptions) {
+		opts.network = network
+	}
+}
+
+func FacilitySyslogRecorderOption(facility int) SyslogRecorderOption {
+	return func(opts *syslogRecorderOptions) {
+		opts.facility = facility
+	}
+}
+
+func HostnameSyslogRecorderOption(hostname string) SyslogRecorderOption {
+	return func(opts *syslogRecorderOptions) {
+		opts.hostname = hostname
+	}
+}
+
+// AppNameSyslogRecorderOption sets the APP-NAME template, such as gost-{service}.
+func AppNameSyslogRecorderOption(appName string) SyslogRecorderOption {
+	return func(opts *syslogRecorderOptions) {
+		opts.appName = appName
+	}
+}
+
+// MsgIDSyslogRecorderOption sets the MSGID template, such as {clientID}.
+func MsgIDSyslogRecorderOption(msgID string) SyslogRecorderOption {
+	return func(opts *syslogRecorderOptions) {
+		opts.msgID = msgID
+	}
+}
+
+func TimeoutSyslogRecorderOption(timeout time.Duration) SyslogRecorderOption {
+	return func(opts *syslogRecorderOptions) {
+		opts.timeout = timeout
+	}
+}
+
+func TLSConfigSyslogRecorderOption(tlsConfig *tls.Config) SyslogRecorderOption {
+	return func(opts *syslogRecorderOptions) {
+		opts.tlsConfig = tlsConfig
+	}
+}
+
+// EncoderSyslogRecorderOption sets the encoder of the message,
+// the templates are always executed with the original record.
+func EncoderSyslogRecorderOption(encoder Encoder) SyslogRecorderOption {
+	return func(opts *syslogRecorderOptions) {
+		opts.encoder = encoder
+	}
+}
+
+func LogSyslogRecorderOption(log logger.Logger) SyslogRecorderOption {
+	return func(opts *syslogRecorderOptions) {
+		opts.log = log
+	}
+}
+
+type syslogRecorder struct {
+	addr    string
+	options syslogRecorderOptions
+	appName recordTemplate
+	msgID   recordTemplate
+	procID  string
+	dialer  *net.Dialer
+
+	conn net.Conn
+	mu   sync.Mutex
+}
+
+// SyslogRecorder records data to syslog server in RFC 5424 format.
+// The messages are sent one per datagram over UDP, or framed by octet counting (RFC 6587) over TCP and TLS.
+// The delivery is at-least-once, see RecordBatch.
+func SyslogRecorder(addr string, opts ...SyslogRecorderOption) recorder.Recorder {
+	options := syslogRecorderOptions{
+		facility: defaultSyslogFacility,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if addr == "" {
+		return nil
+	}
+	if options.network == "" {
+		options.network = "udp"
+	}
+	if options.hostname == "" {
+		options.hostname, _ = os.Hostname()
+	}
+	if options.appName == "" {
+		options.appName = defaultSyslogAppName
+	}
+	if options.log == nil {
+		options.log = logger.Default()
+	}
+
+	return &syslogRecorder{
+		addr:    addr,
+		options: options,
+		appName: recordTemplate(options.appName),
+		msgID:   recordTemplate(options.msgID),
+		procID:  strconv.Itoa(os.Getpid()),
+		dialer: &net.Dialer{
+			Timeout: options.timeout,
+		},
+	}
+}
+
+func (r *syslogRecorder) Record(ctx context.Context, b []byte, opts ...recorder.RecordOption) error {
+	return r.RecordBatch(ctx, [][]byte{b})
+}
+
+// RecordBatch sends the records over the connection.
+// If the write fails, the whole batch is sent again once over a new connection,
+// the messages written before the failure may be received twice, so the delivery is at-least-once.
+func (r *syslogRecorder) RecordBatch(ctx context.Context, records [][]byte) error {
+	xmetrics.GetCounter(xmetrics.MetricRecorderRecordsCounter, metrics.Labels{"recorder": r.options.recorder}).Add(float64(len(records)))
+
+	var msgs [][]byte
+	for _, b := range records {
+		msg, err := r.format(b, time.Now())
+		if err != nil {
+			return err
+		}
+		if msg != nil {
+			msgs = append(msgs, msg)
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.write(ctx, msgs)
+	if err != nil && r.conn != nil {
+		// the connection may be closed by server, retry once with a new connection.
+		r.options.log.Debugf("syslog %s: %v, reconnecting", r.addr, err)
+		r.conn.Close()
+		r.conn = nil
+		err = r.write(ctx, msgs)
+	}
+	return err
+}
+
+func (r *syslogRecorder) write(ctx context.Context, msgs [][]byte) error {
+	if r.conn == nil {
+		conn, err := r.dial(ctx)
+		if err != nil {
+			return err
+		}
+		r.conn = conn
+	}
+
+	if r.options.timeout > 0 {
+		r.conn.SetWriteDeadline(time.Now().Add(r.options.timeout))
+	}
+
+	if r.options.network == "udp" {
+		for _, msg := range msgs {
+			if _, err := r.conn.Write(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, msg := range msgs {
+		buf.WriteString(strconv.Itoa(len(msg)))
+		buf.WriteByte(' ')
+		buf.Write(msg)
+	}
+	_, err := r.conn.Write(buf.Bytes())
+	return err
+}
+
+func (r *syslogRecorder) dial(ctx context.Context) (net.Conn, error) {
+	switch r.options.network {
+	case "udp":
+		return r.dialer.DialContext(ctx, "udp", r.addr)
+	case "tcp":
+		return r.dialer.DialContext(ctx, "tcp", r.addr)
+	case "tls":
+		cfg := r.options.tlsConfig
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		d := tls.Dialer{
+			NetDialer: r.dialer,
+			Config:    cfg,
+		}
+		return d.DialContext(ctx, "tcp", r.addr)
+	default:
+		return nil, fmt.Errorf("unknown network %s", r.options.network)
+	}
+}
+
+// format builds the syslog message:
+// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (r *syslogRecorder) format(b []byte, t time.Time) ([]byte, error) {
+	sev := syslogSeverityInfo
+	if severity(flatten(b)) > 1 {
+		sev = syslogSeverityWarning
+	}
+
+	msg := b
+	if r.options.encoder != nil {
+		v, err := r.options.encoder.Encode(b)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		msg = v
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %s %s - ",
+		r.options.facility*8+sev,
+		t.Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(r.options.hostname, 255),
+		syslogHeaderField(r.appName.Execute(b), 48),
+		syslogHeaderField(r.procID, 128),
+		syslogHeaderField(r.msgID.Execute(b), 32),
+	)
+	buf.Write(bytes.TrimRight(msg, "\r\n"))
+
+	return buf.Bytes(), nil
+}
+
+func (r *syslogRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn != nil {
+		err := r.conn.Close()
+		r.conn = nil
+		return err
+	}
+	return nil
+}
+
+// syslogHeaderField converts s to the header field, which is printable US-ASCII without space.
+func syslogHeaderField(s string, max int) string {
+	if s == "" {
+		return "-"
+	}
+
+	b := []byte(s)
+	for i, c := range b {
+		if c < 33 || c > 126 {
+			b[i] = '_'
+		}
+	}
+	if len(b) > max {
+		b = b[:max]
+	}
+	return string(b)
+}
@@ -0,0 +1,76 @@
+package recorder
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogRecorder(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	msgs := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// octet counting framing.
+		br := bufio.NewReader(conn)
+		for {
+			s, err := br.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(s))
+			b := make([]byte, n)
+			if _, err := io.ReadFull(br, b); err != nil {
+				return
+			}
+			msgs <- string(b)
+		}
+	}()
+
+	r := SyslogRecorder(ln.Addr().String(),
+		NetworkSyslogRecorderOption("tcp"),
+		HostnameSyslogRecorderOption("gost-0"),
+		AppNameSyslogRecorderOption("gost-{service}"),
+		MsgIDSyslogRecorderOption("{clientID}"),
+	)
+	defer r.(*syslogRecorder).Close()
+
+	err = r.(*syslogRecorder).RecordBatch(context.Background(), [][]byte{
+		[]byte(`{"service":"svc 0","clientID":"user-0","err":"refused"}`),
+		[]byte(`{"service":"svc-1"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*regexp.Regexp{
+		regexp.MustCompile(`^<132>1 \S+ gost-0 gost-svc_0 \d+ user-0 - \{"service":"svc 0","clientID":"user-0","err":"refused"\}$`),
+		regexp.MustCompile(`^<134>1 \S+ gost-0 gost-svc-1 \d+ - - \{"service":"svc-1"\}$`),
+	}
+	for _, re := range want {
+		select {
+		case msg := <-msgs:
+			if !re.MatchString(msg) {
+				t.Errorf("unexpected message %s", msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package recorder
+
+import (
+	"strings"
+)
+
+// recordTemplate is a string with the placeholders of the record fields, such as {service}, {clientID} and {host},
+// the fields of the nested objects are referenced with dotted keys, e.g. {http.host}.
+// The unknown fields are replaced with empty string.
+type recordTemplate string
+
+func (t recordTemplate) isStatic() bool {
+	return !strings.Contains(string(t), "{")
+}
+
+// Execute replaces the placeholders with the fields of the JSON record.
+func (t recordTemplate) Execute(b []byte) string {
+	if t.isStatic() {
+		return string(t)
+	}
+
+	values := make(map[string]string)
+	for _, f := range flatten(b) {
+		values[f.key] = f.value
+	}
+
+	s := string(t)
+	var sb strings.Builder
+	for {
+		start := strings.IndexByte(s, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			break
+		}
+		sb.WriteString(s[:start])
+		sb.WriteString(values[s[start+1:start+end]])
+		s = s[start+end+1:]
+	}
+	sb.WriteString(s)
+
+	return sb.String()
+}
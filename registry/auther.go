@@ -37,3 +37,12 @@ func (w *autherWrapper) Authenticate(ctx context.Context, user, password string,
 	}
 	return v.Authenticate(ctx, user, password, opts...)
 }
+
+func (w *autherWrapper) List(ctx context.Context) map[string]string {
+	if lister, ok := w.r.get(w.name).(interface {
+		List(ctx context.Context) map[string]string
+	}); ok {
+		return lister.List(ctx)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/go-gost/core/chain"
+	"github.com/go-gost/core/hop"
 	"github.com/go-gost/core/metadata"
 	"github.com/go-gost/core/selector"
 )
@@ -55,6 +56,13 @@ func (w *chainWrapper) Metadata() metadata.Metadata {
 	return nil
 }
 
+func (w *chainWrapper) Hops() []hop.Hop {
+	if v, ok := w.r.get(w.name).(interface{ Hops() []hop.Hop }); ok {
+		return v.Hops()
+	}
+	return nil
+}
+
 func (w *chainWrapper) Route(ctx context.Context, network, address string, opts ...chain.RouteOption) chain.Route {
 	v := w.r.get(w.name)
 	if v == nil {
@@ -31,6 +31,10 @@ type hopWrapper struct {
 	r    *hopRegistry
 }
 
+func (w *hopWrapper) Name() string {
+	return w.name
+}
+
 func (w *hopWrapper) Nodes() []*chain.Node {
 	v := w.r.get(w.name)
 	if v == nil {
@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-gost/core/limiter"
+	"github.com/go-gost/core/limiter/traffic"
+	"github.com/go-gost/x/quota"
+)
+
+type quotaRegistry struct {
+	registry[quota.Quota]
+}
+
+func (r *quotaRegistry) Register(name string, v quota.Quota) error {
+	return r.registry.Register(name, v)
+}
+
+func (r *quotaRegistry) Get(name string) quota.Quota {
+	if name != "" {
+		return &quotaWrapper{name: name, r: r}
+	}
+	return nil
+}
+
+func (r *quotaRegistry) get(name string) quota.Quota {
+	return r.registry.Get(name)
+}
+
+type quotaWrapper struct {
+	name string
+	r    *quotaRegistry
+}
+
+func (w *quotaWrapper) Add(ctx context.Context, client string, bytes int64, connTime time.Duration) {
+	v := w.r.get(w.name)
+	if v == nil {
+		return
+	}
+	v.Add(ctx, client, bytes, connTime)
+}
+
+func (w *quotaWrapper) Exceeded(ctx context.Context, client string) bool {
+	v := w.r.get(w.name)
+	if v == nil {
+		return false
+	}
+	return v.Exceeded(ctx, client)
+}
+
+func (w *quotaWrapper) Usage(ctx context.Context, client string) *quota.ClientUsage {
+	v := w.r.get(w.name)
+	if v == nil {
+		return nil
+	}
+	return v.Usage(ctx, client)
+}
+
+func (w *quotaWrapper) Clients(ctx context.Context) []string {
+	v := w.r.get(w.name)
+	if v == nil {
+		return nil
+	}
+	return v.Clients(ctx)
+}
+
+func (w *quotaWrapper) In(ctx context.Context, key string, opts ...limiter.Option) traffic.Limiter {
+	if v, ok := w.r.get(w.name).(traffic.TrafficLimiter); ok {
+		return v.In(ctx, key, opts...)
+	}
+	return nil
+}
+
+func (w *quotaWrapper) Out(ctx context.Context, key string, opts ...limiter.Option) traffic.Limiter {
+	if v, ok := w.r.get(w.name).(traffic.TrafficLimiter); ok {
+		return v.Out(ctx, key, opts...)
+	}
+	return nil
+}
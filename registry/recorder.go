@@ -37,3 +37,8 @@ func (w *recorderWrapper) Record(ctx context.Context, b []byte, opts ...recorder
 	}
 	return v.Record(ctx, b, opts...)
 }
+
+// Unwrap returns the registered recorder.
+func (w *recorderWrapper) Unwrap() recorder.Recorder {
+	return w.r.get(w.name)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/go-gost/core/router"
 	"github.com/go-gost/core/sd"
 	"github.com/go-gost/core/service"
+	"github.com/go-gost/x/quota"
 )
 
 var (
@@ -52,6 +53,7 @@ var (
 	routerReg   reg.Registry[router.Router]     = new(routerRegistry)
 	sdReg       reg.Registry[sd.SD]             = new(sdRegistry)
 	observerReg reg.Registry[observer.Observer] = new(observerRegistry)
+	quotaReg    reg.Registry[quota.Quota]       = new(quotaRegistry)
 
 	loggerReg reg.Registry[logger.Logger] = new(loggerRegistry)
 )
@@ -185,6 +187,10 @@ func ObserverRegistry() reg.Registry[observer.Observer] {
 	return observerReg
 }
 
+func QuotaRegistry() reg.Registry[quota.Quota] {
+	return quotaReg
+}
+
 func LoggerRegistry() reg.Registry[logger.Logger] {
 	return loggerReg
 }
@@ -16,6 +16,8 @@ import (
 	"github.com/go-gost/core/logger"
 	xchain "github.com/go-gost/x/chain"
 	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
 )
 
 type Options struct {
@@ -68,12 +70,14 @@ type exchanger struct {
 	rawAddr string
 	router  chain.Router
 	client  *http.Client
+	doq     *doqClient
 	options Options
 }
 
 // NewExchanger create an Exchanger.
 // The addr should be URL-like format,
-// e.g. udp://1.1.1.1:53, tls://1.1.1.1:853, https://1.0.0.1/dns-query
+// e.g. udp://1.1.1.1:53, tls://1.1.1.1:853, https://1.0.0.1/dns-query,
+// doq://94.140.14.14:853 (DNS-over-QUIC), h3://1.1.1.1/dns-query (DNS-over-HTTP/3)
 func NewExchanger(addr string, opts ...Option) (Exchanger, error) {
 	var options Options
 	for _, opt := range opts {
@@ -100,7 +104,11 @@ func NewExchanger(addr string, opts ...Option) (Exchanger, error) {
 		options: options,
 	}
 	if _, port, _ := net.SplitHostPort(ex.addr); port == "" {
-		ex.addr = net.JoinHostPort(ex.addr, "53")
+		port = "53"
+		if ex.network == "doq" || ex.network == "quic" {
+			port = "853"
+		}
+		ex.addr = net.JoinHostPort(ex.addr, port)
 	}
 	if ex.router == nil {
 		ex.router = xchain.NewRouter(chain.LoggerRouterOption(options.logger))
@@ -125,7 +133,7 @@ func NewExchanger(addr string, opts ...Option) (Exchanger, error) {
 		ex.client = &http.Client{
 			Timeout: options.timeout,
 			Transport: &http.Transport{
-				TLSClientConfig:       options.tlsConfig,
+				TLSClientConfig:       ex.options.tlsConfig,
 				ForceAttemptHTTP2:     true,
 				MaxIdleConns:          100,
 				IdleConnTimeout:       90 * time.Second,
@@ -134,6 +142,39 @@ func NewExchanger(addr string, opts ...Option) (Exchanger, error) {
 				DialContext:           ex.dial,
 			},
 		}
+	case "doq", "quic":
+		if ex.options.tlsConfig == nil {
+			ex.options.tlsConfig = &tls.Config{
+				InsecureSkipVerify: true,
+			}
+		}
+		ex.network = "doq"
+		ex.doq = &doqClient{ex: ex}
+	case "h3", "doh3":
+		// DNS-over-HTTPS over HTTP/3
+		u.Scheme = "https"
+		ex.addr = u.String()
+		if ex.options.tlsConfig == nil {
+			ex.options.tlsConfig = &tls.Config{
+				InsecureSkipVerify: true,
+			}
+		}
+		ex.network = "h3"
+		ex.client = &http.Client{
+			Timeout: options.timeout,
+			Transport: &http3.Transport{
+				TLSClientConfig: ex.options.tlsConfig,
+				QUICConfig: &quic.Config{
+					HandshakeIdleTimeout: options.timeout,
+					Versions: []quic.Version{
+						quic.Version1,
+					},
+				},
+				Dial: func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (*quic.Conn, error) {
+					return ex.dialQUIC(ctx, addr, tlsCfg, cfg)
+				},
+			},
+		}
 	default:
 		ex.network = "udp"
 	}
@@ -142,8 +183,16 @@ func NewExchanger(addr string, opts ...Option) (Exchanger, error) {
 }
 
 func (ex *exchanger) Exchange(ctx context.Context, msg []byte) ([]byte, error) {
-	if ex.network == "https" {
+	switch ex.network {
+	case "https", "h3":
 		return ex.dohExchange(ctx, msg)
+	case "doq":
+		if ex.options.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, ex.options.timeout)
+			defer cancel()
+		}
+		return ex.doq.Exchange(ctx, msg)
 	}
 	return ex.exchange(ctx, msg)
 }
@@ -0,0 +1,193 @@
+package exchanger
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/chain"
+	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// testRouter dials the test host through the local address, like a chain which resolves the host remotely.
+type testRouter struct {
+	host string
+	addr string
+}
+
+func (r *testRouter) Options() *chain.RouterOptions {
+	return &chain.RouterOptions{}
+}
+
+func (r *testRouter) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	if host, port, _ := net.SplitHostPort(address); host == r.host {
+		address = net.JoinHostPort(r.addr, port)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, network, address)
+}
+
+func (r *testRouter) Bind(ctx context.Context, network, address string, opts ...chain.BindOption) (net.Listener, error) {
+	return nil, io.ErrUnexpectedEOF
+}
+
+func testTLSConfig(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dns.test"},
+		DNSNames:     []string{"dns.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{raw}, PrivateKey: key}},
+	}
+}
+
+func reply(t *testing.T, query []byte) []byte {
+	mq := &dns.Msg{}
+	if err := mq.Unpack(query); err != nil {
+		t.Error(err)
+		return nil
+	}
+	mr := (&dns.Msg{}).SetReply(mq)
+	rr, _ := dns.NewRR(mq.Question[0].Name + " 60 IN A 192.0.2.1")
+	mr.Answer = append(mr.Answer, rr)
+	b, _ := mr.Pack()
+	return b
+}
+
+func testExchange(t *testing.T, ex Exchanger) {
+	mq := (&dns.Msg{}).SetQuestion("example.com.", dns.TypeA)
+	mq.Id = 1234
+	query, _ := mq.Pack()
+
+	for i := 0; i < 2; i++ {
+		b, err := ex.Exchange(context.Background(), query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mr := &dns.Msg{}
+		if err := mr.Unpack(b); err != nil {
+			t.Fatal(err)
+		}
+		if mr.Id != mq.Id {
+			t.Errorf("got message ID %d, want %d", mr.Id, mq.Id)
+		}
+		if len(mr.Answer) != 1 || !strings.Contains(mr.Answer[0].String(), "192.0.2.1") {
+			t.Errorf("got answers %v", mr.Answer)
+		}
+	}
+}
+
+func TestDoQExchanger(t *testing.T) {
+	tlsCfg := testTLSConfig(t)
+	tlsCfg.NextProtos = []string{doqALPN}
+
+	ln, err := quic.ListenAddr("127.0.0.1:0", tlsCfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			go func() {
+				for {
+					stream, err := conn.AcceptStream(context.Background())
+					if err != nil {
+						return
+					}
+					var lb [2]byte
+					if _, err := io.ReadFull(stream, lb[:]); err != nil {
+						stream.Close()
+						continue
+					}
+					query := make([]byte, binary.BigEndian.Uint16(lb[:]))
+					if _, err := io.ReadFull(stream, query); err != nil {
+						stream.Close()
+						continue
+					}
+					if binary.BigEndian.Uint16(query) != 0 {
+						t.Error("the message ID of the query is not 0")
+					}
+					b := reply(t, query)
+					binary.BigEndian.PutUint16(lb[:], uint16(len(b)))
+					stream.Write(append(lb[:], b...))
+					stream.Close()
+				}
+			}()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	// the host is only known by the router.
+	ex, err := NewExchanger("doq://dns.test:"+port,
+		RouterOption(&testRouter{host: "dns.test", addr: "127.0.0.1"}),
+		TimeoutOption(5*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExchange(t, ex)
+}
+
+func TestDoH3Exchanger(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	srv := &http3.Server{
+		TLSConfig: http3.ConfigureTLSConfig(testTLSConfig(t)),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/dns-message")
+			w.Write(reply(t, query))
+		}),
+	}
+	go srv.Serve(pc)
+	defer srv.Close()
+
+	_, port, _ := net.SplitHostPort(pc.LocalAddr().String())
+	// the default TLS config of the exchanger skips the verification of the self-signed certificate.
+	ex, err := NewExchanger("h3://dns.test:"+port+"/dns-query",
+		RouterOption(&testRouter{host: "dns.test", addr: "127.0.0.1"}),
+		TimeoutOption(5*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExchange(t, ex)
+}
@@ -0,0 +1,177 @@
+package exchanger
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/quic-go/quic-go"
+)
+
+const (
+	// doqALPN is the ALPN token of DNS-over-QUIC, RFC 9250 section 4.1.1.
+	doqALPN = "doq"
+)
+
+// doqClient holds the shared QUIC connection of the DNS-over-QUIC exchanger.
+type doqClient struct {
+	ex   *exchanger
+	conn *quic.Conn
+	mu   sync.Mutex
+}
+
+func (c *doqClient) Exchange(ctx context.Context, msg []byte) ([]byte, error) {
+	if len(msg) < 2 {
+		return nil, errors.New("doq: invalid message")
+	}
+
+	conn, err := c.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		// the connection may be closed by peer, retry with a new one.
+		c.resetConn(conn)
+		if conn, err = c.getConn(ctx); err != nil {
+			return nil, err
+		}
+		if stream, err = conn.OpenStreamSync(ctx); err != nil {
+			c.resetConn(conn)
+			return nil, err
+		}
+	}
+	defer stream.CancelRead(0)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		stream.SetDeadline(deadline)
+	}
+
+	// The DNS Message ID MUST be set to 0, RFC 9250 section 4.2.1.
+	id := binary.BigEndian.Uint16(msg[:2])
+
+	b := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(b, uint16(len(msg)))
+	copy(b[2:], msg)
+	b[2], b[3] = 0, 0
+
+	if _, err := stream.Write(b); err != nil {
+		return nil, err
+	}
+	// the client MUST indicate through the STREAM FIN mechanism that no further data will be sent.
+	stream.Close()
+
+	var lb [2]byte
+	if _, err := io.ReadFull(stream, lb[:]); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, binary.BigEndian.Uint16(lb[:]))
+	if _, err := io.ReadFull(stream, reply); err != nil {
+		return nil, err
+	}
+	if len(reply) < 2 {
+		return nil, errors.New("doq: invalid reply")
+	}
+	binary.BigEndian.PutUint16(reply[:2], id)
+
+	return reply, nil
+}
+
+func (c *doqClient) getConn(ctx context.Context) (*quic.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		select {
+		case <-c.conn.Context().Done():
+		default:
+			return c.conn, nil
+		}
+	}
+
+	tlsCfg := c.ex.options.tlsConfig.Clone()
+	tlsCfg.NextProtos = []string{doqALPN}
+
+	conn, err := c.ex.dialQUIC(ctx, c.ex.addr, tlsCfg, &quic.Config{
+		HandshakeIdleTimeout: c.ex.options.timeout,
+		Versions: []quic.Version{
+			quic.Version1,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+
+	return conn, nil
+}
+
+func (c *doqClient) resetConn(conn *quic.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == conn {
+		c.conn = nil
+	}
+	conn.CloseWithError(0, "")
+}
+
+// dialQUIC establishes a QUIC connection over the UDP connection obtained from the router,
+// so the queries can traverse the chain.
+func (ex *exchanger) dialQUIC(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (*quic.Conn, error) {
+	if ex.options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ex.options.timeout)
+		defer cancel()
+	}
+
+	// the address is resolved by the route, the last node of the chain may resolve it remotely.
+	c, err := ex.dial(ctx, "udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	pc := &quicPacketConn{Conn: c, raddr: c.RemoteAddr()}
+	if pc.raddr == nil {
+		pc.raddr = &net.UDPAddr{}
+	}
+
+	if tlsCfg.ServerName == "" {
+		tlsCfg = tlsCfg.Clone()
+		tlsCfg.ServerName, _, _ = net.SplitHostPort(addr)
+	}
+
+	conn, err := quic.Dial(ctx, pc, pc.raddr, tlsCfg, cfg)
+	if err != nil {
+		pc.Close()
+		return nil, err
+	}
+
+	// the packet conn is not closed by QUIC connection.
+	go func() {
+		<-conn.Context().Done()
+		pc.Close()
+	}()
+
+	return conn, nil
+}
+
+// quicPacketConn adapts the connected UDP connection to net.PacketConn.
+type quicPacketConn struct {
+	net.Conn
+	raddr net.Addr
+}
+
+func (c *quicPacketConn) ReadFrom(b []byte) (n int, addr net.Addr, err error) {
+	n, err = c.Conn.Read(b)
+	addr = c.raddr
+	return
+}
+
+func (c *quicPacketConn) WriteTo(b []byte, addr net.Addr) (n int, err error) {
+	return c.Conn.Write(b)
+}
@@ -0,0 +1,351 @@
+package zone
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/x/internal/loader"
+	xlogger "github.com/go-gost/x/logger"
+	"github.com/miekg/dns"
+)
+
+type options struct {
+	records     []string
+	fileLoader  loader.Loader
+	redisLoader loader.Loader
+	httpLoader  loader.Loader
+	period      time.Duration
+	logger      logger.Logger
+}
+
+type Option func(opts *options)
+
+// RecordsOption sets the inline records in zone file format.
+func RecordsOption(records []string) Option {
+	return func(opts *options) {
+		opts.records = records
+	}
+}
+
+func ReloadPeriodOption(period time.Duration) Option {
+	return func(opts *options) {
+		opts.period = period
+	}
+}
+
+func FileLoaderOption(fileLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.fileLoader = fileLoader
+	}
+}
+
+func RedisLoaderOption(redisLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.redisLoader = redisLoader
+	}
+}
+
+func HTTPLoaderOption(httpLoader loader.Loader) Option {
+	return func(opts *options) {
+		opts.httpLoader = httpLoader
+	}
+}
+
+func LoggerOption(logger logger.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+// Zone is a set of local authoritative DNS zones.
+type Zone interface {
+	// Lookup answers the query from the local zone data.
+	// It returns nil if the query should be forwarded to the upstream.
+	Lookup(ctx context.Context, mq *dns.Msg) *dns.Msg
+}
+
+// rrset holds the records of a domain name, indexed by record type.
+type rrset map[uint16][]dns.RR
+
+// localZone serves DNS records loaded in RFC 1035 zone file format,
+// $ORIGIN and $TTL directives are supported.
+// A name covered by a SOA record is answered authoritatively (NXDOMAIN/NODATA),
+// other records only override the upstream answers of the same name.
+type localZone struct {
+	names      map[string]rrset
+	soas       map[string]*dns.SOA
+	options    options
+	logger     logger.Logger
+	mu         sync.RWMutex
+	cancelFunc context.CancelFunc
+}
+
+func NewZone(opts ...Option) Zone {
+	var options options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	z := &localZone{
+		names:      make(map[string]rrset),
+		soas:       make(map[string]*dns.SOA),
+		cancelFunc: cancel,
+		options:    options,
+		logger:     options.logger,
+	}
+	if z.logger == nil {
+		z.logger = xlogger.Nop()
+	}
+
+	go z.periodReload(ctx)
+
+	return z
+}
+
+func (z *localZone) Lookup(ctx context.Context, mq *dns.Msg) *dns.Msg {
+	if len(mq.Question) == 0 || mq.Question[0].Qclass != dns.ClassINET {
+		return nil
+	}
+	q := mq.Question[0]
+	name := strings.ToLower(dns.Fqdn(q.Name))
+
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+
+	if len(z.names) == 0 {
+		return nil
+	}
+
+	soa := z.findSOA(name)
+
+	mr := &dns.Msg{}
+	mr.SetReply(mq)
+	mr.Authoritative = soa != nil
+
+	set, ok := z.names[name]
+	if !ok {
+		set, ok = z.findWildcard(name)
+	}
+	if !ok {
+		if soa == nil {
+			return nil
+		}
+		mr.Rcode = dns.RcodeNameError
+		mr.Ns = append(mr.Ns, soa)
+		return mr
+	}
+
+	answer := z.answer(dns.Fqdn(q.Name), q.Qtype, set, 0)
+	if len(answer) == 0 {
+		if soa == nil {
+			return nil
+		}
+		// NODATA
+		mr.Ns = append(mr.Ns, soa)
+		return mr
+	}
+	mr.Answer = answer
+
+	return mr
+}
+
+// maxCNAMEChain limits the CNAME chasing inside the local zones.
+const maxCNAMEChain = 8
+
+// answer builds the answer records of set with the owner name, following the CNAME records.
+func (z *localZone) answer(owner string, qtype uint16, set rrset, depth int) (answer []dns.RR) {
+	if qtype == dns.TypeANY {
+		for _, rrs := range set {
+			answer = append(answer, copyRRs(owner, rrs)...)
+		}
+		return
+	}
+
+	if rrs := set[qtype]; len(rrs) > 0 {
+		return copyRRs(owner, rrs)
+	}
+
+	cnames := set[dns.TypeCNAME]
+	if len(cnames) == 0 {
+		return nil
+	}
+	answer = copyRRs(owner, cnames[:1])
+
+	target := strings.ToLower(cnames[0].(*dns.CNAME).Target)
+	if depth >= maxCNAMEChain {
+		return
+	}
+	next, ok := z.names[target]
+	if !ok {
+		next, ok = z.findWildcard(target)
+	}
+	if ok {
+		answer = append(answer, z.answer(target, qtype, next, depth+1)...)
+	}
+	return
+}
+
+// findSOA returns the SOA record of the closest zone containing name.
+func (z *localZone) findSOA(name string) dns.RR {
+	for off, end := 0, false; !end; off, end = dns.NextLabel(name, off) {
+		if soa := z.soas[name[off:]]; soa != nil {
+			return soa
+		}
+	}
+	return nil
+}
+
+// findWildcard looks up the wildcard record set of the closest encloser of name.
+func (z *localZone) findWildcard(name string) (rrset, bool) {
+	off, end := dns.NextLabel(name, 0)
+	for ; !end; off, end = dns.NextLabel(name, off) {
+		parent := name[off:]
+		if set, ok := z.names["*."+parent]; ok {
+			return set, true
+		}
+		if _, ok := z.names[parent]; ok {
+			break
+		}
+	}
+	return nil, false
+}
+
+func copyRRs(owner string, rrs []dns.RR) []dns.RR {
+	v := make([]dns.RR, 0, len(rrs))
+	for _, rr := range rrs {
+		rr = dns.Copy(rr)
+		rr.Header().Name = owner
+		v = append(v, rr)
+	}
+	return v
+}
+
+func (z *localZone) periodReload(ctx context.Context) error {
+	if err := z.reload(ctx); err != nil {
+		z.logger.Warnf("reload: %v", err)
+	}
+
+	period := z.options.period
+	if period <= 0 {
+		return nil
+	}
+	if period < time.Second {
+		period = time.Second
+	}
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := z.reload(ctx); err != nil {
+				z.logger.Warnf("reload: %v", err)
+				// return err
+			}
+			z.logger.Debug("zone reload done")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (z *localZone) reload(ctx context.Context) (err error) {
+	names := make(map[string]rrset)
+	soas := make(map[string]*dns.SOA)
+
+	var rrs []dns.RR
+	if len(z.options.records) > 0 {
+		rrs = append(rrs, z.parseRecords("records", strings.NewReader(strings.Join(z.options.records, "\n")))...)
+	}
+	rrs = append(rrs, z.load(ctx)...)
+
+	for _, rr := range rrs {
+		hdr := rr.Header()
+		if hdr.Class != dns.ClassINET {
+			continue
+		}
+		name := strings.ToLower(hdr.Name)
+		if soa, ok := rr.(*dns.SOA); ok {
+			soas[name] = soa
+		}
+		set := names[name]
+		if set == nil {
+			set = make(rrset)
+			names[name] = set
+		}
+		set[hdr.Rrtype] = append(set[hdr.Rrtype], rr)
+	}
+
+	z.logger.Debugf("load items %d", len(rrs))
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	z.names = names
+	z.soas = soas
+
+	return
+}
+
+func (z *localZone) load(ctx context.Context) (rrs []dns.RR) {
+	if z.options.fileLoader != nil {
+		if r := z.loadRecords(ctx, z.options.fileLoader); r != nil {
+			rrs = append(rrs, z.parseRecords("file", r)...)
+		}
+	}
+	if z.options.redisLoader != nil {
+		if r := z.loadRecords(ctx, z.options.redisLoader); r != nil {
+			rrs = append(rrs, z.parseRecords("redis", r)...)
+		}
+	}
+	if z.options.httpLoader != nil {
+		if r := z.loadRecords(ctx, z.options.httpLoader); r != nil {
+			rrs = append(rrs, z.parseRecords("http", r)...)
+		}
+	}
+	return
+}
+
+func (z *localZone) loadRecords(ctx context.Context, ld loader.Loader) io.Reader {
+	if lister, ok := ld.(loader.Lister); ok {
+		list, err := lister.List(ctx)
+		if err != nil {
+			z.logger.Warnf("loader: %v", err)
+		}
+		return strings.NewReader(strings.Join(list, "\n"))
+	}
+
+	r, err := ld.Load(ctx)
+	if err != nil {
+		z.logger.Warnf("loader: %v", err)
+	}
+	return r
+}
+
+func (z *localZone) parseRecords(source string, r io.Reader) (rrs []dns.RR) {
+	zp := dns.NewZoneParser(r, "", source)
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		rrs = append(rrs, rr)
+	}
+	if err := zp.Err(); err != nil {
+		z.logger.Warnf("%s: %v", source, err)
+	}
+	return
+}
+
+func (z *localZone) Close() error {
+	z.cancelFunc()
+	if z.options.fileLoader != nil {
+		z.options.fileLoader.Close()
+	}
+	if z.options.redisLoader != nil {
+		z.options.redisLoader.Close()
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package zone
+
+import (
+	"context"
+	"testing"
+
+	xlogger "github.com/go-gost/x/logger"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestZone(t *testing.T, records ...string) *localZone {
+	z := &localZone{
+		options: options{records: records},
+		logger:  xlogger.Nop(),
+	}
+	require.NoError(t, z.reload(context.Background()))
+	return z
+}
+
+func TestZoneLookup(t *testing.T) {
+	z := newTestZone(t,
+		"$ORIGIN corp.",
+		"$TTL 300",
+		"@ IN SOA ns.corp. admin.corp. 1 3600 600 86400 60",
+		"@ IN NS ns.corp.",
+		"ns IN A 10.0.0.1",
+		"www IN CNAME web",
+		"web IN A 10.0.0.2",
+		"*.apps IN A 10.0.0.3",
+		"txt IN TXT \"hello\"",
+		"_http._tcp IN SRV 10 5 80 web",
+		"override.example.com. IN A 192.168.0.1",
+	)
+
+	testCases := []struct {
+		name   string
+		qtype  uint16
+		rcode  int
+		answer int
+		auth   bool
+		nilMsg bool
+	}{
+		{name: "web.corp.", qtype: dns.TypeA, answer: 1, auth: true},
+		{name: "WWW.corp.", qtype: dns.TypeA, answer: 2, auth: true},
+		{name: "foo.apps.corp.", qtype: dns.TypeA, answer: 1, auth: true},
+		{name: "txt.corp.", qtype: dns.TypeTXT, answer: 1, auth: true},
+		{name: "_http._tcp.corp.", qtype: dns.TypeSRV, answer: 1, auth: true},
+		{name: "corp.", qtype: dns.TypeNS, answer: 1, auth: true},
+		{name: "web.corp.", qtype: dns.TypeAAAA, answer: 0, auth: true},
+		{name: "missing.corp.", qtype: dns.TypeA, rcode: dns.RcodeNameError, auth: true},
+		{name: "override.example.com.", qtype: dns.TypeA, answer: 1},
+		{name: "override.example.com.", qtype: dns.TypeAAAA, nilMsg: true},
+		{name: "example.org.", qtype: dns.TypeA, nilMsg: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"/"+dns.TypeToString[tc.qtype], func(t *testing.T) {
+			mq := &dns.Msg{}
+			mq.SetQuestion(tc.name, tc.qtype)
+
+			mr := z.Lookup(context.Background(), mq)
+			if tc.nilMsg {
+				assert.Nil(t, mr)
+				return
+			}
+			require.NotNil(t, mr)
+			assert.Equal(t, tc.rcode, mr.Rcode)
+			assert.Equal(t, tc.auth, mr.Authoritative)
+			assert.Len(t, mr.Answer, tc.answer)
+			for _, rr := range mr.Answer[:min(1, len(mr.Answer))] {
+				assert.Equal(t, dns.Fqdn(tc.name), dns.Fqdn(rr.Header().Name), "owner name")
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package routing
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+var (
+	// DefaultGeoIPDatabase is the MaxMind country database used by the GeoIP matchers
+	// if the database is not specified in the rule.
+	DefaultGeoIPDatabase = "GeoLite2-Country.mmdb"
+
+	geoIPReaders   = map[string]*geoIPReader{}
+	geoIPReadersMu sync.Mutex
+)
+
+// geoIPReader is a database shared by the matchers.
+type geoIPReader struct {
+	*maxminddb.Reader
+	file string
+	refs int
+}
+
+// release closes the database when it is not used by any matcher.
+func (r *geoIPReader) release() {
+	geoIPReadersMu.Lock()
+	defer geoIPReadersMu.Unlock()
+
+	if r.refs--; r.refs > 0 {
+		return
+	}
+	if geoIPReaders[r.file] == r {
+		delete(geoIPReaders, r.file)
+	}
+	r.Reader.Close()
+}
+
+type geoIPRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// openGeoIP opens the database file, the readers are shared by the rules,
+// the reader should be released when the rule is no longer used.
+func openGeoIP(file string) (*geoIPReader, error) {
+	if file == "" {
+		file = DefaultGeoIPDatabase
+	}
+
+	geoIPReadersMu.Lock()
+	defer geoIPReadersMu.Unlock()
+
+	if r := geoIPReaders[file]; r != nil {
+		r.refs++
+		return r, nil
+	}
+
+	mr, err := maxminddb.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	r := &geoIPReader{
+		Reader: mr,
+		file:   file,
+		refs:   1,
+	}
+	geoIPReaders[file] = r
+	return r, nil
+}
+
+// lookupCountry returns the ISO 3166-1 country code of ip in upper case.
+func lookupCountry(r *maxminddb.Reader, ip net.IP) string {
+	if r == nil || ip == nil {
+		return ""
+	}
+
+	var record geoIPRecord
+	if err := r.Lookup(ip, &record); err != nil {
+		return ""
+	}
+	if code := record.Country.ISOCode; code != "" {
+		return strings.ToUpper(code)
+	}
+	return strings.ToUpper(record.RegisteredCountry.ISOCode)
+}
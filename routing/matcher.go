@@ -6,10 +6,13 @@ import (
 	"net"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/go-gost/core/routing"
+	ja3_util "github.com/go-gost/x/internal/util/ja3"
 	"github.com/go-gost/x/registry"
 	"github.com/go-gost/x/routing/rules"
 	"golang.org/x/exp/slices"
@@ -21,7 +24,7 @@ var (
 
 func init() {
 	var matchers []string
-	for matcher := range httpFuncs {
+	for matcher := range defaultFuncs {
 		matchers = append(matchers, matcher)
 	}
 
@@ -50,8 +53,9 @@ func NewMatcher(rule string) (routing.Matcher, error) {
 	}
 
 	var matchers matchersTree
-	err = matchers.addRule(buildTree(), httpFuncs)
+	err = matchers.addRule(buildTree(), defaultFuncs)
 	if err != nil {
+		matchers.close()
 		return nil, fmt.Errorf("error while adding rule %s: %w", rule, err)
 	}
 
@@ -61,27 +65,55 @@ func NewMatcher(rule string) (routing.Matcher, error) {
 }
 
 func (m *matcher) Match(req *routing.Request) bool {
-	if m == nil {
+	if m == nil || req == nil {
+		return false
+	}
+
+	return m.tree.match(&Request{Request: *req})
+}
+
+func (m *matcher) MatchRequest(req *Request) bool {
+	if m == nil || req == nil {
 		return false
 	}
 
 	return m.tree.match(req)
 }
 
+// Close releases the resources held by the rule, such as the GeoIP databases.
+func (m *matcher) Close() error {
+	m.tree.close()
+	return nil
+}
+
 // matchersTree represents the matchers tree structure.
 type matchersTree struct {
-	// matcher is a matcher func used to match HTTP request properties.
+	// matcher is a matcher func used to match the request properties.
 	// If matcher is not nil, it means that this matcherTree is a leaf of the tree.
 	// It is therefore mutually exclusive with left and right.
-	matcher func(*routing.Request) bool
+	matcher func(*Request) bool
 	// operator to combine the evaluation of left and right leaves.
 	operator string
 	// Mutually exclusive with matcher.
 	left  *matchersTree
 	right *matchersTree
+	// release frees the resources of the matcher func.
+	release func()
 }
 
-func (m *matchersTree) match(req *routing.Request) bool {
+func (m *matchersTree) close() {
+	if m == nil {
+		return
+	}
+	if m.release != nil {
+		m.release()
+		m.release = nil
+	}
+	m.left.close()
+	m.right.close()
+}
+
+func (m *matchersTree) match(req *Request) bool {
 	if m == nil {
 		// This should never happen as it should have been detected during parsing.
 		return false
@@ -129,7 +161,7 @@ func (m *matchersTree) addRule(rule *rules.Tree, funcs matcherFuncs) error {
 
 		if rule.Not {
 			matcherFunc := m.matcher
-			m.matcher = func(req *routing.Request) bool {
+			m.matcher = func(req *Request) bool {
 				return !matcherFunc(req)
 			}
 		}
@@ -138,7 +170,7 @@ func (m *matchersTree) addRule(rule *rules.Tree, funcs matcherFuncs) error {
 	return nil
 }
 
-var httpFuncs = map[string]func(*matchersTree, ...string) error{
+var defaultFuncs = map[string]func(*matchersTree, ...string) error{
 	"ClientIP":     expectNParameters(clientIP, 1),
 	"Proto":        expectNParameters(proto, 1),
 	"Host":         expectNParameters(host, 1),
@@ -153,6 +185,17 @@ var httpFuncs = map[string]func(*matchersTree, ...string) error{
 	"QueryRegexp":  expectNParameters(queryRegexp, 1, 2),
 	"Bypass":       expectNParameters(bypass, 1),
 	"Admission":    expectNParameters(admission, 1),
+	"DstPort":      expectNParameters(dstPort, 1),
+	"DstIP":        expectNParameters(dstIP, 1),
+	"SNI":          expectNParameters(sni, 1),
+	"SNIRegexp":    expectNParameters(sniRegexp, 1),
+	"ALPN":         expectNParameters(alpn, 1),
+	"JA3":          expectNParameters(ja3, 1),
+	"JA4":          expectNParameters(ja4, 1),
+	"User":         expectNParameters(user, 1),
+	"Time":         expectNParameters(timeRange, 2),
+	"GeoIP":        expectNParameters(geoIP, 1, 2),
+	"ClientGeoIP":  expectNParameters(clientGeoIP, 1, 2),
 }
 
 func expectNParameters(fn func(*matchersTree, ...string) error, n ...int) func(*matchersTree, ...string) error {
@@ -176,7 +219,7 @@ func clientIP(tree *matchersTree, clientIP ...string) error {
 		return fmt.Errorf("invalid value %q for ClientIP matcher", clientIP[0])
 	}
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		if req.ClientIP == nil {
 			return false
 		}
@@ -194,7 +237,7 @@ func clientIP(tree *matchersTree, clientIP ...string) error {
 func proto(tree *matchersTree, protos ...string) error {
 	proto := strings.ToLower(protos[0])
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		// logger.Default().Debugf("proto: %s %s", proto, req.Protocol)
 		return proto == req.Protocol
 	}
@@ -205,7 +248,7 @@ func proto(tree *matchersTree, protos ...string) error {
 func method(tree *matchersTree, methods ...string) error {
 	method := strings.ToUpper(methods[0])
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		return method == req.Method
 	}
 
@@ -228,25 +271,29 @@ func host(tree *matchersTree, hosts ...string) error {
 		}
 	}
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		// logger.Default().Debugf("host: %s %s", host, req.Host)
-		reqHost := strings.ToLower(strings.TrimSpace(parseHost(req.Host)))
-		if len(reqHost) == 0 {
-			return false
-		}
-
-		if reqHost == host {
-			return true
-		}
+		return matchHost(host, req.Host)
+	}
 
-		if host[0] == '.' && strings.HasSuffix(reqHost, host[1:]) {
-			return true
-		}
+	return nil
+}
 
+func matchHost(pattern string, host string) bool {
+	host = strings.ToLower(strings.TrimSpace(parseHost(host)))
+	if len(host) == 0 {
 		return false
 	}
 
-	return nil
+	if host == pattern {
+		return true
+	}
+
+	if pattern[0] == '.' && strings.HasSuffix(host, pattern[1:]) {
+		return true
+	}
+
+	return false
 }
 
 func hostRegexp(tree *matchersTree, hosts ...string) error {
@@ -261,7 +308,7 @@ func hostRegexp(tree *matchersTree, hosts ...string) error {
 		return fmt.Errorf("compiling HostRegexp matcher: %w", err)
 	}
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		// logger.Default().Debugf("hostRegexp: %s %s", host, req.Host)
 		return re.MatchString(strings.ToLower(strings.TrimSpace(parseHost(req.Host))))
 	}
@@ -276,7 +323,7 @@ func path(tree *matchersTree, paths ...string) error {
 		return fmt.Errorf("path %q does not start with a '/'", path)
 	}
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		return req.Path == path
 	}
 
@@ -291,7 +338,7 @@ func pathRegexp(tree *matchersTree, paths ...string) error {
 		return fmt.Errorf("compiling PathPrefix matcher: %w", err)
 	}
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		return re.MatchString(req.Path)
 	}
 
@@ -305,7 +352,7 @@ func pathPrefix(tree *matchersTree, paths ...string) error {
 		return fmt.Errorf("path %q does not start with a '/'", path)
 	}
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		return strings.HasPrefix(req.Path, path)
 	}
 
@@ -322,7 +369,7 @@ func header(tree *matchersTree, headers ...string) error {
 		hasValue = true
 	}
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		if req.Header == nil {
 			return false
 		}
@@ -360,7 +407,7 @@ func headerRegexp(tree *matchersTree, headers ...string) error {
 		return fmt.Errorf("compiling HeaderRegexp matcher: %w", err)
 	}
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		if req.Header == nil {
 			return false
 		}
@@ -387,7 +434,7 @@ func query(tree *matchersTree, queries ...string) error {
 		hasValue = true
 	}
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		if req.Query == nil {
 			return false
 		}
@@ -419,7 +466,7 @@ func queryRegexp(tree *matchersTree, queries ...string) error {
 		return fmt.Errorf("compiling QueryRegexp matcher: %w", err)
 	}
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		if req.Query == nil {
 			return false
 		}
@@ -442,7 +489,7 @@ func queryRegexp(tree *matchersTree, queries ...string) error {
 func admission(tree *matchersTree, names ...string) error {
 	name := names[0]
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		if req.ClientIP == nil {
 			return false
 		}
@@ -458,7 +505,7 @@ func admission(tree *matchersTree, names ...string) error {
 func bypass(tree *matchersTree, names ...string) error {
 	name := names[0]
 
-	tree.matcher = func(req *routing.Request) bool {
+	tree.matcher = func(req *Request) bool {
 		if bp := registry.BypassRegistry().Get(name); bp != nil {
 			v := bp.Contains(context.Background(), "tcp", req.Host)
 			return !v
@@ -469,6 +516,267 @@ func bypass(tree *matchersTree, names ...string) error {
 	return nil
 }
 
+func dstPort(tree *matchersTree, ports ...string) error {
+	type portRange struct {
+		min, max int
+	}
+
+	var ranges []portRange
+	for _, v := range strings.Split(ports[0], ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		lo, hi, found := strings.Cut(v, "-")
+		min, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil || min < 0 || min > 65535 {
+			return fmt.Errorf("invalid value %q for DstPort matcher", ports[0])
+		}
+		max := min
+		if found {
+			max, err = strconv.Atoi(strings.TrimSpace(hi))
+			if err != nil || max < min || max > 65535 {
+				return fmt.Errorf("invalid value %q for DstPort matcher", ports[0])
+			}
+		}
+		ranges = append(ranges, portRange{min: min, max: max})
+	}
+	if len(ranges) == 0 {
+		return fmt.Errorf("invalid value %q for DstPort matcher", ports[0])
+	}
+
+	tree.matcher = func(req *Request) bool {
+		_, sp, err := net.SplitHostPort(req.dstAddr())
+		if err != nil {
+			return false
+		}
+		port, err := strconv.Atoi(sp)
+		if err != nil {
+			return false
+		}
+
+		for _, r := range ranges {
+			if port >= r.min && port <= r.max {
+				return true
+			}
+		}
+		return false
+	}
+
+	return nil
+}
+
+func dstIP(tree *matchersTree, ips ...string) error {
+	ip := net.ParseIP(ips[0])
+
+	var ipNet *net.IPNet
+	if ip == nil {
+		_, ipNet, _ = net.ParseCIDR(ips[0])
+	}
+	if ip == nil && ipNet == nil {
+		return fmt.Errorf("invalid value %q for DstIP matcher", ips[0])
+	}
+
+	tree.matcher = func(req *Request) bool {
+		dip := net.ParseIP(parseHost(req.dstAddr()))
+		if dip == nil {
+			return false
+		}
+
+		if ip != nil {
+			return ip.Equal(dip)
+		}
+
+		return ipNet.Contains(dip)
+	}
+
+	return nil
+}
+
+func sni(tree *matchersTree, names ...string) error {
+	name := names[0]
+
+	if !IsASCII(name) {
+		return fmt.Errorf("invalid value %q for SNI matcher, non-ASCII characters are not allowed", name)
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if strings.HasPrefix(name, "*") {
+		name = name[1:]
+		if !strings.HasPrefix(name, ".") {
+			name = "." + name
+		}
+	}
+
+	tree.matcher = func(req *Request) bool {
+		return matchHost(name, req.SNI)
+	}
+
+	return nil
+}
+
+func sniRegexp(tree *matchersTree, names ...string) error {
+	name := names[0]
+
+	if !IsASCII(name) {
+		return fmt.Errorf("invalid value %q for SNIRegexp matcher, non-ASCII characters are not allowed", name)
+	}
+
+	re, err := regexp.Compile(name)
+	if err != nil {
+		return fmt.Errorf("compiling SNIRegexp matcher: %w", err)
+	}
+
+	tree.matcher = func(req *Request) bool {
+		if req.SNI == "" {
+			return false
+		}
+		return re.MatchString(strings.ToLower(req.SNI))
+	}
+
+	return nil
+}
+
+func alpn(tree *matchersTree, protos ...string) error {
+	proto := strings.TrimSpace(protos[0])
+
+	tree.matcher = func(req *Request) bool {
+		return slices.Contains(req.ALPN, proto)
+	}
+
+	return nil
+}
+
+// ja3 matches the JA3 fingerprint, the value can be either the fingerprint string or its MD5 hash.
+// The GREASE values in the fingerprint string are ignored, as in the fingerprint of the request.
+func ja3(tree *matchersTree, values ...string) error {
+	value := strings.ToLower(strings.TrimSpace(values[0]))
+	isHash := len(value) == 32 && !strings.ContainsAny(value, ",-")
+	if !isHash {
+		data, err := ja3_util.ParseJA3(value)
+		if err != nil {
+			return fmt.Errorf("JA3 matcher: %w", err)
+		}
+		value = data.String()
+	}
+
+	tree.matcher = func(req *Request) bool {
+		if req.JA3 == "" {
+			return false
+		}
+		if isHash {
+			return ja3_util.Hash(req.JA3) == value
+		}
+		return req.JA3 == value
+	}
+
+	return nil
+}
+
+func ja4(tree *matchersTree, values ...string) error {
+	value := strings.TrimSpace(values[0])
+
+	tree.matcher = func(req *Request) bool {
+		return req.JA4 != "" && strings.EqualFold(req.JA4, value)
+	}
+
+	return nil
+}
+
+func user(tree *matchersTree, users ...string) error {
+	user := users[0]
+
+	tree.matcher = func(req *Request) bool {
+		return req.User != "" && req.User == user
+	}
+
+	return nil
+}
+
+// timeRange matches the local time of day in the window [start, end),
+// the window wraps around midnight if end is before start, e.g. Time(`22:00`, `06:00`).
+func timeRange(tree *matchersTree, values ...string) error {
+	parse := func(s string) (time.Duration, error) {
+		t, err := time.Parse("15:04", strings.TrimSpace(s))
+		if err != nil {
+			t, err = time.Parse("15:04:05", strings.TrimSpace(s))
+		}
+		if err != nil {
+			return 0, fmt.Errorf("invalid value %q for Time matcher", s)
+		}
+		return time.Duration(t.Hour())*time.Hour +
+			time.Duration(t.Minute())*time.Minute +
+			time.Duration(t.Second())*time.Second, nil
+	}
+
+	start, err := parse(values[0])
+	if err != nil {
+		return err
+	}
+	end, err := parse(values[1])
+	if err != nil {
+		return err
+	}
+
+	tree.matcher = func(req *Request) bool {
+		t := req.time()
+		d := time.Duration(t.Hour())*time.Hour +
+			time.Duration(t.Minute())*time.Minute +
+			time.Duration(t.Second())*time.Second
+
+		if start <= end {
+			return d >= start && d < end
+		}
+		return d >= start || d < end
+	}
+
+	return nil
+}
+
+// geoIP matches the country of the destination IP address.
+func geoIP(tree *matchersTree, values ...string) error {
+	country := strings.ToUpper(strings.TrimSpace(values[0]))
+
+	var db string
+	if len(values) == 2 {
+		db = values[1]
+	}
+	r, err := openGeoIP(db)
+	if err != nil {
+		return fmt.Errorf("GeoIP matcher: %w", err)
+	}
+
+	tree.matcher = func(req *Request) bool {
+		return lookupCountry(r.Reader, net.ParseIP(parseHost(req.dstAddr()))) == country
+	}
+	tree.release = r.release
+
+	return nil
+}
+
+// clientGeoIP matches the country of the client IP address.
+func clientGeoIP(tree *matchersTree, values ...string) error {
+	country := strings.ToUpper(strings.TrimSpace(values[0]))
+
+	var db string
+	if len(values) == 2 {
+		db = values[1]
+	}
+	r, err := openGeoIP(db)
+	if err != nil {
+		return fmt.Errorf("ClientGeoIP matcher: %w", err)
+	}
+
+	tree.matcher = func(req *Request) bool {
+		return lookupCountry(r.Reader, req.ClientIP) == country
+	}
+	tree.release = r.release
+
+	return nil
+}
+
 // IsASCII checks if the given string contains only ASCII characters.
 func IsASCII(s string) bool {
 	for i := range len(s) {
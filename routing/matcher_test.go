@@ -0,0 +1,70 @@
+package routing
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/routing"
+	ja3_util "github.com/go-gost/x/internal/util/ja3"
+)
+
+func TestMatchRequest(t *testing.T) {
+	ja3 := "771,4865-4866-4867,0-23-65281,29-23-24,0"
+
+	testCases := []struct {
+		rule  string
+		req   Request
+		match bool
+	}{
+		{rule: "DstPort(`443`)", req: Request{DstAddr: "1.2.3.4:443"}, match: true},
+		{rule: "DstPort(`80,8000-9000`)", req: Request{DstAddr: "example.com:8080"}, match: true},
+		{rule: "DstPort(`8000-9000`)", req: Request{DstAddr: "example.com:443"}, match: false},
+		{rule: "DstPort(`443`)", req: Request{Request: routing.Request{Host: "example.com:443"}}, match: true},
+		{rule: "DstIP(`10.0.0.0/8`)", req: Request{DstAddr: "10.1.2.3:22"}, match: true},
+		{rule: "DstIP(`10.0.0.0/8`)", req: Request{DstAddr: "example.com:22"}, match: false},
+		{rule: "SNI(`*.example.com`)", req: Request{SNI: "www.example.com"}, match: true},
+		{rule: "SNI(`example.com`)", req: Request{SNI: "www.example.com"}, match: false},
+		{rule: "SNIRegexp(`^api\\.`)", req: Request{SNI: "api.example.com"}, match: true},
+		{rule: "ALPN(`h2`)", req: Request{ALPN: []string{"h2", "http/1.1"}}, match: true},
+		{rule: "ALPN(`h2`)", req: Request{ALPN: []string{"http/1.1"}}, match: false},
+		{rule: "JA3(`" + ja3 + "`)", req: Request{JA3: ja3}, match: true},
+		{rule: "JA3(`a0e9f5d64349fb13191bc781f81f42e1`)", req: Request{JA3: ja3}, match: false},
+		{rule: "JA3(`" + ja3_util.Hash(ja3) + "`)", req: Request{JA3: ja3}, match: true},
+		// the GREASE values are ignored.
+		{rule: "JA3(`771,2570-4865-4866-4867,2570-0-23-65281-6682,2570-29-23-24,0`)", req: Request{JA3: ja3}, match: true},
+		{rule: "JA4(`t13d1516h2_8daaf6152771_02713d6af862`)", req: Request{JA4: "t13d1516h2_8daaf6152771_02713d6af862"}, match: true},
+		{rule: "User(`alice`)", req: Request{User: "alice"}, match: true},
+		{rule: "User(`alice`)", req: Request{}, match: false},
+		{rule: "Time(`09:00`, `18:00`)", req: Request{Time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)}, match: true},
+		{rule: "Time(`09:00`, `18:00`)", req: Request{Time: time.Date(2024, 1, 1, 18, 0, 0, 0, time.Local)}, match: false},
+		{rule: "Time(`22:00`, `06:00`)", req: Request{Time: time.Date(2024, 1, 1, 23, 30, 0, 0, time.Local)}, match: true},
+		{rule: "SNI(`example.com`) && DstPort(`443`)", req: Request{SNI: "example.com", DstAddr: "1.2.3.4:443"}, match: true},
+		{rule: "!ClientIP(`192.168.0.0/16`) || User(`bob`)", req: Request{Request: routing.Request{ClientIP: net.ParseIP("192.168.1.1")}}, match: false},
+	}
+
+	for _, tc := range testCases {
+		m, err := NewMatcher(tc.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.rule, err)
+		}
+		req := tc.req
+		if got := m.(Matcher).MatchRequest(&req); got != tc.match {
+			t.Errorf("%s: got %v, want %v", tc.rule, got, tc.match)
+		}
+	}
+}
+
+func TestMatcherInvalid(t *testing.T) {
+	for _, rule := range []string{
+		"DstPort(`http`)",
+		"DstPort(`9000-8000`)",
+		"DstIP(`example.com`)",
+		"Time(`25:00`, `06:00`)",
+		"JA3(`771,4865`)",
+	} {
+		if _, err := NewMatcher(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package routing
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-gost/core/routing"
+	xctx "github.com/go-gost/x/ctx"
+)
+
+// Request extends routing.Request with the properties of the raw TCP/TLS flows.
+type Request struct {
+	routing.Request
+	// DstAddr is the destination address of the flow in host:port form,
+	// Host is used if it is empty.
+	DstAddr string
+	// SNI is the server name of the TLS ClientHello.
+	SNI  string
+	ALPN []string
+	// JA3 is the JA3 fingerprint string (not hashed) of the TLS ClientHello.
+	JA3 string
+	JA4 string
+	// User is the authenticated client ID.
+	User string
+	// Time is the time of the request, the current time is used if it is zero.
+	Time time.Time
+}
+
+// NewRequest creates a Request from req,
+// the properties of the flow are obtained from ctx.
+func NewRequest(ctx context.Context, req *routing.Request, dstAddr string) *Request {
+	r := &Request{
+		DstAddr: dstAddr,
+		User:    string(xctx.ClientIDFromContext(ctx)),
+	}
+	if req != nil {
+		r.Request = *req
+	}
+	if info := xctx.TLSInfoFromContext(ctx); info != nil {
+		r.SNI = info.ServerName
+		r.ALPN = info.ALPN
+		r.JA3 = info.JA3
+		r.JA4 = info.JA4
+	}
+	return r
+}
+
+// Matcher is a routing.Matcher which can also match the extended request.
+type Matcher interface {
+	routing.Matcher
+	MatchRequest(req *Request) bool
+}
+
+func (r *Request) dstAddr() string {
+	if r.DstAddr != "" {
+		return r.DstAddr
+	}
+	return r.Host
+}
+
+func (r *Request) time() time.Time {
+	if r.Time.IsZero() {
+		return time.Now()
+	}
+	return r.Time
+}
@@ -0,0 +1,232 @@
+package sd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/sd"
+)
+
+const (
+	fileLockRetryInterval = 10 * time.Millisecond
+	// a lock older than this is left by a crashed process.
+	fileLockStale = 10 * time.Second
+)
+
+type fileContent struct {
+	Services []*record `json:"services"`
+}
+
+// fileSD stores the services in a JSON file shared by the nodes on the same host.
+// The file is updated under a lock file and replaced atomically, so it can be read without locking.
+type fileSD struct {
+	path     string
+	ttl      time.Duration
+	services map[string]*record
+	mu       sync.Mutex
+	log      logger.Logger
+}
+
+// FileSD creates a service discovery backed by a shared file.
+func FileSD(path string, opts ...Option) sd.SD {
+	options := newOptions(opts)
+
+	return &fileSD{
+		path:     path,
+		ttl:      options.ttl,
+		services: make(map[string]*record),
+		log:      options.logger,
+	}
+}
+
+func (p *fileSD) Register(ctx context.Context, service *sd.Service, opts ...sd.Option) error {
+	if service == nil {
+		return nil
+	}
+
+	r := newRecord(service)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.services[serviceKey(r.Name, r.ID)] = r
+
+	return p.update(ctx, func(records []*record) []*record {
+		return p.put(records, r)
+	})
+}
+
+func (p *fileSD) Deregister(ctx context.Context, service *sd.Service) error {
+	if service == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.services, serviceKey(service.Name, service.ID))
+
+	return p.update(ctx, func(records []*record) []*record {
+		for i, v := range records {
+			if v.Name == service.Name && v.ID == service.ID {
+				return append(records[:i], records[i+1:]...)
+			}
+		}
+		return records
+	})
+}
+
+func (p *fileSD) Renew(ctx context.Context, service *sd.Service) error {
+	if service == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r := p.services[serviceKey(service.Name, service.ID)]
+
+	return p.update(ctx, func(records []*record) []*record {
+		if r != nil {
+			return p.put(records, r)
+		}
+
+		// not registered by this node, only extend the lifetime of the existing service.
+		for _, v := range records {
+			if v.Name == service.Name && v.ID == service.ID {
+				v.Expire = time.Now().Add(p.ttl).UnixMilli()
+			}
+		}
+		return records
+	})
+}
+
+func (p *fileSD) Get(ctx context.Context, name string) (services []*sd.Service, err error) {
+	records, err := p.read()
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	for _, r := range records {
+		if r.Name == name && r.Expire > now {
+			services = append(services, r.service())
+		}
+	}
+	return
+}
+
+// put adds or replaces the record with a new expiration time.
+func (p *fileSD) put(records []*record, r *record) []*record {
+	v := *r
+	v.Expire = time.Now().Add(p.ttl).UnixMilli()
+
+	for i := range records {
+		if records[i].Name == v.Name && records[i].ID == v.ID {
+			records[i] = &v
+			return records
+		}
+	}
+	return append(records, &v)
+}
+
+// update modifies the records under the file lock, the expired records are dropped.
+func (p *fileSD) update(ctx context.Context, f func(records []*record) []*record) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.unlock()
+
+	records, err := p.read()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	n := 0
+	for _, r := range records {
+		if r.Expire > now {
+			records[n] = r
+			n++
+		}
+	}
+
+	return p.write(f(records[:n]))
+}
+
+func (p *fileSD) read() ([]*record, error) {
+	b, err := os.ReadFile(p.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	var content fileContent
+	if err := json.Unmarshal(b, &content); err != nil {
+		return nil, err
+	}
+	return content.Services, nil
+}
+
+func (p *fileSD) write(records []*record) error {
+	b, err := json.MarshalIndent(&fileContent{Services: records}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(p.path), filepath.Base(p.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), p.path)
+}
+
+func (p *fileSD) lock(ctx context.Context) error {
+	name := p.path + ".lock"
+	for {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			return f.Close()
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return err
+		}
+
+		if fi, err := os.Stat(name); err == nil && time.Since(fi.ModTime()) > fileLockStale {
+			p.log.Warnf("sd: remove stale lock %s", name)
+			os.Remove(name)
+			continue
+		}
+
+		select {
+		case <-time.After(fileLockRetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *fileSD) unlock() {
+	os.Remove(p.path + ".lock")
+}
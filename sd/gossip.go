@@ -0,0 +1,442 @@
+package sd
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/sd"
+	"github.com/google/uuid"
+)
+
+const (
+	gossipMaxMessageSize = 16 * 1024 * 1024
+)
+
+// gossipRecord is a service in the mesh, it is only updated by its origin node.
+type gossipRecord struct {
+	record
+	Origin string `json:"origin"`
+	// the update time of the record on the origin node in unix nanoseconds.
+	Version int64 `json:"version"`
+	Deleted bool  `json:"deleted,omitempty"`
+	// remaining lifetime in milliseconds when the record is sent,
+	// so the expiration does not depend on the clocks of the nodes.
+	TTL int64 `json:"ttl"`
+
+	expire time.Time
+}
+
+type gossipMessage struct {
+	ID      string          `json:"id"`
+	Addr    string          `json:"addr"`
+	Peers   []string        `json:"peers,omitempty"`
+	Records []*gossipRecord `json:"records,omitempty"`
+}
+
+type gossipPeer struct {
+	seed     bool
+	lastSeen time.Time
+}
+
+// gossipSD replicates the services among the nodes of a mesh.
+// Each node periodically exchanges all the records and the known peers with some random peers over HTTP,
+// the newer version of a record wins, the records which are not renewed by their origin nodes expire.
+type gossipSD struct {
+	id       string
+	addr     string
+	ttl      time.Duration
+	interval time.Duration
+	secret   string
+	peers    map[string]*gossipPeer
+	// the addresses which turn out to be this node.
+	selves  map[string]bool
+	records map[string]*gossipRecord
+	mu      sync.Mutex
+	client  *http.Client
+	server  *http.Server
+	// the listen error has been logged.
+	listenFailed bool
+	notify       chan struct{}
+	cancel       context.CancelFunc
+	log          logger.Logger
+}
+
+// GossipSD creates a service discovery which joins the peer mesh,
+// addr is the address to listen on for the peers, and is also advertised to them.
+// If the host of addr is empty, the peers use the source address of the requests.
+func GossipSD(addr string, opts ...Option) sd.SD {
+	options := newOptions(opts)
+
+	interval := options.interval
+	if interval <= 0 {
+		interval = defaultGossipInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &gossipSD{
+		id:       uuid.NewString(),
+		addr:     addr,
+		ttl:      options.ttl,
+		interval: interval,
+		secret:   options.secret,
+		peers:    make(map[string]*gossipPeer),
+		selves:   make(map[string]bool),
+		records:  make(map[string]*gossipRecord),
+		client: &http.Client{
+			Timeout: interval,
+		},
+		notify: make(chan struct{}, 1),
+		cancel: cancel,
+		log:    options.logger,
+	}
+	for _, peer := range options.peers {
+		if peer != "" {
+			p.peers[peer] = &gossipPeer{seed: true}
+		}
+	}
+
+	if p.secret == "" && !isLoopback(addr) {
+		p.log.Warnf("sd: gossip: no secret is set, any host which can reach %s can inject services", addr)
+	}
+
+	p.listen(ctx)
+	go p.run(ctx)
+
+	return p
+}
+
+func (p *gossipSD) Register(ctx context.Context, service *sd.Service, opts ...sd.Option) error {
+	if service == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	p.records[serviceKey(service.Name, service.ID)] = p.newRecord(newRecord(service), false)
+	p.mu.Unlock()
+
+	p.trigger()
+	return nil
+}
+
+func (p *gossipSD) Deregister(ctx context.Context, service *sd.Service) error {
+	if service == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := serviceKey(service.Name, service.ID)
+	if r := p.records[key]; r != nil && r.Origin == p.id {
+		// the tombstone replaces the record on the peers until it expires.
+		p.records[key] = p.newRecord(&r.record, true)
+		p.trigger()
+	}
+	return nil
+}
+
+func (p *gossipSD) Renew(ctx context.Context, service *sd.Service) error {
+	if service == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := serviceKey(service.Name, service.ID)
+	if r := p.records[key]; r != nil && r.Origin == p.id && !r.Deleted {
+		p.records[key] = p.newRecord(&r.record, false)
+	}
+	return nil
+}
+
+func (p *gossipSD) Get(ctx context.Context, name string) (services []*sd.Service, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for _, r := range p.records {
+		if r.Name == name && !r.Deleted && r.expire.After(now) {
+			services = append(services, r.service())
+		}
+	}
+	return
+}
+
+func (p *gossipSD) newRecord(r *record, deleted bool) *gossipRecord {
+	now := time.Now()
+	return &gossipRecord{
+		record:  *r,
+		Origin:  p.id,
+		Version: now.UnixNano(),
+		Deleted: deleted,
+		expire:  now.Add(p.ttl),
+	}
+}
+
+func (p *gossipSD) trigger() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (p *gossipSD) run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.notify:
+		case <-ctx.Done():
+			return
+		}
+
+		p.listen(ctx)
+		p.purge()
+
+		for _, peer := range p.selectPeers() {
+			if err := p.exchange(ctx, peer); err != nil {
+				p.log.Debugf("sd: gossip with %s: %v", peer, err)
+			}
+		}
+	}
+}
+
+// listen starts the server for the peers if it is not running.
+// The address may still be held by the replaced instance of the same service discovery
+// on the config update, so a failed listen is retried on each round.
+func (p *gossipSD) listen(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.server != nil || ctx.Err() != nil {
+		return
+	}
+
+	ln, err := net.Listen("tcp", p.addr)
+	if err != nil {
+		if !p.listenFailed {
+			p.log.Warnf("sd: gossip: %v, retrying", err)
+		}
+		p.listenFailed = true
+		return
+	}
+	if p.listenFailed {
+		p.log.Infof("sd: gossip: listening on %s", ln.Addr())
+	}
+	p.listenFailed = false
+
+	p.server = &http.Server{
+		Handler:           p,
+		ReadHeaderTimeout: p.interval,
+	}
+	go p.server.Serve(ln)
+}
+
+// purge removes the expired records and the learned peers which have been unreachable for a TTL.
+func (p *gossipSD) purge() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for k, r := range p.records {
+		if !r.expire.After(now) {
+			delete(p.records, k)
+		}
+	}
+	for addr, peer := range p.peers {
+		if !peer.seed && now.Sub(peer.lastSeen) > p.ttl {
+			delete(p.peers, addr)
+		}
+	}
+}
+
+func (p *gossipSD) selectPeers() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	peers := make([]string, 0, len(p.peers))
+	for addr := range p.peers {
+		peers = append(peers, addr)
+	}
+	rand.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+	if len(peers) > defaultGossipFanout {
+		peers = peers[:defaultGossipFanout]
+	}
+	return peers
+}
+
+// exchange sends the local state to the peer and merges the state of the peer in the response.
+func (p *gossipSD) exchange(ctx context.Context, peer string) error {
+	b, err := json.Marshal(p.message())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+peer, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.secret != "" {
+		req.Header.Set("Authorization", "Bearer "+p.secret)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", resp.Status)
+	}
+
+	var msg gossipMessage
+	if err := json.NewDecoder(io.LimitReader(resp.Body, gossipMaxMessageSize)).Decode(&msg); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if msg.ID == p.id {
+		// the peer is this node.
+		delete(p.peers, peer)
+		p.selves[peer] = true
+		return nil
+	}
+	if v := p.peers[peer]; v != nil {
+		v.lastSeen = time.Now()
+	}
+	p.merge(&msg, "")
+
+	return nil
+}
+
+func (p *gossipSD) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if p.secret != "" &&
+		subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+p.secret)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var msg gossipMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, gossipMaxMessageSize)).Decode(&msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if msg.ID != p.id {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		p.mu.Lock()
+		p.merge(&msg, host)
+		p.mu.Unlock()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p.message())
+}
+
+// merge applies the state of a peer, srcHost is used to complete the advertised address of the sender.
+func (p *gossipSD) merge(msg *gossipMessage, srcHost string) {
+	now := time.Now()
+
+	if msg.Addr != "" && srcHost != "" {
+		if host, port, err := net.SplitHostPort(msg.Addr); err == nil {
+			if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+				msg.Addr = net.JoinHostPort(srcHost, port)
+			}
+		}
+	}
+	if msg.Addr != "" && !p.selves[msg.Addr] {
+		if peer := p.peers[msg.Addr]; peer != nil {
+			peer.lastSeen = now
+		} else if srcHost != "" {
+			p.peers[msg.Addr] = &gossipPeer{lastSeen: now}
+		}
+	}
+	for _, addr := range msg.Peers {
+		if host, _, err := net.SplitHostPort(addr); err != nil || host == "" || addr == p.addr || p.selves[addr] {
+			continue
+		}
+		if _, ok := p.peers[addr]; !ok {
+			p.peers[addr] = &gossipPeer{lastSeen: now}
+		}
+	}
+
+	for _, r := range msg.Records {
+		if r == nil || r.Origin == p.id || r.TTL <= 0 {
+			continue
+		}
+		key := serviceKey(r.Name, r.ID)
+		if v := p.records[key]; v != nil && v.Version >= r.Version {
+			continue
+		}
+		r.expire = now.Add(time.Duration(r.TTL) * time.Millisecond)
+		p.records[key] = r
+	}
+}
+
+func (p *gossipSD) message() *gossipMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	msg := &gossipMessage{
+		ID:   p.id,
+		Addr: p.addr,
+	}
+	for addr := range p.peers {
+		msg.Peers = append(msg.Peers, addr)
+	}
+
+	now := time.Now()
+	for _, r := range p.records {
+		ttl := r.expire.Sub(now).Milliseconds()
+		if ttl <= 0 {
+			continue
+		}
+		v := *r
+		v.TTL = ttl
+		msg.Records = append(msg.Records, &v)
+	}
+	return msg
+}
+
+func (p *gossipSD) Close() error {
+	p.cancel()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.server != nil {
+		return p.server.Close()
+	}
+	return nil
+}
+
+func isLoopback(addr string) bool {
+	host, _, _ := net.SplitHostPort(addr)
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,175 @@
+package sd
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/sd"
+	"github.com/go-redis/redis/v8"
+)
+
+// redisSD stores the services of each name in a redis hash <key>:<name>, field is the service ID.
+// Each service has a TTL key <key>:ttl:<name>:<id> which is refreshed by the renewals,
+// the service is removed from the hash once its TTL key expires.
+type redisSD struct {
+	client   *redis.Client
+	key      string
+	ttl      time.Duration
+	services map[string]*record
+	mu       sync.Mutex
+	log      logger.Logger
+}
+
+// RedisSD creates a service discovery backed by redis, which can be shared by multiple nodes.
+func RedisSD(addr string, opts ...Option) sd.SD {
+	options := newOptions(opts)
+
+	key := options.key
+	if key == "" {
+		key = defaultRedisKey
+	}
+
+	return &redisSD{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Username: options.username,
+			Password: options.password,
+			DB:       options.db,
+		}),
+		key:      key,
+		ttl:      options.ttl,
+		services: make(map[string]*record),
+		log:      options.logger,
+	}
+}
+
+func (p *redisSD) Register(ctx context.Context, service *sd.Service, opts ...sd.Option) error {
+	if service == nil {
+		return nil
+	}
+
+	r := newRecord(service)
+
+	p.mu.Lock()
+	p.services[serviceKey(r.Name, r.ID)] = r
+	p.mu.Unlock()
+
+	return p.set(ctx, r)
+}
+
+func (p *redisSD) set(ctx context.Context, r *record) error {
+	v, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	hkey := p.hashKey(r.Name)
+	_, err = p.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, hkey, r.ID, v)
+		pipe.Set(ctx, p.ttlKey(r.Name, r.ID), 1, p.ttl)
+		// the hash lives as long as its last service.
+		pipe.Expire(ctx, hkey, p.ttl)
+		return nil
+	})
+	return err
+}
+
+func (p *redisSD) Deregister(ctx context.Context, service *sd.Service) error {
+	if service == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	delete(p.services, serviceKey(service.Name, service.ID))
+	p.mu.Unlock()
+
+	_, err := p.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, p.hashKey(service.Name), service.ID)
+		pipe.Del(ctx, p.ttlKey(service.Name, service.ID))
+		return nil
+	})
+	return err
+}
+
+func (p *redisSD) Renew(ctx context.Context, service *sd.Service) error {
+	if service == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	r := p.services[serviceKey(service.Name, service.ID)]
+	p.mu.Unlock()
+
+	if r != nil {
+		return p.set(ctx, r)
+	}
+
+	// not registered by this node, only extend the lifetime of the existing service.
+	_, err := p.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Expire(ctx, p.ttlKey(service.Name, service.ID), p.ttl)
+		pipe.Expire(ctx, p.hashKey(service.Name), p.ttl)
+		return nil
+	})
+	return err
+}
+
+func (p *redisSD) Get(ctx context.Context, name string) (services []*sd.Service, err error) {
+	hkey := p.hashKey(name)
+	m, err := p.client.HGetAll(ctx, hkey).Result()
+	if err != nil || len(m) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+
+	cmds := make([]*redis.IntCmd, len(ids))
+	if _, err = p.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.Exists(ctx, p.ttlKey(name, id))
+		}
+		return nil
+	}); err != nil {
+		return
+	}
+
+	var expired []string
+	for i, id := range ids {
+		if cmds[i].Val() == 0 {
+			expired = append(expired, id)
+			continue
+		}
+
+		r := &record{}
+		if err := json.Unmarshal([]byte(m[id]), r); err != nil {
+			p.log.Warnf("sd: invalid service %s: %v", id, err)
+			continue
+		}
+		services = append(services, r.service())
+	}
+
+	if len(expired) > 0 {
+		if err := p.client.HDel(ctx, hkey, expired...).Err(); err != nil {
+			p.log.Warnf("sd: remove expired services of %s: %v", name, err)
+		}
+	}
+
+	return
+}
+
+func (p *redisSD) hashKey(name string) string {
+	return p.key + ":" + name
+}
+
+func (p *redisSD) ttlKey(name, id string) string {
+	return p.key + ":ttl:" + name + ":" + id
+}
+
+func (p *redisSD) Close() error {
+	return p.client.Close()
+}
@@ -0,0 +1,144 @@
+package sd
+
+import (
+	"time"
+
+	"github.com/go-gost/core/logger"
+	"github.com/go-gost/core/sd"
+)
+
+const (
+	// DefaultTTL is the lifetime of a service without renewal,
+	// it tolerates two missed renewals of the tunnel and router handlers at their default 15s interval.
+	DefaultTTL = 45 * time.Second
+
+	defaultRedisKey       = "gost:sd"
+	defaultGossipInterval = 3 * time.Second
+	defaultGossipFanout   = 3
+)
+
+type options struct {
+	ttl      time.Duration
+	db       int
+	username string
+	password string
+	key      string
+	peers    []string
+	interval time.Duration
+	secret   string
+	logger   logger.Logger
+}
+
+type Option func(opts *options)
+
+// TTLOption sets the lifetime of the services, it should be larger than the renewal interval of the handlers.
+func TTLOption(ttl time.Duration) Option {
+	return func(opts *options) {
+		opts.ttl = ttl
+	}
+}
+
+func DBOption(db int) Option {
+	return func(opts *options) {
+		opts.db = db
+	}
+}
+
+func UsernameOption(username string) Option {
+	return func(opts *options) {
+		opts.username = username
+	}
+}
+
+func PasswordOption(password string) Option {
+	return func(opts *options) {
+		opts.password = password
+	}
+}
+
+func KeyOption(key string) Option {
+	return func(opts *options) {
+		opts.key = key
+	}
+}
+
+// PeersOption sets the seed peers of the gossip mesh.
+func PeersOption(peers []string) Option {
+	return func(opts *options) {
+		opts.peers = peers
+	}
+}
+
+// IntervalOption sets the gossip interval.
+func IntervalOption(interval time.Duration) Option {
+	return func(opts *options) {
+		opts.interval = interval
+	}
+}
+
+// SecretOption sets the shared secret of the gossip mesh.
+func SecretOption(secret string) Option {
+	return func(opts *options) {
+		opts.secret = secret
+	}
+}
+
+func LoggerOption(logger logger.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+func newOptions(opts []Option) options {
+	var options options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	if options.ttl <= 0 {
+		options.ttl = DefaultTTL
+	}
+	if options.logger == nil {
+		options.logger = logger.Default()
+	}
+	return options
+}
+
+// record is the stored form of a service.
+type record struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Node    string `json:"node"`
+	Network string `json:"network"`
+	Address string `json:"address"`
+	// expiration time of the file records in unix milliseconds.
+	Expire int64 `json:"expire,omitempty"`
+}
+
+func newRecord(service *sd.Service) *record {
+	return &record{
+		ID:      service.ID,
+		Name:    service.Name,
+		Node:    service.Node,
+		Network: service.Network,
+		Address: service.Address,
+	}
+}
+
+func (r *record) service() *sd.Service {
+	return &sd.Service{
+		ID:      r.ID,
+		Name:    r.Name,
+		Node:    r.Node,
+		Network: r.Network,
+		Address: r.Address,
+	}
+}
+
+// serviceKey identifies a service registered by this node,
+// the backends keep these services so that a renewal can restore a service
+// which has been expired or evicted from the backend.
+func serviceKey(name, id string) string {
+	return name + "/" + id
+}
@@ -0,0 +1,151 @@
+package sd
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/sd"
+	xlogger "github.com/go-gost/x/logger"
+)
+
+func TestFileSD(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sd.json")
+
+	sd1 := FileSD(path, TTLOption(500*time.Millisecond), LoggerOption(xlogger.Nop()))
+	sd2 := FileSD(path, TTLOption(500*time.Millisecond), LoggerOption(xlogger.Nop()))
+
+	s1 := &sd.Service{ID: "c1", Name: "t1", Node: "n1", Network: "tcp", Address: "10.0.0.1:8421"}
+	s2 := &sd.Service{ID: "c2", Name: "t1", Node: "n2", Network: "tcp", Address: "10.0.0.2:8421"}
+	if err := sd1.Register(ctx, s1); err != nil {
+		t.Fatal(err)
+	}
+	if err := sd2.Register(ctx, s2); err != nil {
+		t.Fatal(err)
+	}
+
+	ss, err := sd1.Get(ctx, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 2 {
+		t.Fatalf("got %d services, want 2", len(ss))
+	}
+
+	// s1 is renewed and s2 expires.
+	time.Sleep(300 * time.Millisecond)
+	sd1.Renew(ctx, &sd.Service{ID: "c1", Name: "t1", Node: "n1"})
+	time.Sleep(300 * time.Millisecond)
+
+	ss, _ = sd2.Get(ctx, "t1")
+	if len(ss) != 1 || *ss[0] != *s1 {
+		t.Fatalf("got %v, want [%v]", ss, s1)
+	}
+
+	sd1.Deregister(ctx, s1)
+	if ss, _ = sd2.Get(ctx, "t1"); len(ss) != 0 {
+		t.Fatalf("got %v, want none", ss)
+	}
+}
+
+func freeAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func TestGossipSD(t *testing.T) {
+	ctx := context.Background()
+	addr1, addr2, addr3 := freeAddr(t), freeAddr(t), freeAddr(t)
+
+	opts := []Option{
+		TTLOption(2 * time.Second),
+		IntervalOption(100 * time.Millisecond),
+		SecretOption("secret"),
+		LoggerOption(xlogger.Nop()),
+	}
+	// node3 only knows node2, and learns node1 from it.
+	sd1 := GossipSD(addr1, append(opts, PeersOption([]string{addr2}))...)
+	sd2 := GossipSD(addr2, append(opts, PeersOption([]string{addr1}))...)
+	sd3 := GossipSD(addr3, append(opts, PeersOption([]string{addr2}))...)
+	for _, v := range []sd.SD{sd1, sd2, sd3} {
+		defer v.(io.Closer).Close()
+	}
+
+	s1 := &sd.Service{ID: "c1", Name: "t1", Node: "n1", Network: "tcp", Address: "10.0.0.1:8421"}
+	sd1.Register(ctx, s1)
+
+	wait := func(v sd.SD, n int) {
+		t.Helper()
+		for i := 0; ; i++ {
+			if ss, _ := v.Get(ctx, "t1"); len(ss) == n {
+				return
+			}
+			if i > 50 {
+				t.Fatalf("services are not synced")
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	wait(sd3, 1)
+	if ss, _ := sd3.Get(ctx, "t1"); *ss[0] != *s1 {
+		t.Fatalf("got %v, want %v", ss[0], s1)
+	}
+
+	sd1.Deregister(ctx, s1)
+	wait(sd3, 0)
+
+	// a node with a wrong secret is rejected.
+	sd4 := GossipSD(freeAddr(t), TTLOption(2*time.Second), IntervalOption(100*time.Millisecond),
+		PeersOption([]string{addr2}), LoggerOption(xlogger.Nop()))
+	defer sd4.(io.Closer).Close()
+
+	sd4.Register(ctx, s1)
+	time.Sleep(500 * time.Millisecond)
+	if ss, _ := sd2.Get(ctx, "t1"); len(ss) != 0 {
+		t.Fatalf("got %v, want none", ss)
+	}
+}
+
+func TestGossipSDListenRetry(t *testing.T) {
+	ctx := context.Background()
+	addr1, addr2 := freeAddr(t), freeAddr(t)
+
+	opts := []Option{
+		TTLOption(2 * time.Second),
+		IntervalOption(100 * time.Millisecond),
+		SecretOption("secret"),
+		LoggerOption(xlogger.Nop()),
+	}
+
+	// the address of node1 is still held by the replaced instance.
+	old := GossipSD(addr1, opts...)
+	sd1 := GossipSD(addr1, opts...)
+	defer sd1.(io.Closer).Close()
+	old.(io.Closer).Close()
+
+	sd2 := GossipSD(addr2, append(opts, PeersOption([]string{addr1}))...)
+	defer sd2.(io.Closer).Close()
+
+	s1 := &sd.Service{ID: "c1", Name: "t1", Node: "n1", Network: "tcp", Address: "10.0.0.1:8421"}
+	sd1.Register(ctx, s1)
+
+	// node1 does not know node2, the service is only synced once node1 listens.
+	for i := 0; ; i++ {
+		if ss, _ := sd2.Get(ctx, "t1"); len(ss) == 1 {
+			break
+		}
+		if i > 50 {
+			t.Fatalf("services are not synced")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,167 @@
+package selector
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gost/core/selector"
+	xctx "github.com/go-gost/x/ctx"
+)
+
+const (
+	// defaultVirtualNodes is the number of points on the ring per unit of weight.
+	defaultVirtualNodes = 160
+	// maxHashWeight caps the weight of a node, which bounds the size of the ring.
+	maxHashWeight = 100
+	// maxCachedRings is the number of the rings kept for the different node sets,
+	// the node set varies with the filtered out nodes.
+	maxCachedRings = 8
+)
+
+type hashRing struct {
+	points []uint64
+	owners []int
+}
+
+func newHashRing(keys []string, weights []int, replicas int) *hashRing {
+	type point struct {
+		hash  uint64
+		owner int
+	}
+
+	var points []point
+	for i, key := range keys {
+		n := replicas * weights[i]
+		for j := 0; j < n; j++ {
+			points = append(points, point{
+				hash:  hashKey(key + "#" + strconv.Itoa(j)),
+				owner: i,
+			})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].hash < points[j].hash
+	})
+
+	r := &hashRing{
+		points: make([]uint64, len(points)),
+		owners: make([]int, len(points)),
+	}
+	for i := range points {
+		r.points[i] = points[i].hash
+		r.owners[i] = points[i].owner
+	}
+	return r
+}
+
+// Get returns the index of the owner of the first point clockwise from the hash of key.
+func (r *hashRing) Get(key string) int {
+	if len(r.points) == 0 {
+		return -1
+	}
+
+	h := hashKey(key)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[i]
+}
+
+// hashKey is FNV-1a followed by the murmur3 finalizer for a better avalanche.
+func hashKey(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	v := h.Sum64()
+
+	v ^= v >> 33
+	v *= 0xff51afd7ed558ccd
+	v ^= v >> 33
+	v *= 0xc4ceb9fe1a85ec53
+	v ^= v >> 33
+	return v
+}
+
+type consistentHashStrategy[T any] struct {
+	replicas int
+	// node set signature to ring.
+	rings map[string]*hashRing
+	r     *rand.Rand
+	mu    sync.Mutex
+}
+
+// ConsistentHashStrategy is a strategy for node selector.
+// The node is selected by consistent hashing on a ring with virtual nodes,
+// the number of virtual nodes of a node is proportional to its weight, which is capped at 100.
+// When the nodes change, only the keys mapped to the affected nodes are remapped.
+func ConsistentHashStrategy[T any]() selector.Strategy[T] {
+	return &consistentHashStrategy[T]{
+		replicas: defaultVirtualNodes,
+		rings:    make(map[string]*hashRing),
+		r:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (s *consistentHashStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
+	if len(vs) == 0 {
+		return
+	}
+
+	h := xctx.HashFromContext(ctx)
+	if h == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		return vs[s.r.Intn(len(vs))]
+	}
+
+	keys := make([]string, len(vs))
+	weights := make([]int, len(vs))
+	var sb strings.Builder
+	for i := range vs {
+		keys[i] = statsKey(vs[i])
+		if keys[i] == "" {
+			keys[i] = strconv.Itoa(i)
+		}
+		weights[i] = min(weightOf(vs[i]), maxHashWeight)
+
+		sb.WriteString(keys[i])
+		sb.WriteByte('/')
+		sb.WriteString(strconv.Itoa(weights[i]))
+		sb.WriteByte(',')
+	}
+
+	return vs[s.ring(sb.String(), keys, weights).Get(h.Source)]
+}
+
+// ring returns the cached ring of the node set,
+// the ring is built only when the nodes or their weights change.
+func (s *consistentHashStrategy[T]) ring(sig string, keys []string, weights []int) *hashRing {
+	s.mu.Lock()
+	r := s.rings[sig]
+	s.mu.Unlock()
+	if r != nil {
+		return r
+	}
+
+	// the ring is built without holding the lock.
+	r = newHashRing(keys, weights, s.replicas)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.rings) >= maxCachedRings {
+		// the stale node sets are dropped.
+		clear(s.rings)
+	}
+	s.rings[sig] = r
+	return r
+}
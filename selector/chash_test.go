@@ -0,0 +1,70 @@
+package selector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-gost/core/chain"
+	xctx "github.com/go-gost/x/ctx"
+)
+
+func TestHashRingRemap(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e"}
+	weights := []int{1, 1, 1, 1, 1}
+	r1 := newHashRing(keys, weights, defaultVirtualNodes)
+	// remove node "c"
+	r2 := newHashRing([]string{"a", "b", "d", "e"}, []int{1, 1, 1, 1}, defaultVirtualNodes)
+
+	const n = 10000
+	moved := 0
+	for i := 0; i < n; i++ {
+		src := fmt.Sprintf("192.168.%d.%d", i/256, i%256)
+		k1 := keys[r1.Get(src)]
+		k2 := []string{"a", "b", "d", "e"}[r2.Get(src)]
+		if k1 != "c" && k1 != k2 {
+			moved++
+		}
+	}
+	if moved > 0 {
+		t.Errorf("%d keys not owned by the removed node were remapped", moved)
+	}
+}
+
+func TestHashRingWeight(t *testing.T) {
+	r := newHashRing([]string{"a", "b"}, []int{3, 1}, defaultVirtualNodes)
+
+	const n = 20000
+	counts := make([]int, 2)
+	for i := 0; i < n; i++ {
+		counts[r.Get(fmt.Sprintf("key-%d", i))]++
+	}
+	if ratio := float64(counts[0]) / float64(counts[1]); ratio < 2.2 || ratio > 4 {
+		t.Errorf("weight ratio: got %.2f, want about 3", ratio)
+	}
+}
+
+func TestConsistentHashStrategy(t *testing.T) {
+	nodes := newTestNodes(t, 1, 1, 10000)
+	s := ConsistentHashStrategy[*chain.Node]().(*consistentHashStrategy[*chain.Node])
+
+	ctx := xctx.ContextWithHash(context.Background(), &xctx.Hash{Source: "192.168.0.1"})
+	n := s.Apply(ctx, nodes...)
+	// the node set changes while a node is filtered out.
+	for i := 0; i < 3; i++ {
+		s.Apply(ctx, nodes[:2]...)
+		if v := s.Apply(ctx, nodes...); v != n {
+			t.Fatalf("got %s, want %s", v.Name, n.Name)
+		}
+	}
+	if len(s.rings) != 2 {
+		t.Errorf("%d rings cached, want 2", len(s.rings))
+	}
+
+	// the weight is capped.
+	for _, r := range s.rings {
+		if len(r.points) > (2+maxHashWeight)*defaultVirtualNodes {
+			t.Errorf("%d points on the ring", len(r.points))
+		}
+	}
+}
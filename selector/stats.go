@@ -0,0 +1,178 @@
+package selector
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-gost/core/chain"
+)
+
+const (
+	// ewmaAlpha is the smoothing factor of the latency moving average.
+	ewmaAlpha = 0.3
+	// ewmaStaleTime is the time after which a latency measurement is considered stale,
+	// a stale item is treated as unmeasured so that it will be probed again.
+	ewmaStaleTime = 60 * time.Second
+	// statsIdleTime is the time after which the stats of an idle node or chain are removed,
+	// a removed node or chain starts over as unmeasured.
+	statsIdleTime = 10 * time.Minute
+)
+
+var (
+	statsMap       sync.Map
+	statsPruneOnce sync.Once
+)
+
+// Stats holds the runtime load and latency measurements of a node or chain,
+// which are consumed by the load-aware strategies.
+type Stats struct {
+	conns      atomic.Int64
+	accessTime atomic.Int64
+	latency    time.Duration
+	updateTime time.Time
+	lastError  string
+	errorTime  time.Time
+	mu         sync.RWMutex
+}
+
+// StatsOf returns the Stats of the node or chain v,
+// nil is returned if v can not be identified.
+func StatsOf(v any) *Stats {
+	key := statsKey(v)
+	if key == "" {
+		return nil
+	}
+
+	statsPruneOnce.Do(func() {
+		go pruneStats(statsIdleTime)
+	})
+
+	v, ok := statsMap.Load(key)
+	if !ok {
+		v, _ = statsMap.LoadOrStore(key, &Stats{})
+	}
+	s := v.(*Stats)
+	s.accessTime.Store(time.Now().UnixNano())
+	return s
+}
+
+// pruneStats periodically removes the stats of the nodes and chains
+// which have no active connections and have not been used for the idle time,
+// such as the nodes removed by the config reloading.
+func pruneStats(idle time.Duration) {
+	ticker := time.NewTicker(idle / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		prune(time.Now().Add(-idle))
+	}
+}
+
+func prune(before time.Time) {
+	statsMap.Range(func(key, value any) bool {
+		s := value.(*Stats)
+		if s.Conns() == 0 && s.accessTime.Load() < before.UnixNano() {
+			statsMap.Delete(key)
+		}
+		return true
+	})
+}
+
+func statsKey(v any) string {
+	switch t := v.(type) {
+	case *chain.Node:
+		if t == nil {
+			return ""
+		}
+		return "node:" + t.Name + "@" + t.Addr
+	case interface{ Name() string }:
+		if name := t.Name(); name != "" {
+			return "chain:" + name
+		}
+	}
+	return ""
+}
+
+// Conns returns the number of active connections.
+func (s *Stats) Conns() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.conns.Load()
+}
+
+// Acquire increases the number of active connections,
+// the returned function must be called once the connection is closed.
+func (s *Stats) Acquire() (release func()) {
+	if s == nil {
+		return func() {}
+	}
+
+	s.conns.Add(1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.conns.Add(-1)
+		})
+	}
+}
+
+// Observe adds a connect duration measurement to the exponentially weighted moving average.
+func (s *Stats) Observe(d time.Duration) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.latency <= 0 || time.Since(s.updateTime) > ewmaStaleTime {
+		s.latency = d
+	} else {
+		s.latency = time.Duration(ewmaAlpha*float64(d) + (1-ewmaAlpha)*float64(s.latency))
+	}
+	s.updateTime = time.Now()
+}
+
+// Latency returns the moving average of the connect duration,
+// zero is returned if there is no valid measurement.
+func (s *Stats) Latency() time.Duration {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if time.Since(s.updateTime) > ewmaStaleTime {
+		return 0
+	}
+	return s.latency
+}
+
+// SetError records the last connect error.
+func (s *Stats) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastError = err.Error()
+	s.errorTime = time.Now()
+}
+
+// LastError returns the last connect error and the time it occurred.
+func (s *Stats) LastError() (string, time.Time) {
+	if s == nil {
+		return "", time.Time{}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lastError, s.errorTime
+}
@@ -57,14 +57,7 @@ func (s *randomStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
 
 	s.rw.Reset()
 	for i := range vs {
-		weight := 0
-		if md, _ := any(vs[i]).(metadata.Metadatable); md != nil {
-			weight = mdutil.GetInt(md.Metadata(), labelWeight)
-		}
-		if weight <= 0 {
-			weight = 1
-		}
-		s.rw.Add(vs[i], weight)
+		s.rw.Add(vs[i], weightOf(vs[i]))
 	}
 
 	return s.rw.Next()
@@ -113,3 +106,121 @@ func (s *hashStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
 
 	return vs[s.r.Intn(len(vs))]
 }
+
+type leastConnStrategy[T any] struct {
+	counter uint64
+}
+
+// LeastConnStrategy is a strategy for node selector.
+// The node with the least active connections relative to its weight will be selected,
+// the nodes with the same load are selected by round-robin.
+func LeastConnStrategy[T any]() selector.Strategy[T] {
+	return &leastConnStrategy[T]{}
+}
+
+func (s *leastConnStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
+	if len(vs) == 0 {
+		return
+	}
+
+	offset := int((atomic.AddUint64(&s.counter, 1) - 1) % uint64(len(vs)))
+
+	var conns, weight int64
+	for i := range vs {
+		n := vs[(offset+i)%len(vs)]
+		c, w := StatsOf(n).Conns()+1, int64(weightOf(n))
+		// c/w < conns/weight
+		if i == 0 || c*weight < conns*w {
+			v, conns, weight = n, c, w
+		}
+	}
+	return
+}
+
+type ewmaStrategy[T any] struct {
+	counter uint64
+}
+
+// EWMAStrategy is a strategy for node selector.
+// The node with the lowest exponentially weighted moving average of connect latency will be selected.
+// The nodes without recent measurements are preferred so that they are probed.
+func EWMAStrategy[T any]() selector.Strategy[T] {
+	return &ewmaStrategy[T]{}
+}
+
+func (s *ewmaStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
+	if len(vs) == 0 {
+		return
+	}
+
+	offset := int((atomic.AddUint64(&s.counter, 1) - 1) % uint64(len(vs)))
+
+	var latency time.Duration
+	for i := range vs {
+		n := vs[(offset+i)%len(vs)]
+		d := StatsOf(n).Latency()
+		if d <= 0 {
+			return n
+		}
+		if i == 0 || d < latency {
+			v, latency = n, d
+		}
+	}
+	return
+}
+
+type p2cStrategy[T any] struct {
+	r  *rand.Rand
+	mu sync.Mutex
+}
+
+// P2CStrategy is a strategy for node selector.
+// Two nodes are picked randomly, and the one with the lower load will be selected.
+// The load of a node is its active connections weighted by its connect latency.
+func P2CStrategy[T any]() selector.Strategy[T] {
+	return &p2cStrategy[T]{
+		r: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (s *p2cStrategy[T]) Apply(ctx context.Context, vs ...T) (v T) {
+	if len(vs) == 0 {
+		return
+	}
+	if len(vs) == 1 {
+		return vs[0]
+	}
+
+	s.mu.Lock()
+	i := s.r.Intn(len(vs))
+	j := s.r.Intn(len(vs) - 1)
+	s.mu.Unlock()
+	if j >= i {
+		j++
+	}
+
+	a, b := StatsOf(vs[i]), StatsOf(vs[j])
+	la, lb := a.Latency(), b.Latency()
+	if la > 0 && lb > 0 {
+		if float64(a.Conns()+1)*float64(la) <= float64(b.Conns()+1)*float64(lb) {
+			return vs[i]
+		}
+		return vs[j]
+	}
+
+	if a.Conns() <= b.Conns() {
+		return vs[i]
+	}
+	return vs[j]
+}
+
+func weightOf(v any) int {
+	weight := 0
+	if md, _ := v.(metadata.Metadatable); md != nil {
+		weight = mdutil.GetInt(md.Metadata(), labelWeight)
+	}
+	if weight <= 0 {
+		weight = 1
+	}
+	return weight
+}
@@ -0,0 +1,133 @@
+package selector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-gost/core/chain"
+	xmd "github.com/go-gost/x/metadata"
+)
+
+func newTestNodes(t *testing.T, weights ...int) []*chain.Node {
+	nodes := make([]*chain.Node, len(weights))
+	for i, w := range weights {
+		nodes[i] = chain.NewNode(fmt.Sprintf("%s-%d", t.Name(), i), fmt.Sprintf("127.0.0.1:%d", 10000+i),
+			chain.MetadataNodeOption(xmd.NewMetadata(map[string]any{labelWeight: w})))
+	}
+	return nodes
+}
+
+func TestLeastConnStrategy(t *testing.T) {
+	nodes := newTestNodes(t, 1, 1, 2)
+	s := LeastConnStrategy[*chain.Node]()
+
+	releases := make(map[*chain.Node][]func())
+	defer func() {
+		for _, v := range releases {
+			for _, release := range v {
+				release()
+			}
+		}
+	}()
+
+	for i := 0; i < 8; i++ {
+		n := s.Apply(context.Background(), nodes...)
+		releases[n] = append(releases[n], StatsOf(n).Acquire())
+	}
+	// the connections are spread by weight.
+	if len(releases[nodes[0]]) != 2 || len(releases[nodes[1]]) != 2 || len(releases[nodes[2]]) != 4 {
+		t.Errorf("unexpected distribution %d, %d, %d",
+			len(releases[nodes[0]]), len(releases[nodes[1]]), len(releases[nodes[2]]))
+	}
+
+	// the node with the closed connections is preferred.
+	for _, release := range releases[nodes[1]] {
+		release()
+	}
+	releases[nodes[1]] = nil
+	for i := 0; i < 3; i++ {
+		if n := s.Apply(context.Background(), nodes...); n != nodes[1] {
+			t.Fatalf("got %s, want the idle node", n.Name)
+		}
+	}
+}
+
+func TestEWMAStrategy(t *testing.T) {
+	nodes := newTestNodes(t, 1, 1, 1)
+	s := EWMAStrategy[*chain.Node]()
+
+	StatsOf(nodes[0]).Observe(30 * time.Millisecond)
+	StatsOf(nodes[1]).Observe(10 * time.Millisecond)
+
+	// the unmeasured node is probed first.
+	if n := s.Apply(context.Background(), nodes...); n != nodes[2] {
+		t.Fatalf("got %s, want the unmeasured node", n.Name)
+	}
+
+	StatsOf(nodes[2]).Observe(20 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if n := s.Apply(context.Background(), nodes...); n != nodes[1] {
+			t.Fatalf("got %s, want the fastest node", n.Name)
+		}
+	}
+
+	// the moving average follows the new measurements.
+	for i := 0; i < 10; i++ {
+		StatsOf(nodes[1]).Observe(50 * time.Millisecond)
+	}
+	if n := s.Apply(context.Background(), nodes...); n != nodes[2] {
+		t.Fatalf("got %s, want the node with the lowest average", n.Name)
+	}
+}
+
+func TestP2CStrategy(t *testing.T) {
+	nodes := newTestNodes(t, 1, 1)
+	s := P2CStrategy[*chain.Node]()
+
+	if n := s.Apply(context.Background(), nodes[0]); n != nodes[0] {
+		t.Fatal("the single node is not selected")
+	}
+
+	// the node with less connections is selected without the latency.
+	release := StatsOf(nodes[0]).Acquire()
+	for i := 0; i < 10; i++ {
+		if n := s.Apply(context.Background(), nodes...); n != nodes[1] {
+			t.Fatalf("got %s, want the idle node", n.Name)
+		}
+	}
+	release()
+
+	// the load is weighted by the latency.
+	StatsOf(nodes[0]).Observe(10 * time.Millisecond)
+	StatsOf(nodes[1]).Observe(100 * time.Millisecond)
+	release = StatsOf(nodes[0]).Acquire()
+	defer release()
+	for i := 0; i < 10; i++ {
+		if n := s.Apply(context.Background(), nodes...); n != nodes[0] {
+			t.Fatalf("got %s, want the fast node", n.Name)
+		}
+	}
+}
+
+func TestPruneStats(t *testing.T) {
+	nodes := newTestNodes(t, 1, 1)
+
+	release := StatsOf(nodes[0]).Acquire()
+	StatsOf(nodes[1]).Observe(10 * time.Millisecond)
+
+	prune(time.Now().Add(time.Second))
+
+	if _, ok := statsMap.Load(statsKey(nodes[0])); !ok {
+		t.Error("the stats with active connections are removed")
+	}
+	if _, ok := statsMap.Load(statsKey(nodes[1])); ok {
+		t.Error("the idle stats are not removed")
+	}
+
+	release()
+	if c := StatsOf(nodes[0]).Conns(); c != 0 {
+		t.Errorf("got %d connections, want 0", c)
+	}
+}
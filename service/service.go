@@ -21,6 +21,7 @@ import (
 	"github.com/go-gost/core/observer/stats"
 	"github.com/go-gost/core/recorder"
 	"github.com/go-gost/core/service"
+	"github.com/go-gost/x/conntrack"
 	xctx "github.com/go-gost/x/ctx"
 	xmetrics "github.com/go-gost/x/metrics"
 	xstats "github.com/go-gost/x/observer/stats"
@@ -252,11 +253,24 @@ func (s *defaultService) Serve() error {
 			continue
 		}
 
+		ctx, cancel := context.WithCancel(ctx)
+		tc := conntrack.NewConn(s.name, sid, srcAddr.String(), &trackedConn{Conn: conn, cancel: cancel})
+		ctx = conntrack.ContextWithConn(ctx, tc)
+
 		wg.Add(1)
 
 		go func() {
 			defer wg.Done()
 
+			conntrack.Default().Register(tc)
+			defer func() {
+				// a detached connection is unregistered when it is closed.
+				if !tc.Detached() {
+					conntrack.Default().Unregister(sid)
+				}
+			}()
+			defer cancel()
+
 			if v := xmetrics.GetCounter(xmetrics.MetricServiceRequestsCounter,
 				metrics.Labels{"service": s.name, "client": clientIP}); v != nil {
 				v.Inc()
@@ -415,3 +429,15 @@ type ServiceEvent struct {
 func (ServiceEvent) Type() observer.EventType {
 	return observer.EventStatus
 }
+
+// trackedConn is the closer of the tracked connection,
+// it also cancels the context of the handler.
+type trackedConn struct {
+	net.Conn
+	cancel context.CancelFunc
+}
+
+func (c *trackedConn) Close() error {
+	c.cancel()
+	return c.Conn.Close()
+}